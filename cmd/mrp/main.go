@@ -5,9 +5,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
 
 	"github.com/vsinha/mrp/pkg/interfaces/cli/commands"
+	"github.com/vsinha/mrp/pkg/interfaces/status"
 )
 
 func main() {
@@ -17,13 +19,30 @@ func main() {
 	}
 
 	command := os.Args[1]
-	ctx := context.Background()
+
+	// Ctrl-C cancels a running command instead of killing it mid-output
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	switch command {
 	case "run":
 		runMRPCommand(ctx, os.Args[2:])
 	case "generate":
 		runGenerateCommand(ctx, os.Args[2:])
+	case "where-used":
+		runWhereUsedCommand(ctx, os.Args[2:])
+	case "bom":
+		runBOMCommand(ctx, os.Args[2:])
+	case "bom-diff":
+		runBOMDiffCommand(ctx, os.Args[2:])
+	case "validate":
+		runValidateCommand(ctx, os.Args[2:])
+	case "risk":
+		runRiskCommand(ctx, os.Args[2:])
+	case "actions":
+		runActionsCommand(ctx, os.Args[2:])
+	case "mps":
+		runMPSCommand(ctx, os.Args[2:])
 	case "help", "--help", "-h":
 		printUsage()
 	default:
@@ -35,50 +54,313 @@ func main() {
 
 func runMRPCommand(ctx context.Context, args []string) {
 	flagSet := flag.NewFlagSet("run", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
 
 	var (
-		scenarioDir = flagSet.String(
-			"scenario",
-			"",
-			"Path to scenario directory containing CSV files",
-		)
-		bomFile       = flagSet.String("bom", "", "Path to BOM CSV file")
-		itemsFile     = flagSet.String("items", "", "Path to items CSV file")
-		inventoryFile = flagSet.String("inventory", "", "Path to inventory CSV file")
-		demandsFile   = flagSet.String("demands", "", "Path to demands CSV file")
-		outputDir     = flagSet.String("output", "", "Output directory for results (optional)")
-		format        = flagSet.String("format", "text", "Output format: text, json, csv, html")
-		svgOutput     = flagSet.String("svg", "", "Generate SVG Gantt chart to specified file")
-		verbose       = flagSet.Bool("verbose", false, "Enable verbose output")
-		criticalPath  = flagSet.Bool("critical-path", false, "Perform critical path analysis")
-		topPaths      = flagSet.Int("top-paths", 3, "Number of top critical paths to analyze")
-		help          = flagSet.Bool("help", false, "Show help message")
+		outputDir    = flagSet.String("output", "", "Output directory for results (optional)")
+		format       = flagSet.String("format", "text", "Output format: text, json, csv, html")
+		svgOutput    = flagSet.String("svg", "", "Generate SVG Gantt chart to specified file")
+		criticalPath = flagSet.Bool("critical-path", false, "Perform critical path analysis")
+		topPaths     = flagSet.Int("top-paths", 3, "Number of top critical paths to analyze")
+		pathMode     = flagSet.String("path-mode", "lead-time", "Time critical paths by: lead-time, schedule")
+		nearCritical = flagSet.Int("near-critical", 5, "Report parts with less float than this many days as near-critical")
+		asOf         = flagSet.String("as-of", "", "Date (YYYY-MM-DD) builds are projected to start from (default: today)")
+		ecoImpact    = flagSet.String("eco-impact", "", "Show which planned orders an ECO would change")
+		timeout      = flagSet.Duration("timeout", 0, "Cancel the MRP run after this long, e.g. 30s (optional)")
+		failOnLate   = flagSet.Bool("fail-on-late", false, "Exit with a non-zero status when any demand is projected late")
 	)
 
 	flagSet.Parse(args)
 
 	// Create command configuration
-	config := commands.Config{
-		ScenarioDir:   *scenarioDir,
-		BOMFile:       *bomFile,
-		ItemsFile:     *itemsFile,
-		InventoryFile: *inventoryFile,
-		DemandsFile:   *demandsFile,
-		OutputDir:     *outputDir,
-		Format:        *format,
-		SVGOutput:     *svgOutput,
-		Verbose:       *verbose,
-		CriticalPath:  *criticalPath,
-		TopPaths:      *topPaths,
-		Help:          *help,
-	}
+	config := inputs.config()
+	config.OutputDir = *outputDir
+	config.Format = *format
+	config.SVGOutput = *svgOutput
+	config.CriticalPath = *criticalPath
+	config.TopPaths = *topPaths
+	config.PathMode = *pathMode
+	config.NearCritical = *nearCritical
+	config.AsOf = *asOf
+	config.ECOImpact = *ecoImpact
+	config.Timeout = *timeout
+	config.FailOnLate = *failOnLate
 
 	// Create and execute command
 	cmd := commands.NewMRPCommand(config)
 
 	if err := cmd.Execute(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runWhereUsedCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("where-used", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		partNumber = flagSet.String("part", "", "Part number to look up (required)")
+		serial     = flagSet.String("serial", "", "End item serial to filter effectivity by (optional)")
+		outputDir  = flagSet.String("output", "", "Output directory for results (optional)")
+		format     = flagSet.String("format", "text", "Output format: text, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.WhereUsedConfig{
+		Config:     inputs.config(),
+		PartNumber: *partNumber,
+		Serial:     *serial,
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewWhereUsedCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runBOMCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("bom", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		partNumber = flagSet.String("part", "", "Part number to explode (required)")
+		serial     = flagSet.String("serial", "", "Serial to resolve effectivity for (required)")
+		quantity   = flagSet.Int("qty", 1, "Units to explode")
+		date       = flagSet.String("date", "", "Need date (YYYY-MM-DD) for date effectivity (optional)")
+		outputDir  = flagSet.String("output", "", "Output directory for results (optional)")
+		format     = flagSet.String("format", "text", "Output format: text, csv, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.BOMConfig{
+		Config:     inputs.config(),
+		PartNumber: *partNumber,
+		Serial:     *serial,
+		Quantity:   *quantity,
+		Date:       *date,
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewBOMCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runBOMDiffCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("bom-diff", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		partNumber = flagSet.String("part", "", "Part number whose BOM to compare (required)")
+		fromSerial = flagSet.String("from", "", "Baseline serial (required)")
+		toSerial   = flagSet.String("to", "", "Serial to compare against the baseline (required)")
+		date       = flagSet.String("date", "", "Need date (YYYY-MM-DD) for date effectivity (optional)")
+		outputDir  = flagSet.String("output", "", "Output directory for results (optional)")
+		format     = flagSet.String("format", "text", "Output format: text, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.BOMDiffConfig{
+		Config:     inputs.config(),
+		PartNumber: *partNumber,
+		FromSerial: *fromSerial,
+		ToSerial:   *toSerial,
+		Date:       *date,
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewBOMDiffCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runValidateCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("validate", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		outputDir = flagSet.String("output", "", "Output directory for results (optional)")
+		format    = flagSet.String("format", "text", "Output format: text, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := inputs.config()
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewValidateCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runRiskCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("risk", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		iterations = flagSet.Int("iterations", 1000, "Iterations to simulate")
+		seed       = flagSet.Int64("seed", 1, "Random seed; the same seed gives the same results")
+		asOf       = flagSet.String("as-of", "", "Date (YYYY-MM-DD) builds start from (default: today)")
+		outputDir  = flagSet.String("output", "", "Output directory for results (required for csv)")
+		format     = flagSet.String("format", "text", "Output format: text, csv, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.RiskConfig{
+		Config:     inputs.config(),
+		Iterations: *iterations,
+		Seed:       *seed,
+		AsOf:       *asOf,
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewRiskCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runActionsCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("actions", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		outputDir = flagSet.String("output", "", "Output directory for results (required for csv)")
+		format    = flagSet.String("format", "text", "Output format: text, csv, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.ActionsConfig{
+		Config: inputs.config(),
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewActionsCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+func runMPSCommand(ctx context.Context, args []string) {
+	flagSet := flag.NewFlagSet("mps", flag.ExitOnError)
+	inputs := addScenarioFlags(flagSet)
+
+	var (
+		periodDays = flagSet.Int("period-days", 7, "Length of each period in days")
+		periods    = flagSet.Int("periods", 0, "Number of periods (default: to the latest forecast or order)")
+		asOf       = flagSet.String("as-of", "", "Date (YYYY-MM-DD) the first period starts (default: today)")
+		mpsOnly    = flagSet.Bool("mps-only", false, "Report the master schedule without exploding it through MRP")
+		outputDir  = flagSet.String("output", "", "Output directory for results (required for csv)")
+		format     = flagSet.String("format", "text", "Output format: text, csv, json")
+	)
+
+	flagSet.Parse(args)
+
+	config := commands.MPSConfig{
+		Config:     inputs.config(),
+		PeriodDays: *periodDays,
+		Periods:    *periods,
+		AsOf:       *asOf,
+		MPSOnly:    *mpsOnly,
+	}
+	config.OutputDir = *outputDir
+	config.Format = *format
+
+	cmd := commands.NewMPSCommand(config)
+
+	if err := cmd.Execute(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(status.ExitCode(err))
+	}
+}
+
+// scenarioFlags are the input flags shared by every command that loads a scenario
+type scenarioFlags struct {
+	scenarioDir   *string
+	bomFile       *string
+	itemsFile     *string
+	inventoryFile *string
+	demandsFile   *string
+	ecosFile      *string
+	pendingECOs   *bool
+	serialSchemes *string
+	receiptsFile  *string
+	firmOrders    *string
+	forecasts     *string
+	verbose       *bool
+	help          *bool
+}
+
+// addScenarioFlags registers the scenario input flags on a flag set
+func addScenarioFlags(flagSet *flag.FlagSet) *scenarioFlags {
+	return &scenarioFlags{
+		scenarioDir: flagSet.String(
+			"scenario",
+			"",
+			"Path to scenario directory containing CSV files",
+		),
+		bomFile:       flagSet.String("bom", "", "Path to BOM CSV file"),
+		itemsFile:     flagSet.String("items", "", "Path to items CSV file"),
+		inventoryFile: flagSet.String("inventory", "", "Path to inventory CSV file"),
+		demandsFile:   flagSet.String("demands", "", "Path to demands CSV file"),
+		ecosFile:      flagSet.String("ecos", "", "Path to ECO CSV file (optional)"),
+		pendingECOs:   flagSet.Bool("include-pending-ecos", false, "Also plan with draft ECOs"),
+		serialSchemes: flagSet.String("serial-schemes", "", "Path to serial schemes CSV file (optional)"),
+		receiptsFile:  flagSet.String("receipts", "", "Path to scheduled receipts CSV file (optional)"),
+		firmOrders:    flagSet.String("firm-orders", "", "Path to firm planned orders CSV file (optional)"),
+		forecasts:     flagSet.String("forecasts", "", "Path to forecasts CSV file (optional)"),
+		verbose:       flagSet.Bool("verbose", false, "Enable verbose output"),
+		help:          flagSet.Bool("help", false, "Show help message"),
+	}
+}
+
+// config returns a command configuration with the parsed scenario inputs
+func (f *scenarioFlags) config() commands.Config {
+	return commands.Config{
+		ScenarioDir:   *f.scenarioDir,
+		BOMFile:       *f.bomFile,
+		ItemsFile:     *f.itemsFile,
+		InventoryFile: *f.inventoryFile,
+		DemandsFile:   *f.demandsFile,
+		Verbose:       *f.verbose,
+		Help:          *f.help,
+
+		ECOsFile:           *f.ecosFile,
+		IncludePendingECOs: *f.pendingECOs,
+		SerialSchemesFile:  *f.serialSchemes,
+
+		ScheduledReceiptsFile: *f.receiptsFile,
+		FirmPlannedOrdersFile: *f.firmOrders,
+
+		ForecastsFile: *f.forecasts,
 	}
 }
 
@@ -135,7 +417,7 @@ func runGenerateCommand(ctx context.Context, args []string) {
 
 	if err := cmd.Execute(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(status.ExitCode(err))
 	}
 }
 
@@ -148,12 +430,40 @@ USAGE:
 COMMANDS:
     run         Run MRP analysis on existing scenario
     generate    Generate new test scenarios
+    where-used  Find every assembly, end item and demand using a part
+    bom         Print the indented BOM of a part for a serial
+    bom-diff    Compare the BOMs of two serials
+    validate    Check a scenario for data problems
+    risk        Simulate lead time variation for completion date percentiles
+    actions     List what to expedite, push out, cancel, increase or release
+    mps         Master schedule end items from forecasts and orders, then explode the MPS
     help        Show this help message
 
 EXAMPLES:
     # Run MRP on existing scenario
     mrp run --scenario ./examples/apollo_saturn_v
 
+    # Find every assembly and demand using a part
+    mrp where-used --scenario ./examples/apollo_csm --part RCS_VALVE --serial CSM107
+
+    # Print the indented BOM of one vehicle
+    mrp bom --scenario ./examples/apollo_saturn_v --part SATURN_V --serial SA506
+
+    # Compare the configurations of two serials
+    mrp bom-diff --scenario ./examples/apollo_saturn_v --part SATURN_V --from SA506 --to SA508
+
+    # Check a scenario for data problems before planning
+    mrp validate --scenario ./examples/apollo_saturn_v
+
+    # Completion date percentiles under lead time variation
+    mrp risk --scenario ./examples/apollo_engine_refurb --iterations 10000
+
+    # Action messages for open orders and planned orders
+    mrp actions --scenario ./examples/apollo_engine_refurb
+
+    # Master schedule forecasts and customer orders, then explode the MPS
+    mrp mps --scenario ./examples/apollo_engine_refurb --as-of 1969-03-03
+
     # Generate new test scenario
     mrp generate --items 1000 --max-depth 6 --demands 20 --inventory 0.5 --output ./test_scenario
 
@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// ActionMessageSummary is one action message with the instruction spelled out
+type ActionMessageSummary struct {
+	Rank        int                 `json:"rank"` // 1 is the most urgent
+	Action      string              `json:"action"`
+	PartNumber  entities.PartNumber `json:"part_number"`
+	Location    string              `json:"location"`
+	OrderNumber string              `json:"order_number,omitempty"`
+	Quantity    entities.Quantity   `json:"quantity"`
+	DueDate     time.Time           `json:"due_date"`
+	NeedDate    time.Time           `json:"need_date"` // Zero when nothing needs the receipt
+	Days        int                 `json:"days"`
+	Message     string              `json:"message"`
+}
+
+// ActionMessageReport lists what a planner should do to bring open and planned orders in line
+// with the plan, most urgent first
+type ActionMessageReport struct {
+	Counts   map[string]int         `json:"counts"` // Messages per action
+	Messages []ActionMessageSummary `json:"messages"`
+}
+
+// NewActionMessageReport spells out each action message, keeping their order
+func NewActionMessageReport(messages []entities.ActionMessage) ActionMessageReport {
+	report := ActionMessageReport{
+		Counts:   make(map[string]int),
+		Messages: make([]ActionMessageSummary, len(messages)),
+	}
+	for i, message := range messages {
+		report.Counts[message.Action.String()]++
+		report.Messages[i] = ActionMessageSummary{
+			Rank:        i + 1,
+			Action:      message.Action.String(),
+			PartNumber:  message.PartNumber,
+			Location:    message.Location,
+			OrderNumber: message.OrderNumber,
+			Quantity:    message.Quantity,
+			DueDate:     message.DueDate,
+			NeedDate:    message.NeedDate,
+			Days:        message.Days,
+			Message:     ActionMessageText(message),
+		}
+	}
+	return report
+}
+
+// ActionMessageText spells out what an action message asks the planner to do
+func ActionMessageText(message entities.ActionMessage) string {
+	switch message.Action {
+	case entities.ActionExpedite:
+		return fmt.Sprintf("Expedite %s by %d days, from %s to %s", message.OrderNumber, message.Days,
+			message.DueDate.Format("2006-01-02"), message.NeedDate.Format("2006-01-02"))
+	case entities.ActionPushOut:
+		return fmt.Sprintf("Push out %s by %d days, from %s to %s", message.OrderNumber, message.Days,
+			message.DueDate.Format("2006-01-02"), message.NeedDate.Format("2006-01-02"))
+	case entities.ActionCancel:
+		if message.NeedDate.IsZero() {
+			return fmt.Sprintf("Cancel %s: nothing needs its %d %s", message.OrderNumber, message.Quantity,
+				message.PartNumber)
+		}
+		return fmt.Sprintf("Cancel %d excess %s on %s", message.Quantity, message.PartNumber, message.OrderNumber)
+	case entities.ActionIncrease:
+		return fmt.Sprintf("Increase %s by %d instead of releasing new orders", message.OrderNumber,
+			message.Quantity)
+	case entities.ActionFirm:
+		if message.DueDate.IsZero() {
+			return fmt.Sprintf("Firm an order for %d %s needed %s: it is inside the demand time fence",
+				message.Quantity, message.PartNumber, message.NeedDate.Format("2006-01-02"))
+		}
+		return fmt.Sprintf("Firm an order for %d %s needed %s: the planning time fence holds MRP's order to %s",
+			message.Quantity, message.PartNumber, message.NeedDate.Format("2006-01-02"),
+			message.DueDate.Format("2006-01-02"))
+	case entities.ActionRelease:
+		text := fmt.Sprintf("Release an order for %d %s now, due %s", message.Quantity, message.PartNumber,
+			message.DueDate.Format("2006-01-02"))
+		if message.Days > 0 {
+			text += fmt.Sprintf(" (%d days after it is needed)", message.Days)
+		}
+		return text
+	default:
+		return message.Action.String()
+	}
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// BOMChangeType describes how a BOM position differs between two serials
+type BOMChangeType int
+
+const (
+	ComponentAdded BOMChangeType = iota
+	ComponentRemoved
+	ComponentSubstituted
+	ComponentQuantityChanged
+)
+
+// String method for BOMChangeType enum
+func (t BOMChangeType) String() string {
+	switch t {
+	case ComponentAdded:
+		return "Added"
+	case ComponentRemoved:
+		return "Removed"
+	case ComponentSubstituted:
+		return "Substituted"
+	case ComponentQuantityChanged:
+		return "QuantityChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// BOMDifference is a find number position whose component differs between two serials
+type BOMDifference struct {
+	ChangeType     BOMChangeType       `json:"-"`
+	ChangeTypeName string              `json:"change_type"`
+	Level          int                 `json:"level"`
+	ParentPN       entities.PartNumber `json:"parent_pn"`
+	FindNumber     int                 `json:"find_number"`
+	FromPart       entities.PartNumber `json:"from_part,omitempty"`
+	ToPart         entities.PartNumber `json:"to_part,omitempty"`
+	FromQtyPer     entities.Quantity   `json:"from_qty_per"`
+	ToQtyPer       entities.Quantity   `json:"to_qty_per"`
+}
+
+// BOMComparison lists the differences between the effective BOMs of two serials.
+// Positions below an added, removed or substituted component are not compared further.
+type BOMComparison struct {
+	PartNumber  entities.PartNumber `json:"part_number"`
+	FromSerial  string              `json:"from_serial"`
+	ToSerial    string              `json:"to_serial"`
+	Differences []BOMDifference     `json:"differences"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// AlternateOption is an effective alternate at a BOM position
+type AlternateOption struct {
+	PartNumber entities.PartNumber `json:"part_number"`
+	Priority   int                 `json:"priority"`
+	QtyPer     entities.Quantity   `json:"qty_per"`
+}
+
+// BOMReportLine is one row of an indented BOM: the alternate chosen at a find number
+type BOMReportLine struct {
+	Level         int                 `json:"level"`
+	FindNumber    int                 `json:"find_number"` // 0 for the top-level part
+	PartNumber    entities.PartNumber `json:"part_number"`
+	Description   string              `json:"description"`
+	QtyPer        entities.Quantity   `json:"qty_per"`
+	ExtendedQty   entities.Quantity   `json:"extended_qty"`
+	UnitOfMeasure string              `json:"unit_of_measure"`
+	Phantom       bool                `json:"phantom"`
+	FromSerial    string              `json:"from_serial"`
+	ToSerial      string              `json:"to_serial"`
+
+	// Alternates lists the effective alternates not chosen at this find number
+	Alternates []AlternateOption `json:"alternates,omitempty"`
+}
+
+// BOMReport is an effectivity-resolved, multi-level BOM in depth-first order
+type BOMReport struct {
+	PartNumber entities.PartNumber `json:"part_number"`
+	Serial     string              `json:"serial"`
+	Quantity   entities.Quantity   `json:"quantity"`
+	NeedDate   time.Time           `json:"need_date"` // Zero = date effectivity not applied
+	Lines      []BOMReportLine     `json:"lines"`
+}
@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// CriticalPathStep is one part on a critical path, from the top level part down
+type CriticalPathStep struct {
+	PartNumber         entities.PartNumber `json:"part_number"`
+	Description        string              `json:"description"`
+	Level              int                 `json:"level"`
+	LeadTimeDays       int                 `json:"lead_time_days"`
+	EffectiveLeadTime  int                 `json:"effective_lead_time"`
+	LeadTimeOffsetDays int                 `json:"lead_time_offset_days"`
+	CumulativeTime     int                 `json:"cumulative_time"` // Effective days from this part down the path
+	HasInventory       bool                `json:"has_inventory"`
+	InventoryQty       entities.Quantity   `json:"inventory_qty"`
+	RequiredQty        entities.Quantity   `json:"required_qty"`
+	ScheduledStart     *time.Time          `json:"scheduled_start,omitempty"` // Planned order dates, in schedule mode
+	ScheduledDue       *time.Time          `json:"scheduled_due,omitempty"`
+	TotalFloat         int                 `json:"total_float"`
+	FreeFloat          int                 `json:"free_float"`
+}
+
+// CriticalPathRoute is one ranked path through the BOM
+type CriticalPathRoute struct {
+	Rank              int                 `json:"rank"` // 1 = the critical path
+	TotalLeadTime     int                 `json:"total_lead_time"`
+	EffectiveLeadTime int                 `json:"effective_lead_time"`
+	PathLength        int                 `json:"path_length"`
+	BottleneckPart    entities.PartNumber `json:"bottleneck_part"`
+	Steps             []CriticalPathStep  `json:"steps"`
+}
+
+// CriticalPathReport contains the most critical paths of one critical path analysis
+type CriticalPathReport struct {
+	TopLevelPart entities.PartNumber `json:"top_level_part"`
+	TargetSerial string              `json:"target_serial"`
+	Location     string              `json:"location"`
+	Mode         string              `json:"mode"`
+	TotalPaths   int                 `json:"total_paths"` // Paths analyzed, capped at the largest int
+	Paths        []CriticalPathRoute `json:"paths"`       // Most critical first
+}
+
+// NewCriticalPathReport creates the report of a critical path analysis
+func NewCriticalPathReport(analysis *entities.CriticalPathAnalysis) CriticalPathReport {
+	report := CriticalPathReport{
+		TopLevelPart: analysis.TopLevelPart,
+		TargetSerial: analysis.TargetSerial,
+		Location:     analysis.Location,
+		Mode:         analysis.Mode.String(),
+		TotalPaths:   analysis.TotalPaths,
+		Paths:        make([]CriticalPathRoute, len(analysis.TopPaths)),
+	}
+
+	for i, path := range analysis.TopPaths {
+		route := CriticalPathRoute{
+			Rank:              i + 1,
+			TotalLeadTime:     path.TotalLeadTime,
+			EffectiveLeadTime: path.EffectiveLeadTime,
+			PathLength:        path.PathLength,
+			BottleneckPart:    path.BottleneckPart,
+			Steps:             make([]CriticalPathStep, len(path.PathDetails)),
+		}
+		for j, node := range path.PathDetails {
+			step := CriticalPathStep{
+				PartNumber:         node.PartNumber,
+				Description:        node.Description,
+				Level:              node.Level,
+				LeadTimeDays:       node.LeadTimeDays,
+				EffectiveLeadTime:  node.EffectiveLeadTime,
+				LeadTimeOffsetDays: node.LeadTimeOffsetDays,
+				CumulativeTime:     node.CumulativeTime,
+				HasInventory:       node.HasInventory,
+				InventoryQty:       node.InventoryQty,
+				RequiredQty:        node.RequiredQty,
+				TotalFloat:         node.TotalFloat,
+				FreeFloat:          node.FreeFloat,
+			}
+			if !node.ScheduledDue.IsZero() {
+				start, due := node.ScheduledStart, node.ScheduledDue
+				step.ScheduledStart, step.ScheduledDue = &start, &due
+			}
+			route.Steps[j] = step
+		}
+		report.Paths[i] = route
+	}
+
+	return report
+}
+
+// CriticalParts returns the parts on the critical path of the report
+func (r *CriticalPathReport) CriticalParts() []entities.PartNumber {
+	if len(r.Paths) == 0 {
+		return nil
+	}
+	parts := make([]entities.PartNumber, len(r.Paths[0].Steps))
+	for i, step := range r.Paths[0].Steps {
+		parts[i] = step.PartNumber
+	}
+	return parts
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// DemandFulfillmentSummary is the projected fulfillment of one demand, with what gates it
+type DemandFulfillmentSummary struct {
+	Demand         string              `json:"demand"` // Demand source, part and serial
+	PartNumber     entities.PartNumber `json:"part_number"`
+	TargetSerial   string              `json:"target_serial"`
+	Location       string              `json:"location"`
+	Quantity       entities.Quantity   `json:"quantity"`
+	NeedDate       time.Time           `json:"need_date"`
+	ProjectedDate  time.Time           `json:"projected_date"` // Zero when unfulfilled
+	DaysLate       int                 `json:"days_late"`      // Negative when early
+	Status         string              `json:"status"`         // on time, late or unfulfilled
+	CoveredByStock bool                `json:"covered_by_stock"`
+	StockQty       entities.Quantity   `json:"stock_qty"`
+	OrderQty       entities.Quantity   `json:"order_qty"`
+	ShortQty       entities.Quantity   `json:"short_qty"`
+	GatedBy        string              `json:"gated_by"` // The gating shortage, receipt or order, e.g. "order F1_ENGINE due 1968-09-01"
+}
+
+// DemandFulfillmentReport is the projected fulfillment of every demand of an MRP run
+type DemandFulfillmentReport struct {
+	TotalDemands int                        `json:"total_demands"`
+	LateDemands  int                        `json:"late_demands"` // Late or unfulfilled
+	Demands      []DemandFulfillmentSummary `json:"demands"`
+}
+
+// NewDemandFulfillmentReport labels the projected fulfillment of each demand for reporting
+func NewDemandFulfillmentReport(fulfillment []entities.DemandFulfillment) DemandFulfillmentReport {
+	report := DemandFulfillmentReport{
+		TotalDemands: len(fulfillment),
+		Demands:      make([]DemandFulfillmentSummary, len(fulfillment)),
+	}
+	for i := range fulfillment {
+		f := &fulfillment[i]
+		summary := DemandFulfillmentSummary{
+			Demand: DemandLabel(&entities.DemandRequirement{
+				PartNumber:   f.PartNumber,
+				DemandSource: f.DemandSource,
+				TargetSerial: f.TargetSerial,
+			}),
+			PartNumber:     f.PartNumber,
+			TargetSerial:   f.TargetSerial,
+			Location:       f.Location,
+			Quantity:       f.Quantity,
+			NeedDate:       f.NeedDate,
+			ProjectedDate:  f.ProjectedDate,
+			DaysLate:       f.DaysLate,
+			Status:         "on time",
+			CoveredByStock: f.CoveredByStock(),
+			StockQty:       f.StockQty,
+			OrderQty:       f.OrderQty,
+			ShortQty:       f.ShortQty,
+		}
+		switch {
+		case !f.Fulfilled():
+			summary.Status = "unfulfilled"
+		case f.IsLate():
+			summary.Status = "late"
+		}
+		switch {
+		case f.GatingShortage != nil:
+			summary.GatedBy = fmt.Sprintf("shortage %s (%d short)", f.GatingShortage.PartNumber, f.GatingShortage.ShortQty)
+		case f.GatingReceipt != nil:
+			summary.GatedBy = fmt.Sprintf("receipt %s due %s", f.GatingReceipt.OrderNumber, f.GatingReceipt.DueDate.Format("2006-01-02"))
+		case f.GatingOrder != nil:
+			summary.GatedBy = fmt.Sprintf("order %s due %s", f.GatingOrder.PartNumber, f.GatingOrder.DueDate.Format("2006-01-02"))
+		default:
+			summary.GatedBy = "stock"
+		}
+		if f.IsLate() {
+			report.LateDemands++
+		}
+		report.Demands[i] = summary
+	}
+	return report
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// OrderChangeType describes how an ECO changes the planned orders for a part
+type OrderChangeType int
+
+const (
+	OrderAdded OrderChangeType = iota
+	OrderRemoved
+	OrderQuantityChanged
+	OrderDatesChanged
+)
+
+// String method for OrderChangeType enum
+func (t OrderChangeType) String() string {
+	switch t {
+	case OrderAdded:
+		return "Added"
+	case OrderRemoved:
+		return "Removed"
+	case OrderQuantityChanged:
+		return "QuantityChanged"
+	case OrderDatesChanged:
+		return "DatesChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// PlannedOrderChange compares the planned orders for a part with and without an ECO
+type PlannedOrderChange struct {
+	PartNumber      entities.PartNumber `json:"part_number"`
+	ChangeType      OrderChangeType     `json:"-"`
+	ChangeTypeName  string              `json:"change_type"`
+	QuantityBefore  entities.Quantity   `json:"quantity_before"`
+	QuantityAfter   entities.Quantity   `json:"quantity_after"`
+	StartDateBefore time.Time           `json:"start_date_before"`
+	StartDateAfter  time.Time           `json:"start_date_after"`
+	DueDateBefore   time.Time           `json:"due_date_before"`
+	DueDateAfter    time.Time           `json:"due_date_after"`
+}
+
+// ECOImpact lists the planned orders an ECO would change
+type ECOImpact struct {
+	ECOID   string               `json:"eco_id"`
+	Status  string               `json:"status"`
+	Changes []PlannedOrderChange `json:"changes"`
+}
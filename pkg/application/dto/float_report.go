@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/vsinha/mrp/pkg/domain/entities"
+
+// PartFloat is the schedule of one part in days from the start of the build, and how many days
+// it can slip
+type PartFloat struct {
+	PartNumber     entities.PartNumber `json:"part_number"`
+	Description    string              `json:"description"`
+	Level          int                 `json:"level"`
+	LeadTimeDays   int                 `json:"lead_time_days"` // Days the part takes in this schedule
+	EarliestStart  int                 `json:"earliest_start"`
+	EarliestFinish int                 `json:"earliest_finish"`
+	LatestStart    int                 `json:"latest_start"`
+	LatestFinish   int                 `json:"latest_finish"`
+	TotalFloat     int                 `json:"total_float"` // Days of slip before the top level part is late
+	FreeFloat      int                 `json:"free_float"`  // Days of slip before any parent must start later
+}
+
+// FloatReport lists the parts of one critical path analysis with no float, and those with less
+// than NearCriticalDays of float
+type FloatReport struct {
+	TopLevelPart     entities.PartNumber `json:"top_level_part"`
+	TargetSerial     string              `json:"target_serial"`
+	Location         string              `json:"location"`
+	Mode             string              `json:"mode"` // How parts were timed: by lead time or by schedule
+	NearCriticalDays int                 `json:"near_critical_days"`
+	ZeroFloat        []PartFloat         `json:"zero_float"`
+	NearCritical     []PartFloat         `json:"near_critical"`
+}
+
+// NewFloatReport creates the float report of a critical path analysis
+func NewFloatReport(analysis *entities.CriticalPathAnalysis, nearCriticalDays int) FloatReport {
+	return FloatReport{
+		TopLevelPart:     analysis.TopLevelPart,
+		TargetSerial:     analysis.TargetSerial,
+		Location:         analysis.Location,
+		Mode:             analysis.Mode.String(),
+		NearCriticalDays: nearCriticalDays,
+		ZeroFloat:        newPartFloats(analysis.ZeroFloatParts()),
+		NearCritical:     newPartFloats(analysis.NearCriticalParts(nearCriticalDays)),
+	}
+}
+
+// newPartFloats converts critical path nodes into part floats
+func newPartFloats(nodes []entities.CriticalPathNode) []PartFloat {
+	floats := make([]PartFloat, len(nodes))
+	for i, node := range nodes {
+		floats[i] = PartFloat{
+			PartNumber:     node.PartNumber,
+			Description:    node.Description,
+			Level:          node.Level,
+			LeadTimeDays:   node.EarliestFinish - node.EarliestStart,
+			EarliestStart:  node.EarliestStart,
+			EarliestFinish: node.EarliestFinish,
+			LatestStart:    node.LatestStart,
+			LatestFinish:   node.LatestFinish,
+			TotalFloat:     node.TotalFloat,
+			FreeFloat:      node.FreeFloat,
+		}
+	}
+	return floats
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// MPSPeriodSummary is one period of an end item's master schedule
+type MPSPeriodSummary struct {
+	Start              time.Time         `json:"start"`
+	Zone               string            `json:"zone"` // frozen, slushy or liquid
+	Forecast           entities.Quantity `json:"forecast"`
+	CustomerOrders     entities.Quantity `json:"customer_orders"`
+	ProjectedDemand    entities.Quantity `json:"projected_demand"`
+	ScheduledReceipts  entities.Quantity `json:"scheduled_receipts"`
+	FirmMPS            entities.Quantity `json:"firm_mps"`
+	PlannedMPS         entities.Quantity `json:"planned_mps"`
+	ProjectedAvailable entities.Quantity `json:"projected_available"`
+	AvailableToPromise entities.Quantity `json:"available_to_promise"`
+	CumulativeATP      entities.Quantity `json:"cumulative_atp"`
+	TargetSerial       string            `json:"target_serial"`
+}
+
+// MPSItemReport is the master schedule of one end item at one location
+type MPSItemReport struct {
+	PartNumber        entities.PartNumber `json:"part_number"`
+	Location          string              `json:"location"`
+	OnHand            entities.Quantity   `json:"on_hand"`
+	SafetyStock       entities.Quantity   `json:"safety_stock"`
+	DemandTimeFence   time.Time           `json:"demand_time_fence"`   // Zero when the item has none
+	PlanningTimeFence time.Time           `json:"planning_time_fence"` // Zero when the item has none
+	TotalDemand       entities.Quantity   `json:"total_demand"`
+	TotalMPS          entities.Quantity   `json:"total_mps"`     // Firm and planned
+	ShortPeriods      int                 `json:"short_periods"` // Periods projected to end below zero
+	Periods           []MPSPeriodSummary  `json:"periods"`
+}
+
+// MasterScheduleReport is the master production schedule of every end item
+type MasterScheduleReport struct {
+	Start      time.Time       `json:"start"`
+	PeriodDays int             `json:"period_days"`
+	Items      []MPSItemReport `json:"items"`
+}
+
+// NewMasterScheduleReport creates the report of a master schedule
+func NewMasterScheduleReport(schedule *entities.MasterSchedule) *MasterScheduleReport {
+	report := &MasterScheduleReport{
+		Start:      schedule.Start,
+		PeriodDays: schedule.PeriodDays,
+		Items:      make([]MPSItemReport, len(schedule.Records)),
+	}
+
+	for i, record := range schedule.Records {
+		item := MPSItemReport{
+			PartNumber:        record.PartNumber,
+			Location:          record.Location,
+			OnHand:            record.OnHand,
+			SafetyStock:       record.SafetyStock,
+			DemandTimeFence:   record.DemandTimeFence,
+			PlanningTimeFence: record.PlanningTimeFence,
+			Periods:           make([]MPSPeriodSummary, len(record.Periods)),
+		}
+		for j, period := range record.Periods {
+			item.TotalDemand += period.ProjectedDemand
+			item.TotalMPS += period.MPSQuantity()
+			if period.ProjectedAvailable < 0 {
+				item.ShortPeriods++
+			}
+			item.Periods[j] = MPSPeriodSummary{
+				Start:              period.Start,
+				Zone:               period.Zone.String(),
+				Forecast:           period.Forecast,
+				CustomerOrders:     period.CustomerOrders,
+				ProjectedDemand:    period.ProjectedDemand,
+				ScheduledReceipts:  period.ScheduledReceipts,
+				FirmMPS:            period.FirmMPS,
+				PlannedMPS:         period.PlannedMPS,
+				ProjectedAvailable: period.ProjectedAvailable,
+				AvailableToPromise: period.AvailableToPromise,
+				CumulativeATP:      period.CumulativeATP,
+				TargetSerial:       period.TargetSerial,
+			}
+		}
+		report.Items[i] = item
+	}
+
+	return report
+}
@@ -8,21 +8,61 @@ import (
 
 // MRPResult contains the complete output of an MRP run
 type MRPResult struct {
-	PlannedOrders  []entities.PlannedOrder                `json:"planned_orders"`
-	Allocations    []entities.AllocationResult            `json:"allocations"`
-	ShortageReport []entities.Shortage                    `json:"shortages"`
-	ExplosionCache map[ExplosionCacheKey]*ExplosionResult `json:"-"`
+	PlannedOrders     []entities.PlannedOrder                `json:"planned_orders"`
+	Allocations       []entities.AllocationResult            `json:"allocations"`
+	ShortageReport    []entities.Shortage                    `json:"shortages"`
+	DemandFulfillment []entities.DemandFulfillment           `json:"demand_fulfillment"` // Per demand, in demand order
+	ActionMessages    []entities.ActionMessage               `json:"action_messages"`    // Most urgent first
+	ExplosionCache    map[ExplosionCacheKey]*ExplosionResult `json:"-"`
+	CacheStats        CacheStats                             `json:"cache_stats"`
 }
 
-// ExplosionCacheKey is used for memoizing BOM explosion results
+// LateDemands returns the fulfillment of every demand projected late or unfulfilled
+func (r *MRPResult) LateDemands() []entities.DemandFulfillment {
+	var late []entities.DemandFulfillment
+	for _, fulfillment := range r.DemandFulfillment {
+		if fulfillment.IsLate() {
+			late = append(late, fulfillment)
+		}
+	}
+	return late
+}
+
+// ExplosionCacheKey is used for memoizing BOM explosion results. Location is not part of the
+// key: a part explodes the same way wherever it is needed.
 type ExplosionCacheKey struct {
-	PartNumber        entities.PartNumber
-	SerialEffectivity entities.SerialEffectivity
+	PartNumber   entities.PartNumber
+	EndItem      entities.PartNumber // The serial schemes of the end item resolve TargetSerial
+	TargetSerial string
+	NeedDate     time.Time // Date effectivity depends on when the part is needed (zero when the BOM has none)
 }
 
-// ExplosionResult contains cached results of BOM explosion
+// ExplosionResult is the cached explosion of one unit of a part: the part itself first, then
+// every part below it in traversal order
 type ExplosionResult struct {
-	Requirements []entities.GrossRequirement
-	LeadTimeDays int
+	Requirements []ExplodedRequirement
 	ComputedAt   time.Time
 }
+
+// ExplodedRequirement is one part in a cached explosion, relative to the exploded part
+type ExplodedRequirement struct {
+	PartNumber     entities.PartNumber
+	QtyPer         entities.Quantity // Units needed per unit of the exploded part
+	NeedDateOffset int               // Days from the exploded part's need date (zero or negative)
+}
+
+// CacheStats counts explosion cache use during an MRP run
+type CacheStats struct {
+	Hits      int `json:"hits"`
+	Misses    int `json:"misses"`
+	Evictions int `json:"evictions"`
+	Entries   int `json:"entries"` // Entries held after the run
+}
+
+// HitRate returns the fraction of lookups answered from the cache
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
@@ -0,0 +1,129 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// DemandPathSummary is the critical path of one demand, and when it is projected to complete
+type DemandPathSummary struct {
+	Demand              string              `json:"demand"` // Demand source, part and serial
+	PartNumber          entities.PartNumber `json:"part_number"`
+	TargetSerial        string              `json:"target_serial"`
+	Location            string              `json:"location"`
+	Quantity            entities.Quantity   `json:"quantity"`
+	NeedDate            time.Time           `json:"need_date"`
+	ProjectedCompletion time.Time           `json:"projected_completion"`
+	DaysLate            int                 `json:"days_late"` // Negative when early
+	BuildDays           int                 `json:"build_days"`
+	BottleneckPart      entities.PartNumber `json:"bottleneck_part"`
+	CriticalParts       int                 `json:"critical_parts"` // Parts with no float
+}
+
+// BottleneckReport is one part with no float in the build of one or more demands
+type BottleneckReport struct {
+	Rank         int                 `json:"rank"`
+	PartNumber   entities.PartNumber `json:"part_number"`
+	Description  string              `json:"description"`
+	LeadTimeDays int                 `json:"lead_time_days"`
+	DemandsGated int                 `json:"demands_gated"`
+	LatenessDays int                 `json:"lateness_days"` // Days of lateness of the gated demands it accounts for
+	Demands      []string            `json:"demands"`
+}
+
+// SharedComponentReport is one part used by more than one demand
+type SharedComponentReport struct {
+	PartNumber   entities.PartNumber `json:"part_number"`
+	Description  string              `json:"description"`
+	DemandCount  int                 `json:"demand_count"`
+	CriticalFor  int                 `json:"critical_for"` // Demands the part has no float in
+	AllocatedQty entities.Quantity   `json:"allocated_qty"`
+	PlannedQty   entities.Quantity   `json:"planned_qty"`
+	ShortQty     entities.Quantity   `json:"short_qty"`
+	Demands      []string            `json:"demands"`
+}
+
+// ProgramReport combines the critical paths of every demand in a plan
+type ProgramReport struct {
+	AsOf             time.Time               `json:"as_of"`
+	Mode             string                  `json:"mode"`
+	TotalDemands     int                     `json:"total_demands"`
+	LateDemands      int                     `json:"late_demands"`
+	Demands          []DemandPathSummary     `json:"demands"`
+	Bottlenecks      []BottleneckReport      `json:"bottlenecks"`       // Most demands gated first
+	SharedComponents []SharedComponentReport `json:"shared_components"` // Most demands first
+}
+
+// NewProgramReport creates the report of a program critical path analysis
+func NewProgramReport(program *entities.ProgramCriticalPath) ProgramReport {
+	report := ProgramReport{
+		AsOf:             program.AsOf,
+		TotalDemands:     len(program.Demands),
+		LateDemands:      len(program.LateDemands()),
+		Demands:          make([]DemandPathSummary, len(program.Demands)),
+		Bottlenecks:      make([]BottleneckReport, len(program.Bottlenecks)),
+		SharedComponents: make([]SharedComponentReport, len(program.SharedComponents)),
+	}
+	if len(program.Demands) > 0 {
+		report.Mode = program.Demands[0].Analysis.Mode.String()
+	}
+
+	for i, demand := range program.Demands {
+		report.Demands[i] = DemandPathSummary{
+			Demand:              DemandLabel(demand.Demand),
+			PartNumber:          demand.Demand.PartNumber,
+			TargetSerial:        demand.Demand.TargetSerial,
+			Location:            demand.Demand.Location,
+			Quantity:            demand.Demand.Quantity,
+			NeedDate:            demand.Demand.NeedDate,
+			ProjectedCompletion: demand.ProjectedCompletion,
+			DaysLate:            demand.DaysLate,
+			BuildDays:           demand.Analysis.Duration(),
+			BottleneckPart:      demand.Analysis.CriticalPath.BottleneckPart,
+			CriticalParts:       len(demand.Analysis.ZeroFloatParts()),
+		}
+	}
+
+	for i, part := range program.Bottlenecks {
+		report.Bottlenecks[i] = BottleneckReport{
+			Rank:         i + 1,
+			PartNumber:   part.PartNumber,
+			Description:  part.Description,
+			LeadTimeDays: part.LeadTimeDays,
+			DemandsGated: len(part.Demands),
+			LatenessDays: part.LatenessDays,
+			Demands:      demandLabels(part.Demands),
+		}
+	}
+
+	for i, component := range program.SharedComponents {
+		report.SharedComponents[i] = SharedComponentReport{
+			PartNumber:   component.PartNumber,
+			Description:  component.Description,
+			DemandCount:  len(component.Demands),
+			CriticalFor:  component.CriticalFor,
+			AllocatedQty: component.AllocatedQty,
+			PlannedQty:   component.PlannedQty,
+			ShortQty:     component.ShortQty,
+			Demands:      demandLabels(component.Demands),
+		}
+	}
+
+	return report
+}
+
+// DemandLabel names a demand by its source, part and serial, as a source may place several
+func DemandLabel(demand *entities.DemandRequirement) string {
+	return fmt.Sprintf("%s/%s/%s", demand.DemandSource, demand.PartNumber, demand.TargetSerial)
+}
+
+// demandLabels names each of a list of demands
+func demandLabels(demands []*entities.DemandRequirement) []string {
+	labels := make([]string, len(demands))
+	for i, demand := range demands {
+		labels[i] = DemandLabel(demand)
+	}
+	return labels
+}
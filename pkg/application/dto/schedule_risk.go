@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// DemandRiskReport is the spread of completion dates of one demand
+type DemandRiskReport struct {
+	Demand            string              `json:"demand"` // Demand source, part and serial
+	PartNumber        entities.PartNumber `json:"part_number"`
+	NeedDate          time.Time           `json:"need_date"`
+	PlannedCompletion time.Time           `json:"planned_completion"` // With likely lead times
+	P50               time.Time           `json:"p50"`
+	P80               time.Time           `json:"p80"`
+	P95               time.Time           `json:"p95"`
+	OnTimeProbability float64             `json:"on_time_probability"`
+}
+
+// PartCriticalityReport is how often one part was on the critical path
+type PartCriticalityReport struct {
+	PartNumber       entities.PartNumber `json:"part_number"`
+	Description      string              `json:"description"`
+	Demands          int                 `json:"demands"`
+	CriticalityIndex float64             `json:"criticality_index"`
+	HasLeadTimeRange bool                `json:"has_lead_time_range"`
+}
+
+// ScheduleRiskReport is the result of a Monte Carlo simulation of lead times
+type ScheduleRiskReport struct {
+	AsOf       time.Time               `json:"as_of"`
+	Iterations int                     `json:"iterations"`
+	Seed       int64                   `json:"seed"`
+	Demands    []DemandRiskReport      `json:"demands"`
+	Parts      []PartCriticalityReport `json:"parts"` // Most critical first
+}
+
+// NewScheduleRiskReport creates the report of a schedule risk simulation
+func NewScheduleRiskReport(risk *entities.ScheduleRisk) *ScheduleRiskReport {
+	report := &ScheduleRiskReport{
+		AsOf:       risk.AsOf,
+		Iterations: risk.Iterations,
+		Seed:       risk.Seed,
+		Demands:    make([]DemandRiskReport, len(risk.Demands)),
+		Parts:      make([]PartCriticalityReport, len(risk.Parts)),
+	}
+
+	for i, demand := range risk.Demands {
+		report.Demands[i] = DemandRiskReport{
+			Demand:            DemandLabel(demand.Demand),
+			PartNumber:        demand.Demand.PartNumber,
+			NeedDate:          demand.Demand.NeedDate,
+			PlannedCompletion: demand.PlannedCompletion,
+			P50:               demand.P50,
+			P80:               demand.P80,
+			P95:               demand.P95,
+			OnTimeProbability: demand.OnTimeProbability,
+		}
+	}
+
+	for i, part := range risk.Parts {
+		report.Parts[i] = PartCriticalityReport{
+			PartNumber:       part.PartNumber,
+			Description:      part.Description,
+			Demands:          part.Demands,
+			CriticalityIndex: part.CriticalityIndex,
+			HasLeadTimeRange: part.HasLeadTimeRange,
+		}
+	}
+
+	return report
+}
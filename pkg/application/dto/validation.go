@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// ValidationSeverity ranks how serious a validation finding is
+type ValidationSeverity int
+
+const (
+	SeverityError ValidationSeverity = iota
+	SeverityWarning
+)
+
+// String method for ValidationSeverity enum
+func (s ValidationSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "Error"
+	case SeverityWarning:
+		return "Warning"
+	default:
+		return "Unknown"
+	}
+}
+
+// ValidationIssue is one problem found while validating a scenario
+type ValidationIssue struct {
+	Severity     ValidationSeverity  `json:"-"`
+	SeverityName string              `json:"severity"`
+	Check        string              `json:"check"` // Name of the check that found it, e.g. EffectivityGap
+	PartNumber   entities.PartNumber `json:"part_number"`
+	FindNumber   int                 `json:"find_number,omitempty"`
+	Message      string              `json:"message"`
+}
+
+// ValidationReport lists the problems found in a scenario, errors first
+type ValidationReport struct {
+	ErrorCount   int               `json:"error_count"`
+	WarningCount int               `json:"warning_count"`
+	Issues       []ValidationIssue `json:"issues"`
+}
+
+// HasErrors reports whether any finding is an error rather than a warning
+func (r *ValidationReport) HasErrors() bool {
+	return r.ErrorCount > 0
+}
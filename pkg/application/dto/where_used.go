@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// WhereUsedLine is a BOM line found while imploding a part toward its end items
+type WhereUsedLine struct {
+	Level      int                 `json:"level"` // 1 = direct parent of the imploded part
+	ParentPN   entities.PartNumber `json:"parent_pn"`
+	ChildPN    entities.PartNumber `json:"child_pn"`
+	QtyPer     entities.Quantity   `json:"qty_per"`
+	FindNumber int                 `json:"find_number"`
+	FromSerial string              `json:"from_serial"`
+	ToSerial   string              `json:"to_serial"`
+}
+
+// EndItemUsage is a top-level item that uses the imploded part
+type EndItemUsage struct {
+	EndItem     entities.PartNumber   `json:"end_item"`
+	Level       int                   `json:"level"`        // Shortest distance down to the part
+	Path        []entities.PartNumber `json:"path"`         // A shortest path, from the end item down to the part
+	QuantityPer entities.Quantity     `json:"quantity_per"` // Parts used per end item
+}
+
+// AffectedDemand is a demand whose explosion uses the imploded part
+type AffectedDemand struct {
+	DemandSource  string              `json:"demand_source"`
+	PartNumber    entities.PartNumber `json:"part_number"`
+	Quantity      entities.Quantity   `json:"quantity"`
+	NeedDate      time.Time           `json:"need_date"`
+	Location      string              `json:"location"`
+	TargetSerial  string              `json:"target_serial"`
+	PartsRequired entities.Quantity   `json:"parts_required"` // Imploded parts this demand consumes
+}
+
+// WhereUsedResult lists every assembly, end item and demand using a part
+type WhereUsedResult struct {
+	PartNumber entities.PartNumber `json:"part_number"`
+	Serial     string              `json:"serial,omitempty"` // Empty = all serials
+	Usages     []WhereUsedLine     `json:"usages"`
+	EndItems   []EndItemUsage      `json:"end_items"`
+	Demands    []AffectedDemand    `json:"demands"`
+}
@@ -0,0 +1,136 @@
+package bomreport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/domain/services"
+)
+
+// BOMComparisonService compares the effective BOM structures of two serials
+type BOMComparisonService struct {
+	reportService *BOMReportService
+	serialComp    *services.SerialComparator
+}
+
+// NewBOMComparisonService creates a new BOM comparison service
+func NewBOMComparisonService(
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+	serialComp *services.SerialComparator,
+) *BOMComparisonService {
+	if serialComp == nil {
+		serialComp = services.NewSerialComparator()
+	}
+	return &BOMComparisonService{
+		reportService: NewBOMReportService(bomRepo, itemRepo, inventoryRepo),
+		serialComp:    serialComp,
+	}
+}
+
+// CompareSerials reports the components added, removed, substituted or changed in quantity
+// at each find number between the BOMs of fromSerial and toSerial. Both serials must follow
+// the part's serial scheme. A zero needDate skips date effectivity.
+func (s *BOMComparisonService) CompareSerials(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	fromSerial, toSerial string,
+	needDate time.Time,
+) (*dto.BOMComparison, error) {
+	if _, err := s.serialComp.Compare(partNumber, fromSerial, toSerial); err != nil {
+		return nil, fmt.Errorf("cannot compare serials of %s: %w", partNumber, err)
+	}
+
+	from, err := s.reportService.explode(ctx, partNumber, fromSerial, 1, needDate)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.reportService.explode(ctx, partNumber, toSerial, 1, needDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BOMComparison{
+		PartNumber:  partNumber,
+		FromSerial:  fromSerial,
+		ToSerial:    toSerial,
+		Differences: compareNodes(from, to, nil),
+	}, nil
+}
+
+// compareNodes compares the children of two nodes for the same part, position by position,
+// descending only into positions whose component is unchanged
+func compareNodes(from, to *bomReportNode, diffs []dto.BOMDifference) []dto.BOMDifference {
+	fromChildren := childrenByFindNumber(from)
+	toChildren := childrenByFindNumber(to)
+
+	var findNumbers []int
+	for findNumber := range fromChildren {
+		findNumbers = append(findNumbers, findNumber)
+	}
+	for findNumber := range toChildren {
+		if _, exists := fromChildren[findNumber]; !exists {
+			findNumbers = append(findNumbers, findNumber)
+		}
+	}
+	sort.Ints(findNumbers)
+
+	for _, findNumber := range findNumbers {
+		fromChild, inFrom := fromChildren[findNumber]
+		toChild, inTo := toChildren[findNumber]
+
+		diff := dto.BOMDifference{
+			Level:      from.line.Level + 1,
+			ParentPN:   from.line.PartNumber,
+			FindNumber: findNumber,
+		}
+		if inFrom {
+			diff.FromPart = fromChild.line.PartNumber
+			diff.FromQtyPer = fromChild.line.QtyPer
+		}
+		if inTo {
+			diff.ToPart = toChild.line.PartNumber
+			diff.ToQtyPer = toChild.line.QtyPer
+		}
+
+		switch {
+		case !inTo:
+			diff.ChangeType = dto.ComponentRemoved
+		case !inFrom:
+			diff.ChangeType = dto.ComponentAdded
+		case diff.FromPart != diff.ToPart:
+			diff.ChangeType = dto.ComponentSubstituted
+		case diff.FromQtyPer != diff.ToQtyPer:
+			diff.ChangeType = dto.ComponentQuantityChanged
+		default:
+			diffs = compareNodes(fromChild, toChild, diffs)
+			continue
+		}
+
+		diff.ChangeTypeName = diff.ChangeType.String()
+		diffs = append(diffs, diff)
+
+		// Same component, different quantity: its own structure may differ too
+		if diff.ChangeType == dto.ComponentQuantityChanged {
+			diffs = compareNodes(fromChild, toChild, diffs)
+		}
+	}
+
+	return diffs
+}
+
+// childrenByFindNumber indexes a node's children by find number; traversal chooses
+// one alternate per find number
+func childrenByFindNumber(node *bomReportNode) map[int]*bomReportNode {
+	children := make(map[int]*bomReportNode, len(node.children))
+	for _, child := range node.children {
+		children[child.line.FindNumber] = child
+	}
+	return children
+}
@@ -0,0 +1,78 @@
+package bomreport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+)
+
+// BOMReportService explodes a BOM for one serial without planning it
+type BOMReportService struct {
+	bomTraverser *shared.BOMTraverser
+}
+
+// NewBOMReportService creates a new BOM report service
+func NewBOMReportService(
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+) *BOMReportService {
+	return &BOMReportService{
+		bomTraverser: shared.NewBOMTraverser(bomRepo, itemRepo, inventoryRepo),
+	}
+}
+
+// ExplodeBOM returns the indented BOM of quantity units of a part for a serial, choosing the
+// same alternates MRP would. A zero needDate skips date effectivity. An assembly anywhere in
+// the BOM none of whose lines is effective for the serial yields an
+// *entities.NoEffectiveAlternateError.
+func (s *BOMReportService) ExplodeBOM(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	serial string,
+	quantity entities.Quantity,
+	needDate time.Time,
+) (*dto.BOMReport, error) {
+	root, err := s.explode(ctx, partNumber, serial, quantity, needDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BOMReport{
+		PartNumber: partNumber,
+		Serial:     serial,
+		Quantity:   quantity,
+		NeedDate:   needDate,
+		Lines:      root.flatten(nil),
+	}, nil
+}
+
+// explode traverses the BOM into a tree of report lines; the part must have an effective BOM
+// for the serial if it has one at all
+func (s *BOMReportService) explode(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	serial string,
+	quantity entities.Quantity,
+	needDate time.Time,
+) (*bomReportNode, error) {
+	result, err := s.bomTraverser.TraverseBOM(
+		ctx,
+		partNumber,
+		serial,
+		"",
+		quantity,
+		needDate,
+		0,
+		NewBOMReportVisitor(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explode BOM for %s: %w", partNumber, err)
+	}
+	return result.(*bomReportNode), nil
+}
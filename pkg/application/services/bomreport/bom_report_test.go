@@ -0,0 +1,182 @@
+package bomreport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	testinghelpers "github.com/vsinha/mrp/pkg/application/services/testing"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+func TestBOMReportService_ExplodeBOM(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, _ := testinghelpers.BuildAerospaceTestData()
+
+	// A lower priority alternate for the V2 turbopump
+	if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+		ParentPN:    "F1_ENGINE",
+		ChildPN:     "F1_TURBOPUMP_V1",
+		QtyPer:      1,
+		FindNumber:  300,
+		Effectivity: entities.SerialEffectivity{FromSerial: "AS506", ToSerial: ""},
+		Priority:    1,
+	}); err != nil {
+		t.Fatalf("Failed to save BOM line: %v", err)
+	}
+
+	service := NewBOMReportService(bomRepo, itemRepo, inventoryRepo)
+	report, err := service.ExplodeBOM(context.Background(), "SATURN_V", "AS506", 2, time.Time{})
+	if err != nil {
+		t.Fatalf("ExplodeBOM failed: %v", err)
+	}
+
+	expected := []struct {
+		level       int
+		findNumber  int
+		partNumber  entities.PartNumber
+		qtyPer      entities.Quantity
+		extendedQty entities.Quantity
+	}{
+		{0, 0, "SATURN_V", 2, 2},
+		{1, 100, "F1_ENGINE", 5, 10},
+		{2, 300, "F1_TURBOPUMP_V2", 1, 10},
+		{1, 200, "J2_ENGINE_V1", 6, 12},
+	}
+
+	if len(report.Lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %+v", len(expected), len(report.Lines), report.Lines)
+	}
+	for i, want := range expected {
+		got := report.Lines[i]
+		if got.Level != want.level || got.FindNumber != want.findNumber ||
+			got.PartNumber != want.partNumber || got.QtyPer != want.qtyPer ||
+			got.ExtendedQty != want.extendedQty {
+			t.Errorf("Line %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// The chosen V2 turbopump lists V1 as its available alternate
+	turbopump := report.Lines[2]
+	if len(turbopump.Alternates) != 1 ||
+		turbopump.Alternates[0].PartNumber != "F1_TURBOPUMP_V1" ||
+		turbopump.Alternates[0].Priority != 1 {
+		t.Errorf("Expected F1_TURBOPUMP_V1 as the alternate, got %+v", turbopump.Alternates)
+	}
+	if len(report.Lines[1].Alternates) != 0 {
+		t.Errorf("Expected no alternates for F1_ENGINE, got %+v", report.Lines[1].Alternates)
+	}
+}
+
+func TestBOMComparisonService_CompareSerials(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, _ := testinghelpers.BuildAerospaceTestData()
+
+	// Two instrument units per vehicle from AS506, and an escape tower flown up to AS505 only
+	for _, pn := range []entities.PartNumber{"INSTRUMENT_UNIT", "LES_TOWER"} {
+		if err := itemRepo.SaveItem(&entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  60,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   1,
+			MaxOrderQty:   10,
+			UnitOfMeasure: "EA",
+		}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+	extraLines := []*entities.BOMLine{
+		{
+			ParentPN:    "SATURN_V",
+			ChildPN:     "INSTRUMENT_UNIT",
+			QtyPer:      1,
+			FindNumber:  400,
+			Effectivity: entities.SerialEffectivity{FromSerial: "AS501", ToSerial: "AS505"},
+		},
+		{
+			ParentPN:    "SATURN_V",
+			ChildPN:     "INSTRUMENT_UNIT",
+			QtyPer:      2,
+			FindNumber:  400,
+			Effectivity: entities.SerialEffectivity{FromSerial: "AS506", ToSerial: ""},
+		},
+		{
+			ParentPN:    "SATURN_V",
+			ChildPN:     "LES_TOWER",
+			QtyPer:      1,
+			FindNumber:  500,
+			Effectivity: entities.SerialEffectivity{FromSerial: "AS501", ToSerial: "AS505"},
+		},
+	}
+	for _, line := range extraLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	service := NewBOMComparisonService(bomRepo, itemRepo, inventoryRepo, nil)
+	comparison, err := service.CompareSerials(context.Background(), "SATURN_V", "AS505", "AS507", time.Time{})
+	if err != nil {
+		t.Fatalf("CompareSerials failed: %v", err)
+	}
+
+	expected := []struct {
+		changeType dto.BOMChangeType
+		parentPN   entities.PartNumber
+		findNumber int
+		fromPart   entities.PartNumber
+		toPart     entities.PartNumber
+	}{
+		{dto.ComponentSubstituted, "F1_ENGINE", 300, "F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"},
+		{dto.ComponentSubstituted, "SATURN_V", 200, "J2_ENGINE_V1", "J2_ENGINE_V2"},
+		{dto.ComponentQuantityChanged, "SATURN_V", 400, "INSTRUMENT_UNIT", "INSTRUMENT_UNIT"},
+		{dto.ComponentRemoved, "SATURN_V", 500, "LES_TOWER", ""},
+	}
+
+	if len(comparison.Differences) != len(expected) {
+		t.Fatalf("Expected %d differences, got %+v", len(expected), comparison.Differences)
+	}
+	for i, want := range expected {
+		got := comparison.Differences[i]
+		if got.ChangeType != want.changeType || got.ParentPN != want.parentPN ||
+			got.FindNumber != want.findNumber || got.FromPart != want.fromPart || got.ToPart != want.toPart {
+			t.Errorf("Difference %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// Comparing a serial with itself finds nothing
+	same, err := service.CompareSerials(context.Background(), "SATURN_V", "AS507", "AS507", time.Time{})
+	if err != nil {
+		t.Fatalf("CompareSerials failed: %v", err)
+	}
+	if len(same.Differences) != 0 {
+		t.Errorf("Expected no differences for the same serial, got %+v", same.Differences)
+	}
+
+	// Serials outside the part's scheme are rejected
+	if _, err := service.CompareSerials(context.Background(), "SATURN_V", "AS505", "507", time.Time{}); err == nil {
+		t.Error("Expected an error for a serial that fits no scheme")
+	}
+}
+
+func TestBOMReportService_ExplodeBOM_NoEffectiveLines(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, _ := testinghelpers.BuildAerospaceTestData()
+	service := NewBOMReportService(bomRepo, itemRepo, inventoryRepo)
+
+	// SATURN_V has a BOM, but none of it is effective before AS501
+	_, err := service.ExplodeBOM(context.Background(), "SATURN_V", "AS499", 1, time.Time{})
+	var noEffective *entities.NoEffectiveAlternateError
+	if !errors.As(err, &noEffective) || noEffective.ParentPN != "SATURN_V" || noEffective.Serial != "AS499" {
+		t.Fatalf("Expected a NoEffectiveAlternateError for SATURN_V AS499, got %v", err)
+	}
+
+	// A part without a BOM is a valid one-line report
+	report, err := service.ExplodeBOM(context.Background(), "F1_TURBOPUMP_V1", "AS499", 1, time.Time{})
+	if err != nil {
+		t.Fatalf("ExplodeBOM failed for a buy part: %v", err)
+	}
+	if len(report.Lines) != 1 {
+		t.Errorf("Expected a single line, got %+v", report.Lines)
+	}
+}
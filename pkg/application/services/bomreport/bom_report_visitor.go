@@ -0,0 +1,88 @@
+package bomreport
+
+import (
+	"context"
+	"sort"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+)
+
+// BOMReportVisitor implements BOMNodeVisitor to build an indented BOM
+type BOMReportVisitor struct{}
+
+// bomReportNode is a report line with its children, as returned from ProcessChildren
+type bomReportNode struct {
+	line     dto.BOMReportLine
+	children []*bomReportNode
+}
+
+// NewBOMReportVisitor creates a new BOM report visitor
+func NewBOMReportVisitor() *BOMReportVisitor {
+	return &BOMReportVisitor{}
+}
+
+// VisitNode builds the report line for this part
+func (v *BOMReportVisitor) VisitNode(
+	ctx context.Context,
+	nodeCtx shared.BOMNodeContext,
+) (interface{}, bool, error) {
+	line := dto.BOMReportLine{
+		Level:         nodeCtx.Level,
+		PartNumber:    nodeCtx.PartNumber,
+		Description:   nodeCtx.Item.Description,
+		QtyPer:        nodeCtx.Quantity,
+		ExtendedQty:   nodeCtx.Quantity,
+		UnitOfMeasure: nodeCtx.Item.UnitOfMeasure,
+		Phantom:       nodeCtx.Item.Phantom,
+	}
+
+	if nodeCtx.BOMLine != nil {
+		line.FindNumber = nodeCtx.BOMLine.FindNumber
+		line.QtyPer = nodeCtx.BOMLine.QtyPer
+		line.FromSerial = nodeCtx.BOMLine.Effectivity.FromSerial
+		line.ToSerial = nodeCtx.BOMLine.Effectivity.ToSerial
+
+		for _, alternate := range nodeCtx.Alternates {
+			if alternate.ChildPN == nodeCtx.BOMLine.ChildPN {
+				continue
+			}
+			line.Alternates = append(line.Alternates, dto.AlternateOption{
+				PartNumber: alternate.ChildPN,
+				Priority:   alternate.Priority,
+				QtyPer:     alternate.QtyPer,
+			})
+		}
+		sort.Slice(line.Alternates, func(i, j int) bool {
+			return line.Alternates[i].Priority < line.Alternates[j].Priority
+		})
+	}
+
+	return &bomReportNode{line: line}, true, nil
+}
+
+// ProcessChildren attaches the children in find number order
+func (v *BOMReportVisitor) ProcessChildren(
+	ctx context.Context,
+	nodeCtx shared.BOMNodeContext,
+	nodeData interface{},
+	childResults []interface{},
+) (interface{}, error) {
+	node := nodeData.(*bomReportNode)
+	for _, result := range childResults {
+		node.children = append(node.children, result.(*bomReportNode))
+	}
+	sort.SliceStable(node.children, func(i, j int) bool {
+		return node.children[i].line.FindNumber < node.children[j].line.FindNumber
+	})
+	return node, nil
+}
+
+// flatten returns the node and its descendants in depth-first order
+func (n *bomReportNode) flatten(lines []dto.BOMReportLine) []dto.BOMReportLine {
+	lines = append(lines, n.line)
+	for _, child := range n.children {
+		lines = child.flatten(lines)
+	}
+	return lines
+}
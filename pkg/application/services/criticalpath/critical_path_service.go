@@ -3,7 +3,6 @@ package criticalpath
 import (
 	"context"
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/vsinha/mrp/pkg/application/services/shared"
@@ -38,69 +37,32 @@ func NewCriticalPathService(
 	}
 }
 
-// AnalyzeCriticalPath performs critical path analysis for a given part and returns top N paths
+// AnalyzeCriticalPath performs critical path analysis for a given part and returns top N paths,
+// ranked by effective lead time, with the float of every part on effective lead times. Date
+// effective alternates are those in effect on needDate; a zero needDate ignores date effectivity.
 func (cps *CriticalPathService) AnalyzeCriticalPath(
 	ctx context.Context,
 	partNumber entities.PartNumber,
 	targetSerial string,
 	location string,
+	needDate time.Time,
 	topN int,
 ) (*entities.CriticalPathAnalysis, error) {
-	// Get all paths through the BOM
-	allPaths, err := cps.findAllPaths(ctx, partNumber, targetSerial, location, 1, 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find paths for %s: %w", partNumber, err)
-	}
-
-	if len(allPaths) == 0 {
-		return &entities.CriticalPathAnalysis{
-			TopLevelPart: partNumber,
-			TargetSerial: targetSerial,
-			Location:     location,
-			AnalysisDate: time.Now(),
-			TotalPaths:   0,
-		}, nil
-	}
-
-	// Sort paths by effective lead time (descending)
-	sort.Slice(allPaths, func(i, j int) bool {
-		// Primary sort: effective lead time
-		if allPaths[i].EffectiveLeadTime != allPaths[j].EffectiveLeadTime {
-			return allPaths[i].EffectiveLeadTime > allPaths[j].EffectiveLeadTime
-		}
-		// Secondary sort: total lead time (ignoring inventory)
-		if allPaths[i].TotalLeadTime != allPaths[j].TotalLeadTime {
-			return allPaths[i].TotalLeadTime > allPaths[j].TotalLeadTime
-		}
-		// Tertiary sort: path length (longer paths first)
-		return allPaths[i].PathLength > allPaths[j].PathLength
+	return cps.analyze(ctx, partNumber, targetSerial, location, needDate, topN, analysisMode{
+		mode:     entities.LeadTimeMode,
+		rank:     RankByEffectiveLeadTime,
+		leadTime: effectiveLeadTime,
 	})
-
-	// Get top N paths
-	topPaths := allPaths
-	if len(allPaths) > topN {
-		topPaths = allPaths[:topN]
-	}
-
-	analysis := &entities.CriticalPathAnalysis{
-		TopLevelPart: partNumber,
-		TargetSerial: targetSerial,
-		Location:     location,
-		AnalysisDate: time.Now(),
-		CriticalPath: allPaths[0], // Longest path
-		TopPaths:     topPaths,
-		TotalPaths:   len(allPaths),
-	}
-
-	return analysis, nil
 }
 
-// AnalyzeCriticalPathWithAllocations performs critical path analysis using MRP allocation results
+// AnalyzeCriticalPathWithAllocations performs critical path analysis using MRP allocation
+// results, ranking paths and scheduling float by total lead time
 func (cps *CriticalPathService) AnalyzeCriticalPathWithAllocations(
 	ctx context.Context,
 	partNumber entities.PartNumber,
 	targetSerial string,
 	location string,
+	needDate time.Time,
 	topN int,
 	allocations []entities.AllocationResult,
 ) (*entities.CriticalPathAnalysis, error) {
@@ -108,70 +70,130 @@ func (cps *CriticalPathService) AnalyzeCriticalPathWithAllocations(
 	cps.bomTraverser.SetAllocationContext(allocations)
 	defer cps.bomTraverser.ClearAllocationContext() // Clean up after analysis
 
-	// Get all paths through the BOM using allocation context
-	allPaths, err := cps.findAllPaths(ctx, partNumber, targetSerial, location, 1, 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find paths for %s: %w", partNumber, err)
-	}
+	return cps.analyze(ctx, partNumber, targetSerial, location, needDate, topN, analysisMode{
+		mode:     entities.LeadTimeMode,
+		rank:     RankByTotalLeadTime,
+		leadTime: planningLeadTime,
+	})
+}
 
-	if len(allPaths) == 0 {
-		return &entities.CriticalPathAnalysis{
-			TopLevelPart: partNumber,
-			TargetSerial: targetSerial,
-			Location:     location,
-			AnalysisDate: time.Now(),
-			TotalPaths:   0,
-		}, nil
-	}
+// AnalyzeCriticalPathFromSchedule performs critical path analysis on the planned orders and
+// allocations of an MRP run, so that paths follow the Gantt chart: each part takes as long as
+// its planned orders, whatever its lead time, and parts covered by inventory take no time.
+// Paths are ranked, and float scheduled, by these order spans.
+func (cps *CriticalPathService) AnalyzeCriticalPathFromSchedule(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	targetSerial string,
+	location string,
+	needDate time.Time,
+	topN int,
+	orders []entities.PlannedOrder,
+	allocations []entities.AllocationResult,
+) (*entities.CriticalPathAnalysis, error) {
+	cps.bomTraverser.SetAllocationContext(allocations)
+	defer cps.bomTraverser.ClearAllocationContext()
 
-	// Sort paths by total lead time (descending - longest first)
-	sort.Slice(allPaths, func(i, j int) bool {
-		return allPaths[i].TotalLeadTime > allPaths[j].TotalLeadTime
+	return cps.analyze(ctx, partNumber, targetSerial, location, needDate, topN, analysisMode{
+		mode:     entities.ScheduleMode,
+		rank:     RankByEffectiveLeadTime,
+		leadTime: effectiveLeadTime,
+		orders:   orders,
 	})
+}
 
-	// Get top N paths
-	if topN > len(allPaths) {
-		topN = len(allPaths)
+// analysisMode configures how an analysis times and ranks parts
+type analysisMode struct {
+	mode     entities.CriticalPathMode
+	rank     PathRanking
+	leadTime leadTimeBasis
+	orders   []entities.PlannedOrder // Times parts in schedule mode
+}
+
+// analyze finds the top N paths through the BOM of a part, and the float of its parts
+func (cps *CriticalPathService) analyze(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	targetSerial string,
+	location string,
+	needDate time.Time,
+	topN int,
+	mode analysisMode,
+) (*entities.CriticalPathAnalysis, error) {
+	paths, totalPaths, partFloat, err := cps.findTopPaths(
+		ctx, partNumber, targetSerial, location, needDate, topN, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find paths for %s: %w", partNumber, err)
 	}
-	topPaths := allPaths[:topN]
 
 	analysis := &entities.CriticalPathAnalysis{
 		TopLevelPart: partNumber,
 		TargetSerial: targetSerial,
 		Location:     location,
+		Mode:         mode.mode,
 		AnalysisDate: time.Now(),
-		CriticalPath: allPaths[0], // Longest path
-		TopPaths:     topPaths,
-		TotalPaths:   len(allPaths),
+		TotalPaths:   totalPaths,
+		PartFloat:    partFloat,
+	}
+	if len(paths) == 0 {
+		return analysis, nil
 	}
 
+	analysis.CriticalPath = paths[0] // Longest path
+	if topN < len(paths) {
+		paths = paths[:topN]
+	}
+	analysis.TopPaths = paths
 	return analysis, nil
 }
 
-// findAllPaths recursively finds all paths through the BOM structure using BOMTraverser
-func (cps *CriticalPathService) findAllPaths(
+// newVisitor creates a critical path visitor for the BOM, keeping the topN most critical paths
+// by rank
+func (cps *CriticalPathService) newVisitor(topN int, rank PathRanking) (*CriticalPathVisitor, error) {
+	bomLines, err := cps.bomRepo.GetAllBOMLines()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOM lines: %w", err)
+	}
+	visitor := NewCriticalPathVisitor(cps.inventoryRepo, cps.serialComp, topN, rank)
+	visitor.SetDatedParts(bomLines)
+	return visitor, nil
+}
+
+// findTopPaths finds the topN most critical paths through the BOM structure, how many paths
+// there are in all, and the float of every part, using BOMTraverser over the BOM as a DAG
+func (cps *CriticalPathService) findTopPaths(
 	ctx context.Context,
 	partNumber entities.PartNumber,
 	targetSerial string,
 	location string,
-	quantity entities.Quantity,
-	level int,
-) ([]entities.CriticalPath, error) {
-	// Use BOMTraverser with CriticalPathVisitor to perform the traversal
-	visitor := NewCriticalPathVisitor(cps.inventoryRepo, cps.serialComp)
+	needDate time.Time,
+	topN int,
+	mode analysisMode,
+) ([]entities.CriticalPath, int, []entities.CriticalPathNode, error) {
+	visitor, err := cps.newVisitor(topN, mode.rank)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if mode.mode == entities.ScheduleMode {
+		visitor.SetSchedule(mode.orders)
+	}
+	// Date effective alternates are chosen for the need date, as MRP explodes them
 	result, err := cps.bomTraverser.TraverseBOM(
 		ctx,
 		partNumber,
 		targetSerial,
 		location,
-		quantity,
-		level,
+		1,
+		needDate,
+		0,
 		visitor,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to traverse BOM for %s: %w", partNumber, err)
+		return nil, 0, nil, fmt.Errorf("failed to traverse BOM for %s: %w", partNumber, err)
 	}
 
-	paths := result.([]entities.CriticalPath)
-	return paths, nil
+	paths := visitor.Paths(result, 0)
+	partFloat := visitor.partFloat(result, mode.leadTime)
+	applyFloat(paths, partFloat)
+	return paths, visitor.PathCount(result), partFloat, nil
 }
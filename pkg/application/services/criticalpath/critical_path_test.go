@@ -2,7 +2,12 @@ package criticalpath
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
 	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
@@ -23,6 +28,7 @@ func TestCriticalPathService_Basic(t *testing.T) {
 		"SIMPLE_ASSEMBLY",
 		"SN001",
 		"FACTORY",
+		time.Time{},
 		3,
 	)
 	if err != nil {
@@ -49,6 +55,395 @@ func TestCriticalPathService_Basic(t *testing.T) {
 	}
 }
 
+func TestCriticalPathService_PhantomHasZeroLeadTime(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo, itemRepo, inventoryRepo := buildSimpleTestData()
+
+	// COMPONENT_A (20 days) becomes a phantom kit, so COMPONENT_B (15 days) drives the schedule
+	componentA, err := itemRepo.GetItem("COMPONENT_A")
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	componentA.Phantom = true
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "SIMPLE_ASSEMBLY", "SN001", "FACTORY", time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	criticalPath := analysis.CriticalPath
+	if criticalPath.TotalLeadTime != 45 {
+		t.Errorf("Expected critical path of 45 days through COMPONENT_B, got %d", criticalPath.TotalLeadTime)
+	}
+	if len(criticalPath.Path) != 2 || criticalPath.Path[1] != "COMPONENT_B" {
+		t.Errorf("Expected critical path through COMPONENT_B, got %v", criticalPath.Path)
+	}
+
+	for _, path := range analysis.TopPaths {
+		for _, node := range path.PathDetails {
+			if node.PartNumber == "COMPONENT_A" && node.LeadTimeDays != 0 {
+				t.Errorf("Expected phantom node lead time 0, got %d", node.LeadTimeDays)
+			}
+		}
+	}
+}
+
+func TestCriticalPathService_LeadTimeOffsetOverlapsParent(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(2)
+	itemRepo := memory.NewItemRepository(3)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	items := []*entities.Item{
+		{PartNumber: "STAGE", Description: "Stage", LeadTimeDays: 30, UnitOfMeasure: "EA"},
+		{PartNumber: "NOZZLE", Description: "Nozzle", LeadTimeDays: 20, UnitOfMeasure: "EA"},
+		{PartNumber: "TANK", Description: "Tank", LeadTimeDays: 10, UnitOfMeasure: "EA"},
+	}
+	for _, item := range items {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	bomLines := []*entities.BOMLine{
+		{
+			ParentPN:           "STAGE",
+			ChildPN:            "NOZZLE",
+			QtyPer:             entities.Quantity(1),
+			FindNumber:         100,
+			Effectivity:        entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+			LeadTimeOffsetDays: 15, // Needed 15 days into the stage build
+		},
+		{
+			ParentPN:    "STAGE",
+			ChildPN:     "TANK",
+			QtyPer:      entities.Quantity(1),
+			FindNumber:  200,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "STAGE", "SN001", "FACTORY", time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	// NOZZLE path: 30 + (20 - 15) = 35 days; TANK path: 30 + 10 = 40 days
+	totals := make(map[entities.PartNumber]int)
+	for _, path := range analysis.TopPaths {
+		totals[path.Path[len(path.Path)-1]] = path.TotalLeadTime
+	}
+	if totals["NOZZLE"] != 35 {
+		t.Errorf("Expected NOZZLE path of 35 days with offset overlap, got %d", totals["NOZZLE"])
+	}
+	if totals["TANK"] != 40 {
+		t.Errorf("Expected TANK path of 40 days, got %d", totals["TANK"])
+	}
+	if analysis.CriticalPath.Path[1] != "TANK" {
+		t.Errorf("Expected TANK to be on the critical path, got %v", analysis.CriticalPath.Path)
+	}
+}
+
+func TestCriticalPathService_DateEffectiveAlternates(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(2)
+	itemRepo := memory.NewItemRepository(3)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	items := []*entities.Item{
+		{PartNumber: "ENGINE", Description: "Engine", LeadTimeDays: 30, UnitOfMeasure: "EA"},
+		{PartNumber: "TURBOPUMP_V1", Description: "Turbopump V1", LeadTimeDays: 90, UnitOfMeasure: "EA"},
+		{PartNumber: "TURBOPUMP_V2", Description: "Turbopump V2", LeadTimeDays: 60, UnitOfMeasure: "EA"},
+	}
+	for _, item := range items {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// V2 cuts in on a date, but V1 is the preferred alternate
+	cutIn := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	bomLines := []*entities.BOMLine{
+		{
+			ParentPN:        "ENGINE",
+			ChildPN:         "TURBOPUMP_V1",
+			QtyPer:          entities.Quantity(1),
+			FindNumber:      100,
+			Effectivity:     entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+			DateEffectivity: entities.DateEffectivity{ToDate: cutIn.AddDate(0, 0, -1)},
+			Priority:        1,
+		},
+		{
+			ParentPN:        "ENGINE",
+			ChildPN:         "TURBOPUMP_V2",
+			QtyPer:          entities.Quantity(1),
+			FindNumber:      100,
+			Effectivity:     entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+			DateEffectivity: entities.DateEffectivity{FromDate: cutIn},
+			Priority:        2,
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	for _, tc := range []struct {
+		needDate time.Time
+		expected entities.PartNumber
+	}{
+		{cutIn.AddDate(0, -6, 0), "TURBOPUMP_V1"},
+		{cutIn.AddDate(0, 6, 0), "TURBOPUMP_V2"},
+	} {
+		analysis, err := service.AnalyzeCriticalPath(ctx, "ENGINE", "SN001", "FACTORY", tc.needDate, 3)
+		if err != nil {
+			t.Fatalf("Critical path analysis failed: %v", err)
+		}
+		if path := analysis.CriticalPath.Path; len(path) != 2 || path[1] != tc.expected {
+			t.Errorf("Expected the critical path through %s when needed %s, got %v",
+				tc.expected, tc.needDate.Format("2006-01-02"), path)
+		}
+	}
+}
+
+func TestCriticalPathService_DateEffectiveSharedComponent(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(4)
+	itemRepo := memory.NewItemRepository(4)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	leadTimes := map[entities.PartNumber]int{"ENGINE": 100, "VALVE": 5, "SEAL_V1": 10, "SEAL_V2": 50}
+	for pn, leadTime := range leadTimes {
+		if err := itemRepo.SaveItem(&entities.Item{PartNumber: pn, LeadTimeDays: leadTime, UnitOfMeasure: "EA"}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// ENGINE needs one valve when it starts and one 90 days in, on either side of the date the
+	// V2 seal cuts in, so each valve is built with a different seal
+	needDate := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	cutIn := needDate.AddDate(0, 0, -60)
+	allSerials := entities.SerialEffectivity{FromSerial: "SN001"}
+	bomLines := []*entities.BOMLine{
+		{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 1, FindNumber: 100, Effectivity: allSerials},
+		{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 1, FindNumber: 200, Effectivity: allSerials, LeadTimeOffsetDays: 90},
+		{
+			ParentPN: "VALVE", ChildPN: "SEAL_V1", QtyPer: 1, FindNumber: 100, Effectivity: allSerials,
+			DateEffectivity: entities.DateEffectivity{ToDate: cutIn.AddDate(0, 0, -1)},
+		},
+		{
+			ParentPN: "VALVE", ChildPN: "SEAL_V2", QtyPer: 1, FindNumber: 100, Effectivity: allSerials,
+			DateEffectivity: entities.DateEffectivity{FromDate: cutIn},
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "ENGINE", "SN001", "FACTORY", needDate, 5)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	paths := make(map[string]bool)
+	for _, path := range analysis.TopPaths {
+		paths[fmt.Sprint(path.Path)] = true
+	}
+	for _, want := range []string{"[ENGINE VALVE SEAL_V1]", "[ENGINE VALVE SEAL_V2]"} {
+		if !paths[want] {
+			t.Errorf("Expected path %s among %v", want, paths)
+		}
+	}
+	if analysis.TotalPaths != 2 {
+		t.Errorf("Expected 2 paths, got %d", analysis.TotalPaths)
+	}
+}
+
+func TestCriticalPathService_PartFloat(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	leadTimes := map[entities.PartNumber]int{"TOP": 10, "A": 20, "B": 5, "C": 8, "D": 3}
+	for pn, leadTime := range leadTimes {
+		if err := itemRepo.SaveItem(&entities.Item{PartNumber: pn, LeadTimeDays: leadTime, UnitOfMeasure: "EA"}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// C is shared by A and B; B is needed 4 days into the TOP build
+	lines := []struct {
+		parent, child entities.PartNumber
+		offset        int
+	}{
+		{"TOP", "A", 0},
+		{"TOP", "B", 4},
+		{"A", "C", 0},
+		{"B", "C", 0},
+		{"B", "D", 0},
+	}
+	for i, line := range lines {
+		err := bomRepo.SaveBOMLine(&entities.BOMLine{
+			ParentPN:           line.parent,
+			ChildPN:            line.child,
+			QtyPer:             1,
+			FindNumber:         (i + 1) * 100,
+			Effectivity:        entities.SerialEffectivity{FromSerial: "SN001"},
+			LeadTimeOffsetDays: line.offset,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "TOP", "SN001", "FACTORY", time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	// C -> A -> TOP is critical at 8 + 20 + 10 = 38 days. B finishes on day 13 but is only
+	// needed 4 days into TOP's build on day 28, and D can finish as late as B's latest start.
+	expected := map[entities.PartNumber]entities.CriticalPathNode{
+		"TOP": {Level: 0, EarliestStart: 28, EarliestFinish: 38, LatestStart: 28, LatestFinish: 38},
+		"A":   {Level: 1, EarliestStart: 8, EarliestFinish: 28, LatestStart: 8, LatestFinish: 28},
+		"B": {Level: 1, EarliestStart: 8, EarliestFinish: 13, LatestStart: 27, LatestFinish: 32,
+			TotalFloat: 19, FreeFloat: 19},
+		"C": {Level: 2, EarliestStart: 0, EarliestFinish: 8, LatestStart: 0, LatestFinish: 8},
+		"D": {Level: 2, EarliestStart: 0, EarliestFinish: 3, LatestStart: 24, LatestFinish: 27,
+			TotalFloat: 24, FreeFloat: 5},
+	}
+	if len(analysis.PartFloat) != len(expected) {
+		t.Fatalf("Expected float for %d parts, got %d", len(expected), len(analysis.PartFloat))
+	}
+	for _, node := range analysis.PartFloat {
+		want := expected[node.PartNumber]
+		got := entities.CriticalPathNode{
+			Level:          node.Level,
+			EarliestStart:  node.EarliestStart,
+			EarliestFinish: node.EarliestFinish,
+			LatestStart:    node.LatestStart,
+			LatestFinish:   node.LatestFinish,
+			TotalFloat:     node.TotalFloat,
+			FreeFloat:      node.FreeFloat,
+		}
+		if got != want {
+			t.Errorf("%s: expected schedule %+v, got %+v", node.PartNumber, want, got)
+		}
+	}
+
+	if analysis.CriticalPath.TotalLeadTime != 38 {
+		t.Errorf("Expected critical path of 38 days, got %d", analysis.CriticalPath.TotalLeadTime)
+	}
+	for _, node := range analysis.CriticalPath.PathDetails {
+		if node.TotalFloat != 0 {
+			t.Errorf("Expected critical path part %s to have zero float, got %d", node.PartNumber, node.TotalFloat)
+		}
+	}
+
+	var zeroFloat []entities.PartNumber
+	for _, node := range analysis.ZeroFloatParts() {
+		zeroFloat = append(zeroFloat, node.PartNumber)
+	}
+	if fmt.Sprint(zeroFloat) != "[C A TOP]" {
+		t.Errorf("Expected zero-float parts [C A TOP], got %v", zeroFloat)
+	}
+	nearCritical := analysis.NearCriticalParts(20)
+	if len(nearCritical) != 1 || nearCritical[0].PartNumber != "B" {
+		t.Errorf("Expected B to be the only part with under 20 days of float, got %+v", nearCritical)
+	}
+}
+
+func TestCriticalPathService_ScheduleMode(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(3)
+	itemRepo := memory.NewItemRepository(4)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	leadTimes := map[entities.PartNumber]int{"STAGE": 30, "NOZZLE": 20, "TANK": 10, "VALVE": 50}
+	for pn, leadTime := range leadTimes {
+		if err := itemRepo.SaveItem(&entities.Item{PartNumber: pn, LeadTimeDays: leadTime, UnitOfMeasure: "EA"}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+	lines := []*entities.BOMLine{
+		{ParentPN: "STAGE", ChildPN: "NOZZLE", QtyPer: 1, FindNumber: 100, LeadTimeOffsetDays: 15},
+		{ParentPN: "STAGE", ChildPN: "TANK", QtyPer: 1, FindNumber: 200},
+		{ParentPN: "TANK", ChildPN: "VALVE", QtyPer: 1, FindNumber: 100},
+	}
+	for _, line := range lines {
+		line.Effectivity = entities.SerialEffectivity{FromSerial: "SN001"}
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	// NOZZLE is split into two orders back to back, and TANK has no order, as if inventory
+	// covered it, so its VALVE order does not hold up the stage
+	day := func(n int) time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, n) }
+	orders := []entities.PlannedOrder{
+		{PartNumber: "NOZZLE", Quantity: 1, StartDate: day(0), DueDate: day(20)},
+		{PartNumber: "NOZZLE", Quantity: 1, StartDate: day(20), DueDate: day(40)},
+		{PartNumber: "VALVE", Quantity: 1, StartDate: day(0), DueDate: day(50)},
+		{PartNumber: "STAGE", Quantity: 1, StartDate: day(25), DueDate: day(55)},
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+
+	// By lead time, VALVE -> TANK -> STAGE is critical at 90 days
+	byLeadTime, err := service.AnalyzeCriticalPath(ctx, "STAGE", "SN001", "FACTORY", time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+	if fmt.Sprint(byLeadTime.CriticalPath.Path) != "[STAGE TANK VALVE]" {
+		t.Errorf("Expected lead time critical path [STAGE TANK VALVE], got %v", byLeadTime.CriticalPath.Path)
+	}
+
+	analysis, err := service.AnalyzeCriticalPathFromSchedule(ctx, "STAGE", "SN001", "FACTORY", time.Time{}, 3, orders, nil)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+	if analysis.Mode != entities.ScheduleMode {
+		t.Errorf("Expected schedule mode, got %v", analysis.Mode)
+	}
+
+	// The stage starts 25 days in, when the second NOZZLE order is 15 days from done
+	criticalPath := analysis.CriticalPath
+	if fmt.Sprint(criticalPath.Path) != "[STAGE NOZZLE]" {
+		t.Fatalf("Expected schedule critical path [STAGE NOZZLE], got %v", criticalPath.Path)
+	}
+	if criticalPath.EffectiveLeadTime != 55 {
+		t.Errorf("Expected the path to span the 55 days of the schedule, got %d", criticalPath.EffectiveLeadTime)
+	}
+	nozzle := criticalPath.PathDetails[1]
+	if nozzle.EffectiveLeadTime != 40 || !nozzle.ScheduledStart.Equal(day(0)) || !nozzle.ScheduledDue.Equal(day(40)) {
+		t.Errorf("Expected NOZZLE to take its 40 scheduled days, got %d (%v to %v)",
+			nozzle.EffectiveLeadTime, nozzle.ScheduledStart, nozzle.ScheduledDue)
+	}
+	if analysis.TotalPaths != 2 {
+		t.Errorf("Expected TANK to end its path, giving 2 paths, got %d", analysis.TotalPaths)
+	}
+}
+
 // buildSimpleTestData creates minimal test data for unit tests
 func buildSimpleTestData() (*memory.BOMRepository, *memory.ItemRepository, *memory.InventoryRepository) {
 	bomRepo := memory.NewBOMRepository(2)
@@ -120,3 +515,391 @@ func buildSimpleTestData() (*memory.BOMRepository, *memory.ItemRepository, *memo
 
 	return bomRepo, itemRepo, inventoryRepo
 }
+
+func TestCriticalPathService_SharedComponentsDoNotExplode(t *testing.T) {
+	ctx := context.Background()
+
+	// 24 layers of 10 parts, each part using every part of the next layer: 10^24 paths
+	const layers, width = 24, 10
+	bomRepo := memory.NewBOMRepository(layers * width * width)
+	itemRepo := memory.NewItemRepository(layers*width + 1)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	partName := func(layer, i int) entities.PartNumber {
+		return entities.PartNumber(fmt.Sprintf("L%d_P%d", layer, i))
+	}
+	mustSave := func(err error) {
+		if err != nil {
+			t.Fatalf("Failed to build BOM: %v", err)
+		}
+	}
+
+	mustSave(itemRepo.SaveItem(&entities.Item{PartNumber: "TOP", LeadTimeDays: 1, UnitOfMeasure: "EA"}))
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			mustSave(itemRepo.SaveItem(&entities.Item{
+				PartNumber:    partName(layer, i),
+				LeadTimeDays:  1 + (layer*width+i)%7,
+				UnitOfMeasure: "EA",
+			}))
+			parent := entities.PartNumber("TOP")
+			if layer > 0 {
+				parent = partName(layer-1, 0)
+			}
+			// Every part of the previous layer uses this one
+			for p := 0; p < width; p++ {
+				if layer > 0 {
+					parent = partName(layer-1, p)
+				} else if p > 0 {
+					break
+				}
+				mustSave(bomRepo.SaveBOMLine(&entities.BOMLine{
+					ParentPN:    parent,
+					ChildPN:     partName(layer, i),
+					QtyPer:      1,
+					FindNumber:  100 + i,
+					Effectivity: entities.SerialEffectivity{FromSerial: "SN001"},
+				}))
+			}
+		}
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "TOP", "SN001", "FACTORY", time.Time{}, 5)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	if len(analysis.TopPaths) != 5 {
+		t.Fatalf("Expected 5 top paths, got %d", len(analysis.TopPaths))
+	}
+	if analysis.TotalPaths != math.MaxInt {
+		t.Errorf("Expected the path count to saturate, got %d", analysis.TotalPaths)
+	}
+	// Each layer has a part with lead time 7 (parts cycle through 1..7 across the layer)
+	if expected := 1 + layers*7; analysis.CriticalPath.TotalLeadTime != expected {
+		t.Errorf("Expected a critical path of %d days, got %d", expected, analysis.CriticalPath.TotalLeadTime)
+	}
+	if len(analysis.CriticalPath.Path) != layers+1 {
+		t.Errorf("Expected a path through all %d levels, got %v", layers+1, analysis.CriticalPath.Path)
+	}
+}
+
+func TestCriticalPathService_SharedComponentQuantities(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(4)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	leadTimes := map[entities.PartNumber]int{"TOP": 10, "A": 20, "B": 5, "S": 40}
+	for pn, leadTime := range leadTimes {
+		if err := itemRepo.SaveItem(&entities.Item{PartNumber: pn, LeadTimeDays: leadTime, UnitOfMeasure: "EA"}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// TOP uses A on two find numbers, so A is reached at 2 and at 1 units; S is shared by A and
+	// B at different quantities per
+	lines := []struct {
+		parent, child entities.PartNumber
+		qtyPer        entities.Quantity
+		findNumber    int
+	}{
+		{"TOP", "A", 2, 100},
+		{"TOP", "A", 1, 200},
+		{"TOP", "B", 1, 300},
+		{"A", "S", 3, 100},
+		{"B", "S", 2, 100},
+	}
+	for _, line := range lines {
+		if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+			ParentPN:    line.parent,
+			ChildPN:     line.child,
+			QtyPer:      line.qtyPer,
+			FindNumber:  line.findNumber,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"},
+		}); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	// 10 units of S on hand cover only part of the 3*3 + 1*2 = 11 required
+	inventoryRepo.AddLotInventory(entities.InventoryLot{
+		PartNumber: "S", LotNumber: "LOT1", Location: "FACTORY", Quantity: 10, Status: entities.Available,
+	})
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "TOP", "SN001", "FACTORY", time.Time{}, 5)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range analysis.TopPaths {
+		key := fmt.Sprint(path.Path)
+		if seen[key] {
+			t.Errorf("Path %s listed more than once in %d top paths", key, len(analysis.TopPaths))
+		}
+		seen[key] = true
+	}
+	if len(analysis.TopPaths) != 2 || analysis.TotalPaths != 2 {
+		t.Errorf("Expected 2 paths (TOP-A-S and TOP-B-S), got %d top paths of %d",
+			len(analysis.TopPaths), analysis.TotalPaths)
+	}
+
+	component := analysis.CriticalPath.PathDetails[len(analysis.CriticalPath.PathDetails)-1]
+	if component.PartNumber != "S" || component.RequiredQty != 11 {
+		t.Errorf("Expected S to require 11 units over every use, got %s requiring %d",
+			component.PartNumber, component.RequiredQty)
+	}
+	// 10 of 11 units on hand leave 1/11 of the 40 day lead time
+	if component.EffectiveLeadTime != 3 {
+		t.Errorf("Expected S to take 3 days for the uncovered unit, got %d", component.EffectiveLeadTime)
+	}
+}
+
+func TestCriticalPathService_TopPathsMatchEnumeration(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(11))
+
+	// A random DAG of 40 parts in which parts only use parts with higher numbers
+	const parts = 40
+	bomRepo := memory.NewBOMRepository(parts * 3)
+	itemRepo := memory.NewItemRepository(parts)
+	inventoryRepo := memory.NewInventoryRepository()
+	children := make(map[int][]int)
+	leadTimes := make(map[int]int)
+
+	for i := 0; i < parts; i++ {
+		leadTimes[i] = 1 + rng.Intn(20)
+		if err := itemRepo.SaveItem(&entities.Item{
+			PartNumber:    entities.PartNumber(fmt.Sprintf("P%02d", i)),
+			LeadTimeDays:  leadTimes[i],
+			UnitOfMeasure: "EA",
+		}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+	for i := 0; i < parts-1; i++ {
+		used := make(map[int]bool)
+		for n := 0; n < 1+rng.Intn(3); n++ {
+			child := i + 1 + rng.Intn(parts-i-1)
+			if used[child] {
+				continue
+			}
+			used[child] = true
+			children[i] = append(children[i], child)
+			if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+				ParentPN:    entities.PartNumber(fmt.Sprintf("P%02d", i)),
+				ChildPN:     entities.PartNumber(fmt.Sprintf("P%02d", child)),
+				QtyPer:      1,
+				FindNumber:  10 * (n + 1),
+				Effectivity: entities.SerialEffectivity{FromSerial: "SN001"},
+			}); err != nil {
+				t.Fatalf("Failed to save BOM line: %v", err)
+			}
+		}
+	}
+
+	// Enumerate every path's total lead time
+	var totals []int
+	var walk func(part, sum int)
+	walk = func(part, sum int) {
+		sum += leadTimes[part]
+		if len(children[part]) == 0 {
+			totals = append(totals, sum)
+			return
+		}
+		for _, child := range children[part] {
+			walk(child, sum)
+		}
+	}
+	walk(0, 0)
+	sort.Sort(sort.Reverse(sort.IntSlice(totals)))
+
+	const topN = 10
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	analysis, err := service.AnalyzeCriticalPath(ctx, "P00", "SN001", "FACTORY", time.Time{}, topN)
+	if err != nil {
+		t.Fatalf("Critical path analysis failed: %v", err)
+	}
+
+	if analysis.TotalPaths != len(totals) {
+		t.Errorf("Expected %d paths in all, got %d", len(totals), analysis.TotalPaths)
+	}
+	if len(analysis.TopPaths) != topN {
+		t.Fatalf("Expected %d top paths, got %d", topN, len(analysis.TopPaths))
+	}
+	for i, path := range analysis.TopPaths {
+		if path.TotalLeadTime != totals[i] {
+			t.Errorf("Path %d: expected %d days, got %d (%v)", i, totals[i], path.TotalLeadTime, path.Path)
+		}
+		sum := 0
+		for level, node := range path.PathDetails {
+			sum += node.LeadTimeDays
+			if node.Level != level {
+				t.Errorf("Path %d: expected %s at level %d, got %d", i, node.PartNumber, level, node.Level)
+			}
+		}
+		if sum != path.TotalLeadTime {
+			t.Errorf("Path %d: parts add up to %d days, path reports %d", i, sum, path.TotalLeadTime)
+		}
+	}
+}
+
+func TestAnalyzeProgram(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(4)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	leadTimes := map[entities.PartNumber]int{"TOP1": 10, "TOP2": 5, "CORE": 20, "PIN": 3, "BRACKET": 15}
+	for pn, leadTime := range leadTimes {
+		if err := itemRepo.SaveItem(&entities.Item{PartNumber: pn, LeadTimeDays: leadTime, UnitOfMeasure: "EA"}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// CORE is shared, and critical in both builds: TOP1 takes 30 days and TOP2 25
+	lines := []*entities.BOMLine{
+		{ParentPN: "TOP1", ChildPN: "CORE", QtyPer: 1, FindNumber: 100},
+		{ParentPN: "TOP1", ChildPN: "PIN", QtyPer: 1, FindNumber: 200},
+		{ParentPN: "TOP2", ChildPN: "CORE", QtyPer: 1, FindNumber: 100},
+		{ParentPN: "TOP2", ChildPN: "BRACKET", QtyPer: 1, FindNumber: 200},
+	}
+	for _, line := range lines {
+		line.Effectivity = entities.SerialEffectivity{FromSerial: "SN001"}
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	demands := []*entities.DemandRequirement{
+		{PartNumber: "TOP1", Quantity: 1, NeedDate: asOf.AddDate(0, 0, 25), DemandSource: "M1", Location: "FACTORY", TargetSerial: "SN001"},
+		{PartNumber: "TOP2", Quantity: 1, NeedDate: asOf.AddDate(0, 0, 30), DemandSource: "M2", Location: "FACTORY", TargetSerial: "SN001"},
+	}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	var demandPaths []entities.DemandCriticalPath
+	for _, demand := range demands {
+		analysis, err := service.AnalyzeCriticalPath(ctx, demand.PartNumber, demand.TargetSerial, demand.Location, demand.NeedDate, 3)
+		if err != nil {
+			t.Fatalf("Critical path analysis failed: %v", err)
+		}
+		demandPaths = append(demandPaths, NewDemandCriticalPath(demand, analysis, asOf))
+	}
+
+	allocations := []entities.AllocationResult{{PartNumber: "CORE", Location: "FACTORY", AllocatedQty: 1, RemainingDemand: 1}}
+	orders := []entities.PlannedOrder{{PartNumber: "CORE", Quantity: 1}, {PartNumber: "PIN", Quantity: 1}}
+	program := AnalyzeProgram(asOf, demandPaths, orders, allocations, nil)
+
+	if program.Demands[0].DaysLate != 5 || program.Demands[1].DaysLate != -5 {
+		t.Errorf("Expected TOP1 5 days late and TOP2 5 days early, got %d and %d",
+			program.Demands[0].DaysLate, program.Demands[1].DaysLate)
+	}
+	if !program.Demands[0].ProjectedCompletion.Equal(asOf.AddDate(0, 0, 30)) {
+		t.Errorf("Expected TOP1 to complete 30 days in, got %v", program.Demands[0].ProjectedCompletion)
+	}
+	if late := program.LateDemands(); len(late) != 1 || late[0].Demand.PartNumber != "TOP1" {
+		t.Errorf("Expected only TOP1 to be late, got %+v", late)
+	}
+	if most := program.MostCritical(); most.Demand.PartNumber != "TOP1" {
+		t.Errorf("Expected TOP1 to be the most critical demand, got %s", most.Demand.PartNumber)
+	}
+
+	// CORE gates both demands and accounts for all 5 days TOP1 is late; TOP1 accounts for them
+	// too, while TOP2 is on time
+	var ranking []string
+	for _, part := range program.Bottlenecks {
+		ranking = append(ranking, fmt.Sprintf("%s:%d:%d", part.PartNumber, len(part.Demands), part.LatenessDays))
+	}
+	if fmt.Sprint(ranking) != "[CORE:2:5 TOP1:1:5 TOP2:1:0]" {
+		t.Errorf("Expected bottlenecks [CORE:2:5 TOP1:1:5 TOP2:1:0], got %v", ranking)
+	}
+
+	if len(program.SharedComponents) != 1 {
+		t.Fatalf("Expected CORE to be the only shared component, got %+v", program.SharedComponents)
+	}
+	core := program.SharedComponents[0]
+	if core.PartNumber != "CORE" || len(core.Demands) != 2 || core.CriticalFor != 2 {
+		t.Errorf("Expected CORE shared by and critical for 2 demands, got %+v", core)
+	}
+	if core.AllocatedQty != 1 || core.PlannedQty != 1 || core.ShortQty != 0 {
+		t.Errorf("Expected CORE 1 allocated, 1 planned, 0 short, got %d, %d, %d",
+			core.AllocatedQty, core.PlannedQty, core.ShortQty)
+	}
+}
+
+func TestCriticalPathService_AnalyzeScheduleRisk(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo, itemRepo, inventoryRepo := buildSimpleTestData()
+
+	// COMPONENT_A takes 20 days; COMPONENT_B is likely 15 but takes up to 45, so it is on the
+	// critical path about half the time: P(B > 20) = 25² / (40 × 30)
+	componentB, err := itemRepo.GetItem("COMPONENT_B")
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	componentB.LeadTimeRange, err = entities.NewLeadTimeDistribution(entities.Triangular, 5, 15, 45)
+	if err != nil {
+		t.Fatalf("Failed to create distribution: %v", err)
+	}
+
+	asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	demands := []*entities.DemandRequirement{{
+		PartNumber:   "SIMPLE_ASSEMBLY",
+		Quantity:     1,
+		NeedDate:     asOf.AddDate(0, 0, 55),
+		DemandSource: "TEST",
+		Location:     "FACTORY",
+		TargetSerial: "SN001",
+	}}
+
+	service := NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	risk, err := service.AnalyzeScheduleRisk(ctx, demands, nil, 5000, 42, asOf)
+	if err != nil {
+		t.Fatalf("Schedule risk analysis failed: %v", err)
+	}
+
+	demandRisk := risk.Demands[0]
+	if !demandRisk.PlannedCompletion.Equal(asOf.AddDate(0, 0, 50)) {
+		t.Errorf("Expected planned completion 50 days in, got %v", demandRisk.PlannedCompletion)
+	}
+	if demandRisk.P50.Before(demandRisk.PlannedCompletion) || demandRisk.P80.Before(demandRisk.P50) ||
+		demandRisk.P95.Before(demandRisk.P80) {
+		t.Errorf("Expected planned <= P50 <= P80 <= P95, got %v, %v, %v, %v",
+			demandRisk.PlannedCompletion, demandRisk.P50, demandRisk.P80, demandRisk.P95)
+	}
+	if demandRisk.P95.After(asOf.AddDate(0, 0, 75)) {
+		t.Errorf("Expected P95 no later than the 75 day worst case, got %v", demandRisk.P95)
+	}
+	if demandRisk.OnTimeProbability <= 0 || demandRisk.OnTimeProbability >= 1 {
+		t.Errorf("Expected an on-time probability between 0 and 1, got %f", demandRisk.OnTimeProbability)
+	}
+
+	criticality := make(map[entities.PartNumber]entities.PartCriticality)
+	for _, part := range risk.Parts {
+		criticality[part.PartNumber] = part
+	}
+	if index := criticality["SIMPLE_ASSEMBLY"].CriticalityIndex; index != 1 {
+		t.Errorf("Expected the assembly to always be critical, got %f", index)
+	}
+	if part := criticality["COMPONENT_B"]; math.Abs(part.CriticalityIndex-0.52) > 0.05 || !part.HasLeadTimeRange {
+		t.Errorf("Expected COMPONENT_B to be critical about 52%% of the time, got %+v", part)
+	}
+	if risk.Parts[0].PartNumber != "SIMPLE_ASSEMBLY" {
+		t.Errorf("Expected the most critical part first, got %s", risk.Parts[0].PartNumber)
+	}
+
+	again, err := service.AnalyzeScheduleRisk(ctx, demands, nil, 5000, 42, asOf)
+	if err != nil {
+		t.Fatalf("Schedule risk analysis failed: %v", err)
+	}
+	if fmt.Sprint(again.Demands[0]) != fmt.Sprint(demandRisk) || fmt.Sprint(again.Parts) != fmt.Sprint(risk.Parts) {
+		t.Error("Expected the same seed to give the same results")
+	}
+}
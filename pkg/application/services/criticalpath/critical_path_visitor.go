@@ -2,6 +2,9 @@ package criticalpath
 
 import (
 	"context"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/vsinha/mrp/pkg/application/services/shared"
 	"github.com/vsinha/mrp/pkg/domain/entities"
@@ -9,147 +12,441 @@ import (
 	"github.com/vsinha/mrp/pkg/domain/services"
 )
 
-// CriticalPathVisitor implements BOMNodeVisitor for critical path analysis
+// PathRanking orders two paths, returning true when a is more critical than b
+type PathRanking func(a, b *entities.CriticalPath) bool
+
+// RankByEffectiveLeadTime ranks paths by effective lead time, then total lead time, then length
+func RankByEffectiveLeadTime(a, b *entities.CriticalPath) bool {
+	if a.EffectiveLeadTime != b.EffectiveLeadTime {
+		return a.EffectiveLeadTime > b.EffectiveLeadTime
+	}
+	if a.TotalLeadTime != b.TotalLeadTime {
+		return a.TotalLeadTime > b.TotalLeadTime
+	}
+	return a.PathLength > b.PathLength
+}
+
+// RankByTotalLeadTime ranks paths by total lead time, ignoring inventory, then effective lead
+// time and length
+func RankByTotalLeadTime(a, b *entities.CriticalPath) bool {
+	if a.TotalLeadTime != b.TotalLeadTime {
+		return a.TotalLeadTime > b.TotalLeadTime
+	}
+	if a.EffectiveLeadTime != b.EffectiveLeadTime {
+		return a.EffectiveLeadTime > b.EffectiveLeadTime
+	}
+	return a.PathLength > b.PathLength
+}
+
+// CriticalPathVisitor implements BOMNodeVisitor for critical path analysis. It builds the BOM
+// as a DAG with one vertex per part, so a shared component is explored once however many
+// assemblies use it, and keeps only the topN longest paths down from each vertex. Effective
+// lead times depend on the total quantity of a part, which is only known once the whole DAG
+// is built, so paths are ranked when the traversal returns to the root.
 type CriticalPathVisitor struct {
 	inventoryRepo repositories.InventoryRepository
 	serialComp    *services.SerialComparator
+	topN          int
+	rank          PathRanking
+
+	// schedule times parts by their planned orders instead of lead times; nil in lead time mode
+	schedule map[entities.PartNumber]*orderSpan
+
+	vertices map[pathVertexKey]*pathVertex
+	order    []*pathVertex // Vertices in the order they were built, children before parents
+
+	// datedParts are the parts with date effective lines below them, whose explosion depends
+	// on when they are needed
+	datedParts map[entities.PartNumber]bool
+}
+
+// orderSpan is the time from the start of a part's first planned order to the due date of its
+// last; split orders of a part run back to back
+type orderSpan struct {
+	start time.Time
+	due   time.Time
+}
+
+// days returns the length of the span in whole days
+func (s *orderSpan) days() int {
+	return int(math.Round(s.due.Sub(s.start).Hours() / 24))
 }
 
 // CriticalPathNodeData holds data for a critical path node during traversal
 type CriticalPathNodeData struct {
-	Node              entities.CriticalPathNode
-	EffectiveLeadTime int
+	Node       entities.CriticalPathNode
+	Location   string
+	Allocation *shared.AllocationContext
+	Scheduled  bool        // EffectiveLeadTime comes from the schedule, whatever the quantity
+	vertex     *pathVertex // Set when the part was already explored
+}
+
+// pathVertexKey identifies a DAG vertex. The required quantity is not part of it: a part
+// reached at different quantities is still one part, and its quantities add up. A part with
+// date effectivity below it is keyed by need date too, since it may be built from other
+// alternates on another date; needDate is zero for every other part.
+type pathVertexKey struct {
+	partNumber   entities.PartNumber
+	targetSerial string
+	needDate     time.Time
+}
+
+// pathVertex is a part in the BOM DAG with the most critical paths from it down to a leaf
+type pathVertex struct {
+	node      entities.CriticalPathNode // RequiredQty totals every use; Level and LeadTimeOffsetDays are set per path
+	data      *CriticalPathNodeData
+	children  []*pathEdge
+	suffixes  []pathSuffix // At most topN, most critical first
+	pathCount int          // Paths from this vertex to a leaf, capped at math.MaxInt
+}
+
+// pathEdge links a parent to a child vertex through the BOM lines of the child
+type pathEdge struct {
+	child    *pathVertex
+	offset   int               // LeadTimeOffsetDays of the BOM line, the earliest when the parent uses the child twice
+	quantity entities.Quantity // Units of the child per unit of the parent, or the required quantity at the root
 }
 
-// NewCriticalPathVisitor creates a new critical path visitor
+// pathSuffix is one path from a vertex down to a leaf. It is stored as a link to the child's
+// suffix it continues with, so paths share their tails instead of copying them.
+type pathSuffix struct {
+	totalLeadTime      int
+	effectiveLeadTime  int
+	length             int
+	bottleneck         entities.PartNumber
+	bottleneckLeadTime int
+	next               *pathEdge // nil at a leaf
+	nextRank           int       // Index into next.child.suffixes
+}
+
+// NewCriticalPathVisitor creates a new critical path visitor keeping the topN most critical
+// paths by rank
 func NewCriticalPathVisitor(
 	inventoryRepo repositories.InventoryRepository,
 	serialComp *services.SerialComparator,
+	topN int,
+	rank PathRanking,
 ) *CriticalPathVisitor {
+	if topN < 1 {
+		topN = 1
+	}
 	return &CriticalPathVisitor{
 		inventoryRepo: inventoryRepo,
 		serialComp:    serialComp,
+		topN:          topN,
+		rank:          rank,
+		vertices:      make(map[pathVertexKey]*pathVertex),
 	}
 }
 
-// VisitNode creates a critical path node for this part
+// SetSchedule times parts by the planned orders of an MRP run, as in the Gantt chart: a part
+// takes as long as its orders, and a part without orders is available at once, so nothing
+// below it holds up its parents
+func (v *CriticalPathVisitor) SetSchedule(orders []entities.PlannedOrder) {
+	v.schedule = make(map[entities.PartNumber]*orderSpan)
+	for _, order := range orders {
+		span, exists := v.schedule[order.PartNumber]
+		if !exists {
+			v.schedule[order.PartNumber] = &orderSpan{start: order.StartDate, due: order.DueDate}
+			continue
+		}
+		if order.StartDate.Before(span.start) {
+			span.start = order.StartDate
+		}
+		if order.DueDate.After(span.due) {
+			span.due = order.DueDate
+		}
+	}
+}
+
+// SetDatedParts keys the vertices of parts with date effective lines below them by need date,
+// as MRP keys their explosions, so each date gets the alternates in effect on it
+func (v *CriticalPathVisitor) SetDatedParts(bomLines []*entities.BOMLine) {
+	parents := make(map[entities.PartNumber][]entities.PartNumber)
+	var pending []entities.PartNumber
+	for _, line := range bomLines {
+		parents[line.ChildPN] = append(parents[line.ChildPN], line.ParentPN)
+		if !line.DateEffectivity.FromDate.IsZero() || !line.DateEffectivity.ToDate.IsZero() {
+			pending = append(pending, line.ParentPN)
+		}
+	}
+
+	v.datedParts = make(map[entities.PartNumber]bool)
+	for len(pending) > 0 {
+		part := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if v.datedParts[part] {
+			continue
+		}
+		v.datedParts[part] = true
+		pending = append(pending, parents[part]...)
+	}
+}
+
+// vertexKey returns the key of the vertex for a node
+func (v *CriticalPathVisitor) vertexKey(nodeCtx shared.BOMNodeContext) pathVertexKey {
+	key := pathVertexKey{partNumber: nodeCtx.PartNumber, targetSerial: nodeCtx.TargetSerial}
+	if v.datedParts[nodeCtx.PartNumber] {
+		key.needDate = nodeCtx.NeedDate
+	}
+	return key
+}
+
+// VisitNode creates a critical path node for this part, and stops at parts already explored
 func (v *CriticalPathVisitor) VisitNode(
 	ctx context.Context,
 	nodeCtx shared.BOMNodeContext,
 ) (interface{}, bool, error) {
-	var hasInventory bool
-	var inventoryQty entities.Quantity
-	var effectiveLeadTime int
+	if vertex, exists := v.vertices[v.vertexKey(nodeCtx)]; exists {
+		return &CriticalPathNodeData{vertex: vertex}, false, nil
+	}
 
+	// Phantoms are blown through and contribute no lead time of their own
+	node := entities.CriticalPathNode{
+		PartNumber:   nodeCtx.PartNumber,
+		Description:  nodeCtx.Item.Description,
+		LeadTimeDays: nodeCtx.Item.PlanningLeadTimeDays(),
+	}
+	nodeData := &CriticalPathNodeData{
+		Node:       node,
+		Location:   nodeCtx.Location,
+		Allocation: nodeCtx.AllocationContext,
+		Scheduled:  v.schedule != nil,
+	}
+
+	if v.schedule != nil {
+		if span, hasOrders := v.schedule[nodeCtx.PartNumber]; hasOrders {
+			nodeData.Node.ScheduledStart, nodeData.Node.ScheduledDue = span.start, span.due
+			nodeData.Node.EffectiveLeadTime = span.days()
+		} else if !nodeCtx.Item.Phantom {
+			// Inventory covers the part, so MRP does not wait on anything below it
+			return nodeData, false, nil
+		}
+	}
+
+	return nodeData, true, nil
+}
+
+// ProcessChildren turns this part into a DAG vertex linked to its children, and returns the
+// edge from its parent to it. Back at the root, it ranks the paths of every vertex.
+func (v *CriticalPathVisitor) ProcessChildren(
+	ctx context.Context,
+	nodeCtx shared.BOMNodeContext,
+	nodeData interface{},
+	childResults []interface{},
+) (interface{}, error) {
+	criticalPathNodeData := nodeData.(*CriticalPathNodeData)
+
+	vertex := criticalPathNodeData.vertex
+	if vertex == nil {
+		vertex = newVertex(criticalPathNodeData, childResults)
+		v.vertices[v.vertexKey(nodeCtx)] = vertex
+		v.order = append(v.order, vertex)
+	}
+
+	edge := &pathEdge{child: vertex, quantity: nodeCtx.Quantity}
+	if nodeCtx.BOMLine != nil {
+		edge.offset = nodeCtx.BOMLine.LeadTimeOffsetDays
+		edge.quantity = nodeCtx.BOMLine.QtyPer
+		return edge, nil
+	}
+
+	v.rankPaths(ctx, edge)
+	return edge, nil
+}
+
+// newVertex creates the vertex of a part from the edges to its children. A child used on
+// several BOM lines of the part gets one edge, so its paths are not listed twice.
+func newVertex(nodeData *CriticalPathNodeData, childResults []interface{}) *pathVertex {
+	vertex := &pathVertex{node: nodeData.Node, data: nodeData}
+
+	edges := make(map[*pathVertex]*pathEdge)
+	for _, childResult := range childResults {
+		edge := childResult.(*pathEdge)
+		if existing, exists := edges[edge.child]; exists {
+			existing.quantity += edge.quantity
+			if edge.offset < existing.offset {
+				existing.offset = edge.offset
+			}
+			continue
+		}
+		edges[edge.child] = edge
+		vertex.children = append(vertex.children, edge)
+	}
+	return vertex
+}
+
+// rankPaths totals the quantity of every part below the root edge, then finds the most
+// critical paths down from each vertex, children first
+func (v *CriticalPathVisitor) rankPaths(ctx context.Context, root *pathEdge) {
+	// Parents come after their children, so walking backwards reaches every use of a part
+	// before the part itself
+	for _, vertex := range v.order {
+		vertex.node.RequiredQty = 0
+	}
+	root.child.node.RequiredQty = root.quantity
+	for i := len(v.order) - 1; i >= 0; i-- {
+		vertex := v.order[i]
+		for _, edge := range vertex.children {
+			edge.child.node.RequiredQty += vertex.node.RequiredQty * edge.quantity
+		}
+	}
+
+	for _, vertex := range v.order {
+		v.setEffectiveLeadTime(ctx, vertex)
+		v.rankSuffixes(vertex)
+	}
+}
+
+// setEffectiveLeadTime sets the inventory and effective lead time of a vertex for the total
+// quantity required of it
+func (v *CriticalPathVisitor) setEffectiveLeadTime(ctx context.Context, vertex *pathVertex) {
+	node, data := &vertex.node, vertex.data
+
+	var effectiveLeadTime int
 	// Use allocation context if available, otherwise fall back to inventory check
-	if nodeCtx.AllocationContext != nil {
+	if alloc := data.Allocation; alloc != nil {
 		// Use allocation results to determine inventory coverage
-		alloc := nodeCtx.AllocationContext
-		hasInventory = alloc.HasAllocation
-		inventoryQty = alloc.AllocatedQty
+		node.HasInventory = alloc.HasAllocation
+		node.InventoryQty = alloc.AllocatedQty
 
 		// Calculate effective lead time based on allocation coverage
-		if alloc.AllocatedQty >= nodeCtx.Quantity {
+		if alloc.AllocatedQty >= node.RequiredQty {
 			// Full allocation coverage - zero lead time
 			effectiveLeadTime = 0
 		} else if alloc.AllocatedQty > 0 {
 			// Partial allocation coverage - reduced lead time
-			coverageRatio := float64(alloc.AllocatedQty) / float64(nodeCtx.Quantity)
-			effectiveLeadTime = int(float64(nodeCtx.Item.LeadTimeDays) * (1.0 - coverageRatio))
+			coverageRatio := float64(alloc.AllocatedQty) / float64(node.RequiredQty)
+			effectiveLeadTime = int(float64(node.LeadTimeDays) * (1.0 - coverageRatio))
 		} else {
 			// No allocation - full lead time
-			effectiveLeadTime = nodeCtx.Item.LeadTimeDays
+			effectiveLeadTime = node.LeadTimeDays
 		}
 	} else {
 		// Fallback to inventory check if no allocation context
-		hasInventory, inventoryQty, effectiveLeadTime = v.checkInventoryAvailability(
-			ctx, nodeCtx.PartNumber, nodeCtx.Location, nodeCtx.Quantity, nodeCtx.Item.LeadTimeDays)
+		node.HasInventory, node.InventoryQty, effectiveLeadTime = v.checkInventoryAvailability(
+			ctx, node.PartNumber, data.Location, node.RequiredQty, node.LeadTimeDays)
 	}
 
-	// Create node for this part
-	node := entities.CriticalPathNode{
-		PartNumber:        nodeCtx.PartNumber,
-		Description:       nodeCtx.Item.Description,
-		LeadTimeDays:      nodeCtx.Item.LeadTimeDays,
-		Level:             nodeCtx.Level,
-		HasInventory:      hasInventory,
-		InventoryQty:      inventoryQty,
-		RequiredQty:       nodeCtx.Quantity,
-		EffectiveLeadTime: effectiveLeadTime,
+	// A schedule times the part by its orders, already set on the node
+	if !data.Scheduled {
+		node.EffectiveLeadTime = effectiveLeadTime
 	}
+}
 
-	nodeData := &CriticalPathNodeData{
-		Node:              node,
-		EffectiveLeadTime: effectiveLeadTime,
+// rankSuffixes keeps the topN most critical paths down from a vertex, through the most
+// critical paths of its children. With lead time offsets, two child paths can tie once they
+// overlap the parent's build and then rank by what follows, so paths cut from a child's topN
+// may be missed beyond the most critical one.
+func (v *CriticalPathVisitor) rankSuffixes(vertex *pathVertex) {
+	node := vertex.node
+	effectiveLeadTime := node.EffectiveLeadTime
+
+	// A leaf is a single path
+	if len(vertex.children) == 0 {
+		vertex.pathCount = 1
+		vertex.suffixes = []pathSuffix{{
+			totalLeadTime:      node.LeadTimeDays,
+			effectiveLeadTime:  effectiveLeadTime,
+			length:             1,
+			bottleneck:         node.PartNumber,
+			bottleneckLeadTime: node.LeadTimeDays,
+		}}
+		return
 	}
 
-	// Always continue traversal for critical path analysis
-	return nodeData, true, nil
-}
+	vertex.pathCount = 0
+	var candidates []pathSuffix
+	for _, edge := range vertex.children {
+		vertex.pathCount = addCapped(vertex.pathCount, edge.child.pathCount)
 
-// ProcessChildren creates critical paths by combining this node with child paths
-func (v *CriticalPathVisitor) ProcessChildren(
-	ctx context.Context,
-	nodeCtx shared.BOMNodeContext,
-	nodeData interface{},
-	childResults []interface{},
-) (interface{}, error) {
-	criticalPathNodeData := nodeData.(*CriticalPathNodeData)
-	node := criticalPathNodeData.Node
-	effectiveLeadTime := criticalPathNodeData.EffectiveLeadTime
-
-	// If no children (leaf node), return single path
-	if len(childResults) == 0 {
-		path := entities.CriticalPath{
-			TotalLeadTime:     nodeCtx.Item.LeadTimeDays,
-			EffectiveLeadTime: effectiveLeadTime,
-			PathLength:        1,
-			Path:              []entities.PartNumber{nodeCtx.PartNumber},
-			PathDetails:       []entities.CriticalPathNode{node},
-			BottleneckPart:    nodeCtx.PartNumber,
+		for rank, childSuffix := range edge.child.suffixes {
+			// The child is only needed offset days into this part's build, so that much of the
+			// child path overlaps with this part's own lead time
+			suffix := pathSuffix{
+				totalLeadTime: node.LeadTimeDays +
+					overlappedDays(childSuffix.totalLeadTime, edge.offset, node.LeadTimeDays),
+				effectiveLeadTime: effectiveLeadTime +
+					overlappedDays(childSuffix.effectiveLeadTime, edge.offset, effectiveLeadTime),
+				length:   1 + childSuffix.length,
+				next:     edge,
+				nextRank: rank,
+			}
+
+			// The bottleneck is the part with the longest individual lead time in the path
+			suffix.bottleneck, suffix.bottleneckLeadTime = node.PartNumber, node.LeadTimeDays
+			if node.LeadTimeDays < childSuffix.bottleneckLeadTime {
+				suffix.bottleneck, suffix.bottleneckLeadTime =
+					childSuffix.bottleneck, childSuffix.bottleneckLeadTime
+			}
+
+			candidates = append(candidates, suffix)
 		}
-		return []entities.CriticalPath{path}, nil
 	}
 
-	// Collect all child paths
-	var allChildPaths []entities.CriticalPath
-	for _, childResult := range childResults {
-		if childResult != nil {
-			childPaths := childResult.([]entities.CriticalPath)
-			allChildPaths = append(allChildPaths, childPaths...)
-		}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].summary(), candidates[j].summary()
+		return v.rank(&a, &b)
+	})
+	if len(candidates) > v.topN {
+		candidates = candidates[:v.topN]
 	}
+	vertex.suffixes = candidates
+}
 
-	// Build paths from this node through each child path
-	var resultPaths []entities.CriticalPath
+// Paths returns the most critical paths found by a traversal with this visitor, given the
+// traversal's result and the level it started at
+func (v *CriticalPathVisitor) Paths(result interface{}, level int) []entities.CriticalPath {
+	return result.(*pathEdge).paths(level)
+}
 
-	for _, childPath := range allChildPaths {
-		// Calculate cumulative times
-		node.CumulativeTime = effectiveLeadTime + childPath.EffectiveLeadTime
+// PathCount returns how many root to leaf paths a traversal with this visitor went through,
+// capped at math.MaxInt
+func (v *CriticalPathVisitor) PathCount(result interface{}) int {
+	return result.(*pathEdge).child.pathCount
+}
 
-		// Determine bottleneck (part with longest individual lead time in path)
-		bottleneck := node.PartNumber
-		if nodeCtx.Item.LeadTimeDays < v.getLeadTimeForPart(
-			childPath.BottleneckPart,
-			childPath.PathDetails,
-		) {
-			bottleneck = childPath.BottleneckPart
-		}
+// summary returns the path totals of a suffix, without its parts
+func (s *pathSuffix) summary() entities.CriticalPath {
+	return entities.CriticalPath{
+		TotalLeadTime:     s.totalLeadTime,
+		EffectiveLeadTime: s.effectiveLeadTime,
+		PathLength:        s.length,
+		BottleneckPart:    s.bottleneck,
+	}
+}
 
-		// Create new path by prepending this node
-		newPath := entities.CriticalPath{
-			TotalLeadTime:     nodeCtx.Item.LeadTimeDays + childPath.TotalLeadTime,
-			EffectiveLeadTime: effectiveLeadTime + childPath.EffectiveLeadTime,
-			PathLength:        1 + childPath.PathLength,
-			Path:              append([]entities.PartNumber{nodeCtx.PartNumber}, childPath.Path...),
-			PathDetails:       append([]entities.CriticalPathNode{node}, childPath.PathDetails...),
-			BottleneckPart:    bottleneck,
-		}
+// paths materializes the most critical paths from the root vertex, numbering levels from level
+func (e *pathEdge) paths(level int) []entities.CriticalPath {
+	root := e.child
+	paths := make([]entities.CriticalPath, len(root.suffixes))
+	for i := range root.suffixes {
+		path := root.suffixes[i].summary()
+		path.Path = make([]entities.PartNumber, 0, path.PathLength)
+		path.PathDetails = make([]entities.CriticalPathNode, 0, path.PathLength)
 
-		resultPaths = append(resultPaths, newPath)
+		edge, rank := e, i
+		for depth := 0; edge != nil; depth++ {
+			suffix := &edge.child.suffixes[rank]
+			node := edge.child.node
+			node.Level = level + depth
+			node.LeadTimeOffsetDays = edge.offset
+			node.CumulativeTime = suffix.effectiveLeadTime
+			path.Path = append(path.Path, node.PartNumber)
+			path.PathDetails = append(path.PathDetails, node)
+			edge, rank = suffix.next, suffix.nextRank
+		}
+		paths[i] = path
 	}
+	return paths
+}
 
-	return resultPaths, nil
+// addCapped adds two path counts, saturating instead of overflowing
+func addCapped(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
 }
 
 // checkInventoryAvailability checks if inventory is available and calculates effective lead time
@@ -208,15 +505,14 @@ func (v *CriticalPathVisitor) checkInventoryAvailability(
 	}
 }
 
-// getLeadTimeForPart finds the lead time for a specific part in the path details
-func (v *CriticalPathVisitor) getLeadTimeForPart(
-	partNumber entities.PartNumber,
-	pathDetails []entities.CriticalPathNode,
-) int {
-	for _, node := range pathDetails {
-		if node.PartNumber == partNumber {
-			return node.LeadTimeDays
-		}
+// overlappedDays returns how much of a child path extends before its parent can start,
+// given that the child is needed offset days into a parent build of parentLeadTime days
+func overlappedDays(childLeadTime, offset, parentLeadTime int) int {
+	if offset > parentLeadTime {
+		offset = parentLeadTime
+	}
+	if childLeadTime <= offset {
+		return 0
 	}
-	return 0
+	return childLeadTime - offset
 }
@@ -0,0 +1,185 @@
+package criticalpath
+
+import (
+	"sort"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// leadTimeBasis picks which lead time of a part the float schedule uses
+type leadTimeBasis func(node *entities.CriticalPathNode) int
+
+// planningLeadTime schedules parts with their full lead time, ignoring inventory
+func planningLeadTime(node *entities.CriticalPathNode) int {
+	return node.LeadTimeDays
+}
+
+// effectiveLeadTime schedules parts with their lead time after inventory coverage
+func effectiveLeadTime(node *entities.CriticalPathNode) int {
+	return node.EffectiveLeadTime
+}
+
+// partSchedule is one part in the float schedule. A part is a single activity however many
+// assemblies use it, as MRP plans one order stream per part, so it takes the longest lead time
+// of its occurrences.
+type partSchedule struct {
+	node     entities.CriticalPathNode
+	duration int
+	children []scheduleLink
+	parents  []scheduleLink
+}
+
+// scheduleLink connects a part to a parent or child through one BOM line
+type scheduleLink struct {
+	part   *partSchedule
+	offset int // LeadTimeOffsetDays of the BOM line
+}
+
+// neededAt returns how many days into this part's build a child is needed, given the offset
+// of its BOM line
+func (p *partSchedule) neededAt(offset int) int {
+	if offset > p.duration {
+		return p.duration
+	}
+	return offset
+}
+
+// scheduleNetwork is the BOM of a traversal as a network of part activities, ordered children
+// first; the BOM is acyclic, so the root comes last
+type scheduleNetwork struct {
+	root  *partSchedule
+	order []*partSchedule
+}
+
+// scheduleNetwork builds the network of parts reached by a traversal with this visitor, each
+// taking its lead time on the given basis
+func (v *CriticalPathVisitor) scheduleNetwork(result interface{}, leadTime leadTimeBasis) *scheduleNetwork {
+	parts := make(map[entities.PartNumber]*partSchedule)
+	visited := make(map[*pathVertex]bool)
+
+	var collect func(vertex *pathVertex) *partSchedule
+	collect = func(vertex *pathVertex) *partSchedule {
+		part, exists := parts[vertex.node.PartNumber]
+		if !exists {
+			part = &partSchedule{node: vertex.node, duration: leadTime(&vertex.node)}
+			parts[vertex.node.PartNumber] = part
+		} else if duration := leadTime(&vertex.node); duration > part.duration {
+			part.node, part.duration = vertex.node, duration
+		}
+		if visited[vertex] {
+			return part
+		}
+		visited[vertex] = true
+
+		for _, edge := range vertex.children {
+			child := collect(edge.child)
+			part.children = append(part.children, scheduleLink{part: child, offset: edge.offset})
+			child.parents = append(child.parents, scheduleLink{part: part, offset: edge.offset})
+		}
+		return part
+	}
+	network := &scheduleNetwork{root: collect(result.(*pathEdge).child)}
+
+	sorted := make(map[*partSchedule]bool)
+	var visit func(part *partSchedule)
+	visit = func(part *partSchedule) {
+		if sorted[part] {
+			return
+		}
+		sorted[part] = true
+		for _, link := range part.children {
+			visit(link.part)
+		}
+		network.order = append(network.order, part)
+	}
+	visit(network.root)
+	return network
+}
+
+// schedule computes the earliest and latest start and finish, and the float, of every part on
+// its current duration. Days count from the start of the build: a forward pass from the leaves
+// gives the earliest dates, and a backward pass from the top level part, finishing at its
+// earliest finish, gives the latest.
+func (n *scheduleNetwork) schedule() {
+	// Forward pass: a part starts once every child finishes by the time its build needs it
+	for _, part := range n.order {
+		start := 0
+		for _, link := range part.children {
+			if s := link.part.node.EarliestFinish - part.neededAt(link.offset); s > start {
+				start = s
+			}
+		}
+		part.node.EarliestStart = start
+		part.node.EarliestFinish = start + part.duration
+	}
+
+	// Backward pass: a part must finish by the time its most demanding parent needs it. The
+	// level is the deepest occurrence of the part, as in MRP low-level coding.
+	for i := len(n.order) - 1; i >= 0; i-- {
+		part := n.order[i]
+		node := &part.node
+		node.Level = 0
+		node.LatestFinish = n.root.node.EarliestFinish
+		node.FreeFloat = node.LatestFinish - node.EarliestFinish
+		for j, link := range part.parents {
+			parent := &link.part.node
+			latestFinish := parent.LatestStart + link.part.neededAt(link.offset)
+			freeFloat := parent.EarliestStart + link.part.neededAt(link.offset) - node.EarliestFinish
+			if j == 0 || latestFinish < node.LatestFinish {
+				node.LatestFinish = latestFinish
+			}
+			if j == 0 || freeFloat < node.FreeFloat {
+				node.FreeFloat = freeFloat
+			}
+			if parent.Level+1 > node.Level {
+				node.Level = parent.Level + 1
+			}
+		}
+		node.LatestStart = node.LatestFinish - part.duration
+		node.TotalFloat = node.LatestStart - node.EarliestStart
+	}
+}
+
+// partFloat computes the schedule and float of every part reached by a traversal with this
+// visitor. Parts come least float first.
+func (v *CriticalPathVisitor) partFloat(result interface{}, leadTime leadTimeBasis) []entities.CriticalPathNode {
+	network := v.scheduleNetwork(result, leadTime)
+	network.schedule()
+
+	floats := make([]entities.CriticalPathNode, len(network.order))
+	for i, part := range network.order {
+		floats[i] = part.node
+		floats[i].LeadTimeOffsetDays = 0
+	}
+	sort.Slice(floats, func(i, j int) bool {
+		if floats[i].TotalFloat != floats[j].TotalFloat {
+			return floats[i].TotalFloat < floats[j].TotalFloat
+		}
+		if floats[i].EarliestStart != floats[j].EarliestStart {
+			return floats[i].EarliestStart < floats[j].EarliestStart
+		}
+		return floats[i].PartNumber < floats[j].PartNumber
+	})
+	return floats
+}
+
+// applyFloat copies the schedule and float of each part onto the nodes of paths
+func applyFloat(paths []entities.CriticalPath, floats []entities.CriticalPathNode) {
+	byPart := make(map[entities.PartNumber]*entities.CriticalPathNode, len(floats))
+	for i := range floats {
+		byPart[floats[i].PartNumber] = &floats[i]
+	}
+	for i := range paths {
+		for j := range paths[i].PathDetails {
+			node := &paths[i].PathDetails[j]
+			if float, ok := byPart[node.PartNumber]; ok {
+				node.EarliestStart = float.EarliestStart
+				node.EarliestFinish = float.EarliestFinish
+				node.LatestStart = float.LatestStart
+				node.LatestFinish = float.LatestFinish
+				node.TotalFloat = float.TotalFloat
+				node.FreeFloat = float.FreeFloat
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package criticalpath
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// NewDemandCriticalPath projects when a demand completes if the build scheduled by its critical
+// path analysis starts on asOf
+func NewDemandCriticalPath(
+	demand *entities.DemandRequirement,
+	analysis *entities.CriticalPathAnalysis,
+	asOf time.Time,
+) entities.DemandCriticalPath {
+	completion := asOf.AddDate(0, 0, analysis.Duration())
+	return entities.DemandCriticalPath{
+		Demand:              demand,
+		Analysis:            analysis,
+		ProjectedCompletion: completion,
+		DaysLate:            int(math.Round(completion.Sub(demand.NeedDate).Hours() / 24)),
+	}
+}
+
+// AnalyzeProgram combines the critical paths of the demands in a plan. A part with no float in
+// the build of a demand gates that demand, and of each late demand it accounts for as many days
+// as it takes, up to the days the demand is late: shortening it by that much would bring the
+// demand that much closer to on time. Parts used by more than one demand are reported as shared
+// components, with the quantities the MRP run covered, planned and left short across the plan.
+func AnalyzeProgram(
+	asOf time.Time,
+	demands []entities.DemandCriticalPath,
+	orders []entities.PlannedOrder,
+	allocations []entities.AllocationResult,
+	shortages []entities.Shortage,
+) *entities.ProgramCriticalPath {
+	bottlenecks := make(map[entities.PartNumber]*entities.BottleneckPart)
+	shared := make(map[entities.PartNumber]*entities.SharedComponent)
+	var bottleneckOrder, sharedOrder []entities.PartNumber
+
+	for _, demand := range demands {
+		for _, node := range demand.Analysis.PartFloat {
+			component, exists := shared[node.PartNumber]
+			if !exists {
+				component = &entities.SharedComponent{
+					PartNumber:  node.PartNumber,
+					Description: node.Description,
+				}
+				shared[node.PartNumber] = component
+				sharedOrder = append(sharedOrder, node.PartNumber)
+			}
+			component.Demands = append(component.Demands, demand.Demand)
+
+			// Parts that take no time cannot hold anything up, even without float
+			duration := node.EarliestFinish - node.EarliestStart
+			if node.TotalFloat != 0 || duration == 0 {
+				continue
+			}
+			component.CriticalFor++
+
+			bottleneck, exists := bottlenecks[node.PartNumber]
+			if !exists {
+				bottleneck = &entities.BottleneckPart{
+					PartNumber:  node.PartNumber,
+					Description: node.Description,
+				}
+				bottlenecks[node.PartNumber] = bottleneck
+				bottleneckOrder = append(bottleneckOrder, node.PartNumber)
+			}
+			bottleneck.Demands = append(bottleneck.Demands, demand.Demand)
+			if duration > bottleneck.LeadTimeDays {
+				bottleneck.LeadTimeDays = duration
+			}
+			if demand.DaysLate > 0 {
+				bottleneck.LatenessDays += min(duration, demand.DaysLate)
+			}
+		}
+	}
+
+	program := &entities.ProgramCriticalPath{AsOf: asOf, Demands: demands}
+
+	for _, part := range bottleneckOrder {
+		program.Bottlenecks = append(program.Bottlenecks, *bottlenecks[part])
+	}
+	sort.SliceStable(program.Bottlenecks, func(i, j int) bool {
+		a, b := program.Bottlenecks[i], program.Bottlenecks[j]
+		if len(a.Demands) != len(b.Demands) {
+			return len(a.Demands) > len(b.Demands)
+		}
+		if a.LatenessDays != b.LatenessDays {
+			return a.LatenessDays > b.LatenessDays
+		}
+		return a.PartNumber < b.PartNumber
+	})
+
+	allocated := make(map[entities.PartNumber]entities.Quantity)
+	for _, alloc := range allocations {
+		allocated[alloc.PartNumber] += alloc.AllocatedQty
+	}
+	planned := make(map[entities.PartNumber]entities.Quantity)
+	for _, order := range orders {
+		planned[order.PartNumber] += order.Quantity
+	}
+	short := make(map[entities.PartNumber]entities.Quantity)
+	for _, shortage := range shortages {
+		short[shortage.PartNumber] += shortage.ShortQty
+	}
+	for _, part := range sharedOrder {
+		component := shared[part]
+		if len(component.Demands) < 2 {
+			continue
+		}
+		component.AllocatedQty = allocated[part]
+		component.PlannedQty = planned[part]
+		component.ShortQty = short[part]
+		program.SharedComponents = append(program.SharedComponents, *component)
+	}
+	sort.SliceStable(program.SharedComponents, func(i, j int) bool {
+		a, b := program.SharedComponents[i], program.SharedComponents[j]
+		if len(a.Demands) != len(b.Demands) {
+			return len(a.Demands) > len(b.Demands)
+		}
+		if a.CriticalFor != b.CriticalFor {
+			return a.CriticalFor > b.CriticalFor
+		}
+		return a.PartNumber < b.PartNumber
+	})
+
+	return program
+}
@@ -0,0 +1,175 @@
+package criticalpath
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// AnalyzeScheduleRisk simulates the builds of demands with lead times drawn from each item's
+// lead time range. Every iteration draws one lead time per part, shared by every demand using
+// it, and reschedules each build from asOf: parts covered by the MRP allocations are shortened
+// in proportion, as in critical path analysis. The same seed gives the same results.
+func (cps *CriticalPathService) AnalyzeScheduleRisk(
+	ctx context.Context,
+	demands []*entities.DemandRequirement,
+	allocations []entities.AllocationResult,
+	iterations int,
+	seed int64,
+	asOf time.Time,
+) (*entities.ScheduleRisk, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+
+	cps.bomTraverser.SetAllocationContext(allocations)
+	defer cps.bomTraverser.ClearAllocationContext()
+
+	// Build the schedule network of each demand once; iterations only change durations
+	networks := make([]*scheduleNetwork, len(demands))
+	items := make(map[entities.PartNumber]*entities.Item)
+	usage := make(map[entities.PartNumber]int)
+	var parts []entities.PartNumber
+	for i, demand := range demands {
+		visitor, err := cps.newVisitor(1, RankByEffectiveLeadTime)
+		if err != nil {
+			return nil, err
+		}
+		result, err := cps.bomTraverser.TraverseBOM(
+			ctx,
+			demand.PartNumber,
+			demand.TargetSerial,
+			demand.Location,
+			1,
+			demand.NeedDate,
+			0,
+			visitor,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse BOM for %s: %w", demand.PartNumber, err)
+		}
+		networks[i] = visitor.scheduleNetwork(result, effectiveLeadTime)
+
+		for _, part := range networks[i].order {
+			partNumber := part.node.PartNumber
+			usage[partNumber]++
+			if _, exists := items[partNumber]; exists {
+				continue
+			}
+			item, err := cps.itemRepo.GetItem(partNumber)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get item %s: %w", partNumber, err)
+			}
+			items[partNumber] = item
+			parts = append(parts, partNumber)
+		}
+	}
+	// Draw lead times in a fixed order, so that a seed always gives the same iterations
+	sort.Slice(parts, func(i, j int) bool { return parts[i] < parts[j] })
+
+	risk := &entities.ScheduleRisk{
+		AsOf:       asOf,
+		Iterations: iterations,
+		Seed:       seed,
+		Demands:    make([]entities.DemandRisk, len(demands)),
+	}
+	for i, network := range networks {
+		network.schedule()
+		risk.Demands[i] = entities.DemandRisk{
+			Demand:            demands[i],
+			PlannedCompletion: asOf.AddDate(0, 0, network.root.node.EarliestFinish),
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	sampled := make(map[entities.PartNumber]int, len(parts))
+	finishes := make([][]int, len(demands))
+	for i := range finishes {
+		finishes[i] = make([]int, iterations)
+	}
+	critical := make(map[entities.PartNumber]int)
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, partNumber := range parts {
+			sampled[partNumber] = items[partNumber].SampleLeadTimeDays(rng)
+		}
+
+		for i, network := range networks {
+			for _, part := range network.order {
+				part.duration = sampledDuration(&part.node, sampled[part.node.PartNumber])
+			}
+			network.schedule()
+			finishes[i][iteration] = network.root.node.EarliestFinish
+
+			for _, part := range network.order {
+				if part.node.TotalFloat == 0 && part.duration > 0 {
+					critical[part.node.PartNumber]++
+				}
+			}
+		}
+	}
+
+	for i := range risk.Demands {
+		demandRisk := &risk.Demands[i]
+		days := finishes[i]
+		sort.Ints(days)
+		demandRisk.P50 = asOf.AddDate(0, 0, percentile(days, 0.50))
+		demandRisk.P80 = asOf.AddDate(0, 0, percentile(days, 0.80))
+		demandRisk.P95 = asOf.AddDate(0, 0, percentile(days, 0.95))
+
+		onTime := 0
+		for _, finish := range days {
+			if !asOf.AddDate(0, 0, finish).After(demandRisk.Demand.NeedDate) {
+				onTime++
+			}
+		}
+		demandRisk.OnTimeProbability = float64(onTime) / float64(iterations)
+	}
+
+	// Parts that were never critical are left out
+	for _, partNumber := range parts {
+		if critical[partNumber] == 0 {
+			continue
+		}
+		item := items[partNumber]
+		risk.Parts = append(risk.Parts, entities.PartCriticality{
+			PartNumber:       partNumber,
+			Description:      item.Description,
+			Demands:          usage[partNumber],
+			CriticalityIndex: float64(critical[partNumber]) / float64(iterations*usage[partNumber]),
+			HasLeadTimeRange: item.LeadTimeRange != nil,
+		})
+	}
+	sort.SliceStable(risk.Parts, func(i, j int) bool {
+		return risk.Parts[i].CriticalityIndex > risk.Parts[j].CriticalityIndex
+	})
+
+	return risk, nil
+}
+
+// sampledDuration scales a part's effective lead time by a sampled lead time, so that inventory
+// covers the same share of the part as it does at its likely lead time
+func sampledDuration(node *entities.CriticalPathNode, sampledDays int) int {
+	if node.LeadTimeDays == 0 {
+		return 0
+	}
+	return int(math.Round(float64(sampledDays) * float64(node.EffectiveLeadTime) / float64(node.LeadTimeDays)))
+}
+
+// percentile returns the smallest of sorted values with at least fraction p of values at or
+// below it
+func percentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
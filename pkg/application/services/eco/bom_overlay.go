@@ -0,0 +1,252 @@
+package eco
+
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/domain/services"
+)
+
+// BOMOverlay presents a base BOM with a set of ECOs applied on top of it.
+// An ECO changes the BOM only for serials at or after its cut-in serial, so serial-aware
+// queries apply exactly the ECOs that have cut in, while serial-agnostic queries return
+// every line that is effective for some serial.
+type BOMOverlay struct {
+	base       repositories.BOMRepository
+	ecos       []*entities.ECO
+	serialComp *services.SerialComparator
+}
+
+// NewBOMOverlay creates an overlay applying ecos, in order, on top of base
+func NewBOMOverlay(
+	base repositories.BOMRepository,
+	ecos []*entities.ECO,
+	serialComp *services.SerialComparator,
+) *BOMOverlay {
+	if serialComp == nil {
+		serialComp = services.NewSerialComparator()
+	}
+	return &BOMOverlay{
+		base:       base,
+		ecos:       ecos,
+		serialComp: serialComp,
+	}
+}
+
+// Verify interface compliance
+var _ repositories.BOMRepository = (*BOMOverlay)(nil)
+
+// SelectECOs returns the ECOs to plan with: released ECOs, plus drafts when includePending is set
+func SelectECOs(ecos []*entities.ECO, includePending bool) []*entities.ECO {
+	var selected []*entities.ECO
+	for _, eco := range ecos {
+		if eco.IsReleased() || includePending {
+			selected = append(selected, eco)
+		}
+	}
+	return selected
+}
+
+// GetBOMLines returns every line of a part that is effective for some serial
+func (o *BOMOverlay) GetBOMLines(partNumber entities.PartNumber) ([]*entities.BOMLine, error) {
+	lines, err := o.base.GetBOMLines(partNumber)
+	if err != nil {
+		return nil, err
+	}
+	return filterParent(o.applyAnySerial(lines), partNumber), nil
+}
+
+// GetEffectiveLines returns the effective BOM lines for a part and a target serial of endItem
+func (o *BOMOverlay) GetEffectiveLines(
+	partNumber entities.PartNumber,
+	endItem entities.PartNumber,
+	serial string,
+) ([]*entities.BOMLine, error) {
+	lines, err := o.linesForSerial(partNumber, endItem, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	var effectiveLines []*entities.BOMLine
+	for _, line := range lines {
+		if o.serialComp.IsSerialInRangeForPart(endItem, serial, line.Effectivity) {
+			effectiveLines = append(effectiveLines, line)
+		}
+	}
+	return effectiveLines, nil
+}
+
+// GetAllBOMLines returns every line that is effective for some serial
+func (o *BOMOverlay) GetAllBOMLines() ([]*entities.BOMLine, error) {
+	lines, err := o.base.GetAllBOMLines()
+	if err != nil {
+		return nil, err
+	}
+	return o.applyAnySerial(lines), nil
+}
+
+// GetWhereUsed returns every line using a part as its child that is effective for some serial
+func (o *BOMOverlay) GetWhereUsed(childPN entities.PartNumber) ([]*entities.BOMLine, error) {
+	baseLines, err := o.base.GetWhereUsed(childPN)
+	if err != nil {
+		return nil, err
+	}
+
+	// ECOs may add the part to parents that do not use it in the base BOM
+	var parents []entities.PartNumber
+	for _, line := range baseLines {
+		parents = appendParent(parents, line.ParentPN)
+	}
+	for _, eco := range o.ecos {
+		for _, change := range eco.Changes {
+			if change.Action == entities.ECOAddLine && change.Line.ChildPN == childPN {
+				parents = appendParent(parents, change.Line.ParentPN)
+			}
+		}
+	}
+
+	var usages []*entities.BOMLine
+	for _, parentPN := range parents {
+		lines, err := o.GetBOMLines(parentPN)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if line.ChildPN == childPN {
+				usages = append(usages, line)
+			}
+		}
+	}
+	return usages, nil
+}
+
+// LoadBOMLines loads BOM lines into the base repository
+func (o *BOMOverlay) LoadBOMLines(lines []*entities.BOMLine) error {
+	return o.base.LoadBOMLines(lines)
+}
+
+// GetAlternateGroups returns BOM lines grouped by FindNumber for a parent part
+func (o *BOMOverlay) GetAlternateGroups(
+	parentPN entities.PartNumber,
+) (map[int][]*entities.BOMLine, error) {
+	lines, err := o.GetBOMLines(parentPN)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int][]*entities.BOMLine)
+	for _, line := range lines {
+		groups[line.FindNumber] = append(groups[line.FindNumber], line)
+	}
+	return groups, nil
+}
+
+// GetEffectiveAlternates returns alternate BOM lines for a specific FindNumber, serial and need date
+func (o *BOMOverlay) GetEffectiveAlternates(
+	parentPN entities.PartNumber,
+	findNumber int,
+	endItem entities.PartNumber,
+	targetSerial string,
+	needDate time.Time,
+) ([]*entities.BOMLine, error) {
+	lines, err := o.linesForSerial(parentPN, endItem, targetSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	var alternates []*entities.BOMLine
+	for _, line := range lines {
+		if line.FindNumber == findNumber &&
+			o.serialComp.IsSerialInRangeForPart(endItem, targetSerial, line.Effectivity) &&
+			line.DateEffectivity.IsEffectiveOn(needDate) {
+			alternates = append(alternates, line)
+		}
+	}
+	return alternates, nil
+}
+
+// linesForSerial returns a part's BOM lines with every ECO that has cut in for serial, a serial
+// of endItem, applied
+func (o *BOMOverlay) linesForSerial(
+	partNumber entities.PartNumber,
+	endItem entities.PartNumber,
+	serial string,
+) ([]*entities.BOMLine, error) {
+	lines, err := o.base.GetBOMLines(partNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, eco := range o.ecos {
+		if o.hasCutIn(eco, endItem, serial) {
+			lines = eco.Apply(lines)
+		}
+	}
+	return filterParent(lines, partNumber), nil
+}
+
+// applyAnySerial returns the union of the lines before any serialized cut-in and after all ECOs
+func (o *BOMOverlay) applyAnySerial(lines []*entities.BOMLine) []*entities.BOMLine {
+	beforeCutIn := lines
+	afterCutIn := lines
+	for _, eco := range o.ecos {
+		if eco.CutInSerial == "" {
+			beforeCutIn = eco.Apply(beforeCutIn)
+		}
+		afterCutIn = eco.Apply(afterCutIn)
+	}
+
+	union := append([]*entities.BOMLine{}, beforeCutIn...)
+	for _, line := range afterCutIn {
+		if !containsLine(union, line) {
+			union = append(union, line)
+		}
+	}
+	return union
+}
+
+// hasCutIn reports whether an ECO applies to serial, a serial of endItem
+func (o *BOMOverlay) hasCutIn(
+	eco *entities.ECO,
+	endItem entities.PartNumber,
+	serial string,
+) bool {
+	return eco.CutInSerial == "" ||
+		o.serialComp.IsSerialInRangeForPart(
+			endItem,
+			serial,
+			entities.SerialEffectivity{FromSerial: eco.CutInSerial},
+		)
+}
+
+// filterParent drops lines added by ECOs for other parents
+func filterParent(lines []*entities.BOMLine, parentPN entities.PartNumber) []*entities.BOMLine {
+	var filtered []*entities.BOMLine
+	for _, line := range lines {
+		if line.ParentPN == parentPN {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered
+}
+
+// appendParent appends a parent part number unless already present
+func appendParent(parents []entities.PartNumber, parentPN entities.PartNumber) []entities.PartNumber {
+	for _, existing := range parents {
+		if existing == parentPN {
+			return parents
+		}
+	}
+	return append(parents, parentPN)
+}
+
+// containsLine checks for an identical line (pointer or value)
+func containsLine(lines []*entities.BOMLine, target *entities.BOMLine) bool {
+	for _, line := range lines {
+		if line == target || *line == *target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package eco
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+// buildTurbopumpECOData creates an F-1 engine BOM and an ECO swapping the V1 turbopump for V2 at AS507
+func buildTurbopumpECOData(t *testing.T) (*memory.BOMRepository, *memory.ItemRepository, *entities.ECO) {
+	t.Helper()
+
+	itemRepo := memory.NewItemRepository(3)
+	for _, pn := range []entities.PartNumber{"F1_ENGINE", "F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"} {
+		item := &entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  30,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		}
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	allSerials := entities.SerialEffectivity{FromSerial: "AS501", ToSerial: ""}
+	bomRepo := memory.NewBOMRepository(1)
+	if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+		ParentPN:    "F1_ENGINE",
+		ChildPN:     "F1_TURBOPUMP_V1",
+		QtyPer:      1,
+		FindNumber:  100,
+		Effectivity: allSerials,
+	}); err != nil {
+		t.Fatalf("Failed to save BOM line: %v", err)
+	}
+
+	eco, err := entities.NewECO("ECO-001", "Turbopump V2 cut-in", entities.ECODraft, "AS507", []entities.ECOChange{
+		{
+			Action: entities.ECORemoveLine,
+			Line:   entities.BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V1", FindNumber: 100},
+		},
+		{
+			Action: entities.ECOAddLine,
+			Line: entities.BOMLine{
+				ParentPN:    "F1_ENGINE",
+				ChildPN:     "F1_TURBOPUMP_V2",
+				QtyPer:      1,
+				FindNumber:  100,
+				Effectivity: allSerials,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ECO: %v", err)
+	}
+
+	return bomRepo, itemRepo, eco
+}
+
+func TestBOMOverlay_CutInSerial(t *testing.T) {
+	bomRepo, _, eco := buildTurbopumpECOData(t)
+	overlay := NewBOMOverlay(bomRepo, []*entities.ECO{eco}, nil)
+
+	tests := []struct {
+		serial   string
+		expected entities.PartNumber
+	}{
+		{"AS506", "F1_TURBOPUMP_V1"},
+		{"AS507", "F1_TURBOPUMP_V2"},
+		{"AS512", "F1_TURBOPUMP_V2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serial, func(t *testing.T) {
+			alternates, err := overlay.GetEffectiveAlternates("F1_ENGINE", 100, "F1_ENGINE", tt.serial, time.Time{})
+			if err != nil {
+				t.Fatalf("Failed to get effective alternates: %v", err)
+			}
+			if len(alternates) != 1 || alternates[0].ChildPN != tt.expected {
+				t.Errorf("Expected only %s for %s, got %v", tt.expected, tt.serial, alternates)
+			}
+		})
+	}
+
+	// Serial-agnostic queries see both versions
+	lines, err := overlay.GetBOMLines("F1_ENGINE")
+	if err != nil {
+		t.Fatalf("Failed to get BOM lines: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Errorf("Expected V1 and V2 lines across all serials, got %d", len(lines))
+	}
+
+	// The base repository is untouched
+	baseLines, _ := bomRepo.GetBOMLines("F1_ENGINE")
+	if len(baseLines) != 1 || baseLines[0].ChildPN != "F1_TURBOPUMP_V1" {
+		t.Errorf("Expected base BOM to be unchanged, got %v", baseLines)
+	}
+}
+
+func TestBOMOverlay_GetWhereUsed(t *testing.T) {
+	bomRepo, _, eco := buildTurbopumpECOData(t)
+	overlay := NewBOMOverlay(bomRepo, []*entities.ECO{eco}, nil)
+
+	// V2 is only used through the ECO, V1 still by serials before the cut-in
+	for _, pn := range []entities.PartNumber{"F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"} {
+		usages, err := overlay.GetWhereUsed(pn)
+		if err != nil {
+			t.Fatalf("Failed to get where-used: %v", err)
+		}
+		if len(usages) != 1 || usages[0].ParentPN != "F1_ENGINE" {
+			t.Errorf("Expected %s to be used by F1_ENGINE, got %v", pn, usages)
+		}
+	}
+}
+
+func TestSelectECOs(t *testing.T) {
+	_, _, draft := buildTurbopumpECOData(t)
+	released := *draft
+	released.ID = "ECO-002"
+	released.Status = entities.ECOReleased
+	ecos := []*entities.ECO{draft, &released}
+
+	if got := SelectECOs(ecos, false); len(got) != 1 || got[0].ID != "ECO-002" {
+		t.Errorf("Expected only the released ECO without pending, got %v", got)
+	}
+	if got := SelectECOs(ecos, true); len(got) != 2 {
+		t.Errorf("Expected both ECOs with pending, got %d", len(got))
+	}
+}
+
+func TestImpactService_AnalyzeImpact(t *testing.T) {
+	bomRepo, itemRepo, eco := buildTurbopumpECOData(t)
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     2,
+			NeedDate:     time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "AS506",
+		},
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     3,
+			NeedDate:     time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_12",
+			Location:     "KSC",
+			TargetSerial: "AS507",
+		},
+	}
+	demandRepo := memory.NewDemandRepository()
+	if err := demandRepo.LoadDemands(demands); err != nil {
+		t.Fatalf("Failed to load demands: %v", err)
+	}
+	inventory := func() (repositories.InventoryRepository, error) {
+		return memory.NewInventoryRepository(), nil
+	}
+
+	service := NewImpactService(nil)
+	impact, err := service.AnalyzeImpact(
+		context.Background(),
+		eco,
+		nil,
+		demands,
+		bomRepo,
+		itemRepo,
+		inventory,
+		demandRepo,
+	)
+	if err != nil {
+		t.Fatalf("AnalyzeImpact failed: %v", err)
+	}
+
+	changes := make(map[entities.PartNumber]dto.PlannedOrderChange)
+	for _, change := range impact.Changes {
+		changes[change.PartNumber] = change
+	}
+
+	v1, ok := changes["F1_TURBOPUMP_V1"]
+	if !ok || v1.ChangeType != dto.OrderQuantityChanged || v1.QuantityBefore != 5 || v1.QuantityAfter != 2 {
+		t.Errorf("Expected V1 quantity to drop from 5 to 2, got %+v", v1)
+	}
+
+	v2, ok := changes["F1_TURBOPUMP_V2"]
+	if !ok || v2.ChangeType != dto.OrderAdded || v2.QuantityAfter != 3 {
+		t.Errorf("Expected 3 V2 turbopumps to be added, got %+v", v2)
+	}
+
+	if _, ok := changes["F1_ENGINE"]; ok {
+		t.Errorf("Expected engine orders to be unchanged, got %+v", changes["F1_ENGINE"])
+	}
+}
+
+func TestImpactService_AnalyzeImpact_ReceiptsAndFirmOrders(t *testing.T) {
+	bomRepo, itemRepo, eco := buildTurbopumpECOData(t)
+
+	needDate := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     2,
+			NeedDate:     needDate,
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "AS506",
+		},
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     3,
+			NeedDate:     needDate,
+			DemandSource: "APOLLO_12",
+			Location:     "KSC",
+			TargetSerial: "AS507",
+		},
+	}
+	demandRepo := memory.NewDemandRepository()
+	if err := demandRepo.LoadDemands(demands); err != nil {
+		t.Fatalf("Failed to load demands: %v", err)
+	}
+	inventory := func() (repositories.InventoryRepository, error) {
+		return memory.NewInventoryRepository(), nil
+	}
+
+	// A firm order for more V1 turbopumps than either plan needs, and one V2 already on order
+	service := NewImpactService(nil)
+	service.SetFirmPlannedOrders([]entities.PlannedOrder{
+		{
+			PartNumber:  "F1_TURBOPUMP_V1",
+			Quantity:    6,
+			StartDate:   needDate.AddDate(0, 0, -60),
+			DueDate:     needDate.AddDate(0, 0, -30),
+			Location:    "KSC",
+			OrderType:   entities.Buy,
+			Firm:        true,
+			OrderNumber: "FPO-1",
+		},
+	})
+	service.SetScheduledReceipts([]entities.ScheduledReceipt{
+		{
+			OrderNumber: "PO-1",
+			PartNumber:  "F1_TURBOPUMP_V2",
+			Quantity:    1,
+			DueDate:     needDate.AddDate(0, 0, -30),
+			Location:    "KSC",
+			OrderType:   entities.Buy,
+		},
+	})
+	impact, err := service.AnalyzeImpact(
+		context.Background(),
+		eco,
+		nil,
+		demands,
+		bomRepo,
+		itemRepo,
+		inventory,
+		demandRepo,
+	)
+	if err != nil {
+		t.Fatalf("AnalyzeImpact failed: %v", err)
+	}
+
+	changes := make(map[entities.PartNumber]dto.PlannedOrderChange)
+	for _, change := range impact.Changes {
+		changes[change.PartNumber] = change
+	}
+
+	if v1, ok := changes["F1_TURBOPUMP_V1"]; ok {
+		t.Errorf("Expected the firm order to cover V1 in both plans, got %+v", v1)
+	}
+	v2, ok := changes["F1_TURBOPUMP_V2"]
+	if !ok || v2.ChangeType != dto.OrderAdded || v2.QuantityAfter != 2 {
+		t.Errorf("Expected 2 V2 turbopumps to be added beyond the one on order, got %+v", v2)
+	}
+}
@@ -0,0 +1,209 @@
+package eco
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/domain/services"
+)
+
+// InventoryProvider returns a fresh inventory repository. Each MRP run allocates (and so
+// consumes) inventory, so comparing two plans needs an untouched copy for each run.
+type InventoryProvider func() (repositories.InventoryRepository, error)
+
+// ImpactService plans with and without an ECO to show which planned orders it would change
+type ImpactService struct {
+	serialComp *services.SerialComparator
+
+	// Open orders and firm planned orders both plans net before planning new orders
+	receipts   []entities.ScheduledReceipt
+	firmOrders []entities.PlannedOrder
+}
+
+// NewImpactService creates a new ECO impact service
+func NewImpactService(serialComp *services.SerialComparator) *ImpactService {
+	return &ImpactService{
+		serialComp: serialComp,
+	}
+}
+
+// SetScheduledReceipts registers the open orders both plans net against requirements
+func (s *ImpactService) SetScheduledReceipts(receipts []entities.ScheduledReceipt) {
+	s.receipts = receipts
+}
+
+// SetFirmPlannedOrders registers the planned orders the planner has fixed, which both plans
+// net against requirements and keep unchanged
+func (s *ImpactService) SetFirmPlannedOrders(orders []entities.PlannedOrder) {
+	s.firmOrders = orders
+}
+
+// AnalyzeImpact compares a plan using the applied ECOs (without target) against one that also
+// applies target. Orders are compared per part, the unit MRP plans them in.
+func (s *ImpactService) AnalyzeImpact(
+	ctx context.Context,
+	target *entities.ECO,
+	applied []*entities.ECO,
+	demands []*entities.DemandRequirement,
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventory InventoryProvider,
+	demandRepo repositories.DemandRepository,
+) (*dto.ECOImpact, error) {
+	var baseline []*entities.ECO
+	for _, eco := range applied {
+		if eco.ID != target.ID {
+			baseline = append(baseline, eco)
+		}
+	}
+	withTarget := append(append([]*entities.ECO{}, baseline...), target)
+
+	before, err := s.plan(ctx, baseline, demands, bomRepo, itemRepo, inventory, demandRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan without ECO %s: %w", target.ID, err)
+	}
+
+	after, err := s.plan(ctx, withTarget, demands, bomRepo, itemRepo, inventory, demandRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan with ECO %s: %w", target.ID, err)
+	}
+
+	return &dto.ECOImpact{
+		ECOID:   target.ID,
+		Status:  target.Status.String(),
+		Changes: compareOrders(before.PlannedOrders, after.PlannedOrders),
+	}, nil
+}
+
+// plan runs MRP against the base BOM with ecos applied, netting the receipts and firm orders
+func (s *ImpactService) plan(
+	ctx context.Context,
+	ecos []*entities.ECO,
+	demands []*entities.DemandRequirement,
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventory InventoryProvider,
+	demandRepo repositories.DemandRepository,
+) (*dto.MRPResult, error) {
+	inventoryRepo, err := inventory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inventory: %w", err)
+	}
+
+	// A fresh service per run so the explosion cache never mixes BOM versions
+	overlay := NewBOMOverlay(bomRepo, ecos, s.serialComp)
+	mrpService := mrp.NewMRPService()
+	mrpService.SetScheduledReceipts(s.receipts)
+	mrpService.SetFirmPlannedOrders(s.firmOrders)
+	return mrpService.ExplodeDemand(
+		ctx,
+		demands,
+		overlay,
+		itemRepo,
+		inventoryRepo,
+		demandRepo,
+	)
+}
+
+// orderSummary aggregates the planned orders for one part
+type orderSummary struct {
+	partNumber entities.PartNumber
+	quantity   entities.Quantity
+	start      time.Time
+	due        time.Time
+}
+
+// summarizeOrders aggregates planned orders by part
+func summarizeOrders(orders []entities.PlannedOrder) map[entities.PartNumber]*orderSummary {
+	summaries := make(map[entities.PartNumber]*orderSummary)
+	for _, order := range orders {
+		summary, exists := summaries[order.PartNumber]
+		if !exists {
+			summaries[order.PartNumber] = &orderSummary{
+				partNumber: order.PartNumber,
+				quantity:   order.Quantity,
+				start:      order.StartDate,
+				due:        order.DueDate,
+			}
+			continue
+		}
+
+		summary.quantity += order.Quantity
+		if order.StartDate.Before(summary.start) {
+			summary.start = order.StartDate
+		}
+		if order.DueDate.After(summary.due) {
+			summary.due = order.DueDate
+		}
+	}
+	return summaries
+}
+
+// compareOrders returns the per part differences between two plans, sorted by part
+func compareOrders(before, after []entities.PlannedOrder) []dto.PlannedOrderChange {
+	beforeSummaries := summarizeOrders(before)
+	afterSummaries := summarizeOrders(after)
+
+	var changes []dto.PlannedOrderChange
+	for partNumber, b := range beforeSummaries {
+		a, exists := afterSummaries[partNumber]
+		change := dto.PlannedOrderChange{
+			PartNumber:      b.partNumber,
+			QuantityBefore:  b.quantity,
+			StartDateBefore: b.start,
+			DueDateBefore:   b.due,
+		}
+
+		switch {
+		case !exists:
+			change.ChangeType = dto.OrderRemoved
+		case a.quantity != b.quantity:
+			change.ChangeType = dto.OrderQuantityChanged
+		case !sameDay(a.start, b.start) || !sameDay(a.due, b.due):
+			change.ChangeType = dto.OrderDatesChanged
+		default:
+			continue
+		}
+
+		if exists {
+			change.QuantityAfter = a.quantity
+			change.StartDateAfter = a.start
+			change.DueDateAfter = a.due
+		}
+		change.ChangeTypeName = change.ChangeType.String()
+		changes = append(changes, change)
+	}
+
+	for partNumber, a := range afterSummaries {
+		if _, exists := beforeSummaries[partNumber]; exists {
+			continue
+		}
+		changes = append(changes, dto.PlannedOrderChange{
+			PartNumber:     a.partNumber,
+			ChangeType:     dto.OrderAdded,
+			ChangeTypeName: dto.OrderAdded.String(),
+			QuantityAfter:  a.quantity,
+			StartDateAfter: a.start,
+			DueDateAfter:   a.due,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].PartNumber < changes[j].PartNumber
+	})
+
+	return changes
+}
+
+// sameDay compares schedule dates, which move in whole days; forward scheduling anchors on
+// the current time, so two runs also differ by the few moments between them
+func sameDay(a, b time.Time) bool {
+	diff := a.Sub(b)
+	return diff > -time.Hour && diff < time.Hour
+}
@@ -0,0 +1,301 @@
+package implosion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/domain/services"
+)
+
+// ImplosionService walks the BOM upward from a part to the assemblies, end items and
+// demands that use it (where-used)
+type ImplosionService struct {
+	bomRepo    repositories.BOMRepository
+	demandRepo repositories.DemandRepository
+}
+
+// NewImplosionService creates a new implosion service
+func NewImplosionService(
+	bomRepo repositories.BOMRepository,
+	demandRepo repositories.DemandRepository,
+) *ImplosionService {
+	return &ImplosionService{
+		bomRepo:    bomRepo,
+		demandRepo: demandRepo,
+	}
+}
+
+// WhereUsed returns every assembly, end item and demand using a part. A non-empty serial
+// only follows BOM lines effective for that serial and only reports that serial's demands;
+// otherwise each demand is checked against its own target serial.
+func (s *ImplosionService) WhereUsed(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	serial string,
+) (*dto.WhereUsedResult, error) {
+	im, err := s.implode(ctx, partNumber, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	endItems, err := im.endItems()
+	if err != nil {
+		return nil, err
+	}
+
+	demands, err := s.demandRepo.GetDemands()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demands: %w", err)
+	}
+
+	// Each demand is imploded against its own serial; serials are shared by many demands
+	bySerial := map[string]*implosion{serial: im}
+	var affected []dto.AffectedDemand
+	for _, demand := range demands {
+		if serial != "" && demand.TargetSerial != serial {
+			continue
+		}
+
+		demandImplosion, exists := bySerial[demand.TargetSerial]
+		if !exists {
+			demandImplosion, err = s.implode(ctx, partNumber, demand.TargetSerial)
+			if err != nil {
+				return nil, err
+			}
+			bySerial[demand.TargetSerial] = demandImplosion
+		}
+
+		if _, uses := demandImplosion.level[demand.PartNumber]; !uses {
+			continue
+		}
+		quantityPer, err := demandImplosion.quantityPer(demand.PartNumber)
+		if err != nil {
+			return nil, err
+		}
+		affected = append(affected, dto.AffectedDemand{
+			DemandSource:  demand.DemandSource,
+			PartNumber:    demand.PartNumber,
+			Quantity:      demand.Quantity,
+			NeedDate:      demand.NeedDate,
+			Location:      demand.Location,
+			TargetSerial:  demand.TargetSerial,
+			PartsRequired: quantityPer * demand.Quantity,
+		})
+	}
+
+	sort.SliceStable(affected, func(i, j int) bool {
+		return affected[i].NeedDate.Before(affected[j].NeedDate)
+	})
+
+	return &dto.WhereUsedResult{
+		PartNumber: partNumber,
+		Serial:     serial,
+		Usages:     im.usages,
+		EndItems:   endItems,
+		Demands:    affected,
+	}, nil
+}
+
+// implosion holds the upward closure of a part for one serial
+type implosion struct {
+	part     entities.PartNumber
+	usages   []dto.WhereUsedLine
+	level    map[entities.PartNumber]int                 // Shortest distance from each user down to the part
+	next     map[entities.PartNumber]entities.PartNumber // Next part down a shortest path
+	children map[entities.PartNumber][]*entities.BOMLine // Lines within the closure, by parent
+	memo     map[entities.PartNumber]entities.Quantity
+	visiting []entities.PartNumber // Parts whose quantityPer is being computed, outermost first
+}
+
+// implode walks breadth-first from the part up to its end items, so each using part is
+// reached first at its shortest level
+func (s *ImplosionService) implode(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	serial string,
+) (*implosion, error) {
+	im := &implosion{
+		part:     partNumber,
+		level:    map[entities.PartNumber]int{partNumber: 0},
+		next:     make(map[entities.PartNumber]entities.PartNumber),
+		children: make(map[entities.PartNumber][]*entities.BOMLine),
+		memo:     make(map[entities.PartNumber]entities.Quantity),
+	}
+	effective := make(map[entities.PartNumber][]*entities.BOMLine)
+
+	// Effectivity serials are serials of end items, so lines are resolved with the serial
+	// schemes of the end items above them
+	var endItems map[entities.PartNumber][]entities.PartNumber
+	if serial != "" {
+		allLines, err := s.bomRepo.GetAllBOMLines()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get BOM lines: %w", err)
+		}
+		endItems = services.EndItemsAbove(allLines)
+	}
+
+	queue := []entities.PartNumber{partNumber}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		child := queue[0]
+		queue = queue[1:]
+
+		lines, err := s.usingLines(child, serial, endItems, effective)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range lines {
+			level := im.level[child] + 1
+			im.usages = append(im.usages, dto.WhereUsedLine{
+				Level:      level,
+				ParentPN:   line.ParentPN,
+				ChildPN:    line.ChildPN,
+				QtyPer:     line.QtyPer,
+				FindNumber: line.FindNumber,
+				FromSerial: line.Effectivity.FromSerial,
+				ToSerial:   line.Effectivity.ToSerial,
+			})
+			im.children[line.ParentPN] = append(im.children[line.ParentPN], line)
+
+			if _, seen := im.level[line.ParentPN]; !seen {
+				im.level[line.ParentPN] = level
+				im.next[line.ParentPN] = child
+				queue = append(queue, line.ParentPN)
+			}
+		}
+	}
+
+	return im, nil
+}
+
+// usingLines returns the lines using a part, restricted to those effective for serial as a
+// serial of any end item above them
+func (s *ImplosionService) usingLines(
+	child entities.PartNumber,
+	serial string,
+	endItems map[entities.PartNumber][]entities.PartNumber,
+	effective map[entities.PartNumber][]*entities.BOMLine,
+) ([]*entities.BOMLine, error) {
+	lines, err := s.bomRepo.GetWhereUsed(child)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get where-used for %s: %w", child, err)
+	}
+	if serial == "" {
+		return lines, nil
+	}
+
+	var filtered []*entities.BOMLine
+	for _, line := range lines {
+		parentLines, cached := effective[line.ParentPN]
+		if !cached {
+			above := endItems[line.ParentPN]
+			if len(above) == 0 {
+				above = []entities.PartNumber{line.ParentPN}
+			}
+			for _, endItem := range above {
+				lines, err := s.bomRepo.GetEffectiveLines(line.ParentPN, endItem, serial)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get effective lines for %s: %w", line.ParentPN, err)
+				}
+				parentLines = append(parentLines, lines...)
+			}
+			effective[line.ParentPN] = parentLines
+		}
+
+		for _, effectiveLine := range parentLines {
+			if *effectiveLine == *line {
+				filtered = append(filtered, line)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// endItems returns the using parts that nothing else uses, sorted by part number
+func (im *implosion) endItems() ([]dto.EndItemUsage, error) {
+	var users []entities.PartNumber
+	for pn := range im.level {
+		if pn != im.part {
+			users = append(users, pn)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+
+	usedAsChild := make(map[entities.PartNumber]bool)
+	for _, usage := range im.usages {
+		usedAsChild[usage.ChildPN] = true
+	}
+
+	var endItems []dto.EndItemUsage
+	for _, pn := range users {
+		if usedAsChild[pn] {
+			continue
+		}
+
+		quantityPer, err := im.quantityPer(pn)
+		if err != nil {
+			return nil, err
+		}
+
+		path := []entities.PartNumber{pn}
+		for current := pn; current != im.part; {
+			current = im.next[current]
+			path = append(path, current)
+		}
+
+		endItems = append(endItems, dto.EndItemUsage{
+			EndItem:     pn,
+			Level:       im.level[pn],
+			Path:        path,
+			QuantityPer: quantityPer,
+		})
+	}
+	return endItems, nil
+}
+
+// quantityPer returns how many of the imploded part one unit of pn uses, summed over
+// find numbers. Alternates at a find number are either/or, so the group counts at its
+// largest quantity.
+func (im *implosion) quantityPer(pn entities.PartNumber) (entities.Quantity, error) {
+	if pn == im.part {
+		return 1, nil
+	}
+	if quantity, exists := im.memo[pn]; exists {
+		return quantity, nil
+	}
+	for i, visiting := range im.visiting {
+		if visiting == pn {
+			cycle := append(append([]entities.PartNumber{}, im.visiting[i:]...), pn)
+			return 0, &entities.CyclicBOMError{Cycle: cycle}
+		}
+	}
+	im.visiting = append(im.visiting, pn)
+	defer func() { im.visiting = im.visiting[:len(im.visiting)-1] }()
+
+	groups := make(map[int]entities.Quantity)
+	for _, line := range im.children[pn] {
+		childQuantity, err := im.quantityPer(line.ChildPN)
+		if err != nil {
+			return 0, err
+		}
+		if quantity := line.QtyPer * childQuantity; quantity > groups[line.FindNumber] {
+			groups[line.FindNumber] = quantity
+		}
+	}
+
+	var total entities.Quantity
+	for _, quantity := range groups {
+		total += quantity
+	}
+	im.memo[pn] = total
+	return total, nil
+}
@@ -0,0 +1,153 @@
+package implosion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+// buildRCSData creates a CSM whose RCS quads use RCS_VALVE; CSM104 onwards also uses a
+// second valve directly on the service module
+func buildRCSData(t *testing.T) (*memory.BOMRepository, *memory.DemandRepository) {
+	t.Helper()
+
+	allSerials := entities.SerialEffectivity{FromSerial: "CSM101", ToSerial: ""}
+	bomRepo := memory.NewBOMRepository(5)
+	lines := []*entities.BOMLine{
+		{ParentPN: "CSM", ChildPN: "SERVICE_MODULE", QtyPer: 1, FindNumber: 100, Effectivity: allSerials},
+		{ParentPN: "SERVICE_MODULE", ChildPN: "RCS_QUAD", QtyPer: 4, FindNumber: 100, Effectivity: allSerials},
+		{ParentPN: "RCS_QUAD", ChildPN: "RCS_VALVE", QtyPer: 2, FindNumber: 100, Effectivity: allSerials},
+		{
+			ParentPN:    "SERVICE_MODULE",
+			ChildPN:     "RCS_VALVE",
+			QtyPer:      1,
+			FindNumber:  200,
+			Effectivity: entities.SerialEffectivity{FromSerial: "CSM104", ToSerial: ""},
+		},
+		{ParentPN: "LM", ChildPN: "LM_RCS_THRUSTER", QtyPer: 16, FindNumber: 100, Effectivity: allSerials},
+	}
+	if err := bomRepo.LoadBOMLines(lines); err != nil {
+		t.Fatalf("Failed to load BOM lines: %v", err)
+	}
+
+	demandRepo := memory.NewDemandRepository()
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "CSM",
+			Quantity:     1,
+			NeedDate:     time.Date(1969, 7, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "CSM107",
+		},
+		{
+			PartNumber:   "CSM",
+			Quantity:     1,
+			NeedDate:     time.Date(1969, 3, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_9",
+			Location:     "KSC",
+			TargetSerial: "CSM103",
+		},
+		{
+			PartNumber:   "LM",
+			Quantity:     1,
+			NeedDate:     time.Date(1969, 7, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "LM5",
+		},
+	}
+	if err := demandRepo.LoadDemands(demands); err != nil {
+		t.Fatalf("Failed to load demands: %v", err)
+	}
+
+	return bomRepo, demandRepo
+}
+
+func TestImplosionService_WhereUsed(t *testing.T) {
+	bomRepo, demandRepo := buildRCSData(t)
+	service := NewImplosionService(bomRepo, demandRepo)
+
+	result, err := service.WhereUsed(context.Background(), "RCS_VALVE", "")
+	if err != nil {
+		t.Fatalf("WhereUsed failed: %v", err)
+	}
+
+	if len(result.Usages) != 4 {
+		t.Errorf("Expected 4 where-used lines, got %d: %+v", len(result.Usages), result.Usages)
+	}
+
+	if len(result.EndItems) != 1 {
+		t.Fatalf("Expected CSM as the only end item, got %+v", result.EndItems)
+	}
+	csm := result.EndItems[0]
+	if csm.EndItem != "CSM" || csm.Level != 2 {
+		t.Errorf("Expected CSM two levels up, got %+v", csm)
+	}
+	expectedPath := []entities.PartNumber{"CSM", "SERVICE_MODULE", "RCS_VALVE"}
+	if len(csm.Path) != len(expectedPath) {
+		t.Fatalf("Expected path %v, got %v", expectedPath, csm.Path)
+	}
+	for i, pn := range expectedPath {
+		if csm.Path[i] != pn {
+			t.Errorf("Expected path %v, got %v", expectedPath, csm.Path)
+			break
+		}
+	}
+	if csm.QuantityPer != 9 {
+		t.Errorf("Expected 4x2 quad valves plus 1 direct valve = 9 per CSM, got %d", csm.QuantityPer)
+	}
+
+	// Each demand is checked against its own serial: CSM103 predates the direct valve
+	if len(result.Demands) != 2 {
+		t.Fatalf("Expected the two CSM demands, got %+v", result.Demands)
+	}
+	if result.Demands[0].DemandSource != "APOLLO_9" || result.Demands[0].PartsRequired != 8 {
+		t.Errorf("Expected APOLLO_9 first needing 8 valves, got %+v", result.Demands[0])
+	}
+	if result.Demands[1].DemandSource != "APOLLO_11" || result.Demands[1].PartsRequired != 9 {
+		t.Errorf("Expected APOLLO_11 needing 9 valves, got %+v", result.Demands[1])
+	}
+}
+
+func TestImplosionService_WhereUsedForSerial(t *testing.T) {
+	bomRepo, demandRepo := buildRCSData(t)
+	service := NewImplosionService(bomRepo, demandRepo)
+
+	result, err := service.WhereUsed(context.Background(), "RCS_VALVE", "CSM103")
+	if err != nil {
+		t.Fatalf("WhereUsed failed: %v", err)
+	}
+
+	for _, usage := range result.Usages {
+		if usage.ParentPN == "SERVICE_MODULE" && usage.ChildPN == "RCS_VALVE" {
+			t.Errorf("Expected the direct valve line to be filtered out for CSM103, got %+v", usage)
+		}
+	}
+	if len(result.EndItems) != 1 || result.EndItems[0].QuantityPer != 8 {
+		t.Errorf("Expected 8 valves per CSM103, got %+v", result.EndItems)
+	}
+	if len(result.Demands) != 1 || result.Demands[0].TargetSerial != "CSM103" {
+		t.Errorf("Expected only the CSM103 demand, got %+v", result.Demands)
+	}
+}
+
+func TestImplosionService_UnusedPart(t *testing.T) {
+	bomRepo, demandRepo := buildRCSData(t)
+	service := NewImplosionService(bomRepo, demandRepo)
+
+	result, err := service.WhereUsed(context.Background(), "CSM", "")
+	if err != nil {
+		t.Fatalf("WhereUsed failed: %v", err)
+	}
+
+	if len(result.Usages) != 0 || len(result.EndItems) != 0 {
+		t.Errorf("Expected no users of an end item, got %+v", result)
+	}
+	if len(result.Demands) != 2 || result.Demands[0].PartsRequired != 1 {
+		t.Errorf("Expected the end item's own demands, got %+v", result.Demands)
+	}
+}
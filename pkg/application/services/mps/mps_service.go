@@ -0,0 +1,352 @@
+package mps
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+)
+
+// Config sets the horizon of a master schedule
+type Config struct {
+	Start      time.Time // Start of the first period; earlier orders are past due in the first period
+	PeriodDays int       // Length of each period in days (defaults to 7)
+	Periods    int       // Number of periods (0 to reach the latest forecast or customer order)
+}
+
+// MPSService builds the master production schedule of the end items with independent demand
+type MPSService struct {
+	itemRepo      repositories.ItemRepository
+	inventoryRepo repositories.InventoryRepository
+}
+
+// NewMPSService creates a new MPS service
+func NewMPSService(
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+) *MPSService {
+	return &MPSService{
+		itemRepo:      itemRepo,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// endItem identifies a master schedule record
+type endItem struct {
+	partNumber entities.PartNumber
+	location   string
+}
+
+// BuildSchedule master schedules every part and location with a forecast or customer order.
+// In each period, demand inside the item's demand time fence is its customer orders alone, and
+// beyond it the larger of forecast and customer orders (orders consume the forecast). On-hand
+// stock, scheduled receipts and firm planned orders cover demand first; when the projected
+// available balance falls below safety stock, a lot sized MPS quantity is planned in that
+// period, but never inside the planning time fence, so shortfalls there carry to the first
+// period beyond it.
+//
+// The inventory repository is only read, so a schedule can be reported on its own; call
+// ReserveStock before MRP explodes it.
+func (s *MPSService) BuildSchedule(
+	ctx context.Context,
+	forecasts []*entities.Forecast,
+	customerOrders []*entities.DemandRequirement,
+	receipts []entities.ScheduledReceipt,
+	firmOrders []entities.PlannedOrder,
+	config Config,
+) (*entities.MasterSchedule, error) {
+	if config.PeriodDays == 0 {
+		config.PeriodDays = 7
+	}
+	if config.PeriodDays < 0 {
+		return nil, fmt.Errorf("period length must be positive, got %d days", config.PeriodDays)
+	}
+	if config.Periods < 0 {
+		return nil, fmt.Errorf("number of periods cannot be negative, got %d", config.Periods)
+	}
+
+	start := shared.DateOf(config.Start)
+	seen := make(map[endItem]bool)
+	var endItems []endItem
+	latest := start
+	for _, order := range customerOrders {
+		key := endItem{order.PartNumber, order.Location}
+		if !seen[key] {
+			seen[key] = true
+			endItems = append(endItems, key)
+		}
+		if order.NeedDate.After(latest) {
+			latest = order.NeedDate
+		}
+	}
+	for _, forecast := range forecasts {
+		key := endItem{forecast.PartNumber, forecast.Location}
+		if !seen[key] {
+			seen[key] = true
+			endItems = append(endItems, key)
+		}
+		if forecast.PeriodStart.After(latest) {
+			latest = forecast.PeriodStart
+		}
+	}
+	sort.Slice(endItems, func(i, j int) bool {
+		if endItems[i].partNumber != endItems[j].partNumber {
+			return endItems[i].partNumber < endItems[j].partNumber
+		}
+		return endItems[i].location < endItems[j].location
+	})
+
+	periods := config.Periods
+	if periods == 0 {
+		periods = shared.DaysBetween(start, shared.DateOf(latest))/config.PeriodDays + 1
+	}
+
+	schedule := &entities.MasterSchedule{
+		Start:      start,
+		PeriodDays: config.PeriodDays,
+		Records:    make([]entities.MPSRecord, 0, len(endItems)),
+	}
+	for _, key := range endItems {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := s.buildRecord(key, forecasts, customerOrders, receipts, firmOrders, start,
+			config.PeriodDays, periods)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Records = append(schedule.Records, *record)
+	}
+
+	return schedule, nil
+}
+
+// buildRecord master schedules one end item
+func (s *MPSService) buildRecord(
+	key endItem,
+	forecasts []*entities.Forecast,
+	customerOrders []*entities.DemandRequirement,
+	receipts []entities.ScheduledReceipt,
+	firmOrders []entities.PlannedOrder,
+	start time.Time,
+	periodDays, periods int,
+) (*entities.MPSRecord, error) {
+	item, err := s.itemRepo.GetItem(key.partNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get end item %s: %w", key.partNumber, err)
+	}
+
+	onHand, err := s.stockOnHand(key)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &entities.MPSRecord{
+		PartNumber:  key.partNumber,
+		Location:    key.location,
+		OnHand:      onHand,
+		SafetyStock: item.SafetyStock,
+		Periods:     make([]entities.MPSPeriod, periods),
+	}
+	if item.DemandTimeFenceDays > 0 {
+		record.DemandTimeFence = start.AddDate(0, 0, item.DemandTimeFenceDays)
+	}
+	if item.PlanningTimeFenceDays > 0 {
+		record.PlanningTimeFence = start.AddDate(0, 0, item.PlanningTimeFenceDays)
+	}
+	for i := range record.Periods {
+		period := &record.Periods[i]
+		period.Start = start.AddDate(0, 0, i*periodDays)
+		period.End = start.AddDate(0, 0, (i+1)*periodDays)
+		switch {
+		case !record.DemandTimeFence.IsZero() && period.Start.Before(record.DemandTimeFence):
+			period.Zone = entities.FrozenZone
+		case !record.PlanningTimeFence.IsZero() && period.Start.Before(record.PlanningTimeFence):
+			period.Zone = entities.SlushyZone
+		default:
+			period.Zone = entities.LiquidZone
+		}
+	}
+
+	// bucket returns the period a date falls in, or -1 beyond the horizon
+	bucket := func(date time.Time) int {
+		days := shared.DaysBetween(start, shared.DateOf(date))
+		if days < 0 {
+			return 0
+		}
+		if i := days / periodDays; i < periods {
+			return i
+		}
+		return -1
+	}
+
+	forecastSerials := make([]string, periods)
+	for _, forecast := range forecasts {
+		// Forecast for periods already past has expired; only past due orders carry
+		if forecast.PartNumber != key.partNumber || forecast.Location != key.location ||
+			shared.DateOf(forecast.PeriodStart).Before(start) {
+			continue
+		}
+		if i := bucket(forecast.PeriodStart); i >= 0 {
+			record.Periods[i].Forecast += forecast.Quantity
+			if forecastSerials[i] == "" {
+				forecastSerials[i] = forecast.TargetSerial
+			}
+		}
+	}
+	for _, order := range customerOrders {
+		if order.PartNumber != key.partNumber || order.Location != key.location {
+			continue
+		}
+		if i := bucket(order.NeedDate); i >= 0 {
+			record.Periods[i].CustomerOrders += order.Quantity
+			if record.Periods[i].TargetSerial == "" {
+				record.Periods[i].TargetSerial = order.TargetSerial
+			}
+		}
+	}
+	for _, receipt := range receipts {
+		if receipt.PartNumber != key.partNumber || receipt.Location != key.location {
+			continue
+		}
+		if i := bucket(receipt.DueDate); i >= 0 {
+			record.Periods[i].ScheduledReceipts += receipt.Quantity
+		}
+	}
+	for _, order := range firmOrders {
+		if order.PartNumber != key.partNumber || order.Location != key.location {
+			continue
+		}
+		if i := bucket(order.DueDate); i >= 0 {
+			record.Periods[i].FirmMPS += order.Quantity
+		}
+	}
+
+	// Project the available balance, planning MPS quantities beyond the planning time fence
+	available := onHand
+	for i := range record.Periods {
+		period := &record.Periods[i]
+		period.ProjectedDemand = period.CustomerOrders
+		if period.Zone != entities.FrozenZone && period.Forecast > period.CustomerOrders {
+			period.ProjectedDemand = period.Forecast
+		}
+		if period.TargetSerial == "" {
+			period.TargetSerial = forecastSerials[i]
+		}
+
+		available += period.ScheduledReceipts + period.FirmMPS - period.ProjectedDemand
+		if available < item.SafetyStock && period.Zone == entities.LiquidZone {
+			period.PlannedMPS = item.LotSize(item.SafetyStock - available)
+			available += period.PlannedMPS
+		}
+		period.ProjectedAvailable = available
+	}
+	fillSerials(record.Periods)
+	calculateATP(record.Periods, onHand)
+
+	return record, nil
+}
+
+// ReserveStock reserves the end item stock a schedule counted on in the inventory repository,
+// so MRP explodes the MPS against the stock that is left instead of netting it twice. Stock
+// allocated elsewhere since the schedule was built yields an *entities.AllocationConflictError.
+func (s *MPSService) ReserveStock(schedule *entities.MasterSchedule) error {
+	for _, record := range schedule.Records {
+		if record.OnHand == 0 {
+			continue
+		}
+		allocation, err := s.inventoryRepo.AllocateInventory(record.PartNumber, record.Location, record.OnHand)
+		if err != nil {
+			return fmt.Errorf("failed to reserve inventory for %s: %w", record.PartNumber, err)
+		}
+		if allocation.RemainingDemand > 0 {
+			return &entities.AllocationConflictError{
+				PartNumber: record.PartNumber,
+				Location:   record.Location,
+				Status:     entities.Allocated,
+				Quantity:   allocation.RemainingDemand,
+			}
+		}
+	}
+	return nil
+}
+
+// stockOnHand returns the available stock of an end item
+func (s *MPSService) stockOnHand(key endItem) (entities.Quantity, error) {
+	lots, err := s.inventoryRepo.GetInventoryLots(key.partNumber, key.location)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inventory for %s: %w", key.partNumber, err)
+	}
+	serials, err := s.inventoryRepo.GetSerializedInventory(key.partNumber, key.location)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inventory for %s: %w", key.partNumber, err)
+	}
+
+	onHand := entities.Quantity(len(serials))
+	for _, lot := range lots {
+		onHand += lot.Quantity
+	}
+	return onHand, nil
+}
+
+// fillSerials gives periods without demand of their own the serial of the period before, and
+// leading periods the first serial demanded
+func fillSerials(periods []entities.MPSPeriod) {
+	first := ""
+	for i := range periods {
+		if periods[i].TargetSerial == "" && i > 0 {
+			periods[i].TargetSerial = periods[i-1].TargetSerial
+		}
+		if first == "" {
+			first = periods[i].TargetSerial
+		}
+	}
+	for i := 0; i < len(periods) && periods[i].TargetSerial == ""; i++ {
+		periods[i].TargetSerial = first
+	}
+}
+
+// calculateATP works out the available to promise of each period that receives supply (and of
+// the first, which has the on-hand stock): its supply less the customer orders due before the
+// next supply arrives. A shortfall borrows from the ATP of earlier supply; the cumulative ATP
+// is the running total.
+func calculateATP(periods []entities.MPSPeriod, onHand entities.Quantity) {
+	var supplied []int
+	for i := range periods {
+		if i == 0 || periods[i].ScheduledReceipts+periods[i].MPSQuantity() > 0 {
+			supplied = append(supplied, i)
+		}
+	}
+
+	for j, i := range supplied {
+		next := len(periods)
+		if j+1 < len(supplied) {
+			next = supplied[j+1]
+		}
+		atp := periods[i].ScheduledReceipts + periods[i].MPSQuantity()
+		if i == 0 {
+			atp += onHand
+		}
+		for k := i; k < next; k++ {
+			atp -= periods[k].CustomerOrders
+		}
+		periods[i].AvailableToPromise = atp
+	}
+
+	for j := len(supplied) - 1; j > 0; j-- {
+		if short := periods[supplied[j]].AvailableToPromise; short < 0 {
+			periods[supplied[j-1]].AvailableToPromise += short
+			periods[supplied[j]].AvailableToPromise = 0
+		}
+	}
+
+	var cumulative entities.Quantity
+	for i := range periods {
+		cumulative += periods[i].AvailableToPromise
+		periods[i].CumulativeATP = cumulative
+	}
+}
@@ -0,0 +1,162 @@
+package mps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+func TestMPSService_BuildSchedule(t *testing.T) {
+	itemRepo := memory.NewItemRepository(1)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	// Period 0 is inside the demand time fence and period 1 inside the planning time fence
+	if err := itemRepo.SaveItem(&entities.Item{
+		PartNumber:            "ENGINE",
+		LeadTimeDays:          30,
+		LotSizeRule:           entities.MinimumQty,
+		MinOrderQty:           4,
+		SafetyStock:           1,
+		DemandTimeFenceDays:   7,
+		PlanningTimeFenceDays: 14,
+	}); err != nil {
+		t.Fatalf("Failed to save item: %v", err)
+	}
+	if err := inventoryRepo.SaveInventoryLot(&entities.InventoryLot{
+		PartNumber:  "ENGINE",
+		LotNumber:   "LOT001",
+		Location:    "PLANT",
+		Quantity:    3,
+		ReceiptDate: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		Status:      entities.Available,
+	}); err != nil {
+		t.Fatalf("Failed to save inventory: %v", err)
+	}
+
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	day := func(days int) time.Time { return start.AddDate(0, 0, days) }
+
+	var forecasts []*entities.Forecast
+	for period := 0; period < 6; period++ {
+		forecasts = append(forecasts, &entities.Forecast{
+			PartNumber:   "ENGINE",
+			Quantity:     2,
+			PeriodStart:  day(period * 7),
+			Location:     "PLANT",
+			TargetSerial: "SN9",
+		})
+	}
+	order := func(qty entities.Quantity, needInDays int, serial string) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   "ENGINE",
+			Quantity:     qty,
+			NeedDate:     day(needInDays),
+			DemandSource: serial,
+			Location:     "PLANT",
+			TargetSerial: serial,
+		}
+	}
+	customerOrders := []*entities.DemandRequirement{
+		order(1, -5, "SN0"), // Past due, so in the first period
+		order(3, 1, "SN1"),
+		order(1, 8, "SN2"),
+		order(3, 15, "SN3"),
+		order(1, 29, "SN4"),
+	}
+	firmOrder, err := entities.NewFirmPlannedOrder("FPO-1", "ENGINE", 2, day(-10), day(22), "PLANT", entities.Make)
+	if err != nil {
+		t.Fatalf("Failed to create firm planned order: %v", err)
+	}
+
+	service := NewMPSService(itemRepo, inventoryRepo)
+	schedule, err := service.BuildSchedule(
+		context.Background(),
+		forecasts,
+		customerOrders,
+		nil,
+		[]entities.PlannedOrder{*firmOrder},
+		Config{Start: start, PeriodDays: 7},
+	)
+	if err != nil {
+		t.Fatalf("BuildSchedule failed: %v", err)
+	}
+
+	if len(schedule.Records) != 1 {
+		t.Fatalf("Expected 1 MPS record, got %d", len(schedule.Records))
+	}
+	record := schedule.Records[0]
+	if record.OnHand != 3 {
+		t.Errorf("Expected 3 on hand, got %d", record.OnHand)
+	}
+
+	// The frozen period ignores its forecast; the shortfall the fences leave is planned in
+	// period 2, the first beyond the planning time fence
+	expected := []struct {
+		zone          entities.TimeFenceZone
+		demand        entities.Quantity
+		plannedMPS    entities.Quantity
+		available     entities.Quantity
+		atp           entities.Quantity
+		cumulativeATP entities.Quantity
+		serial        string
+	}{
+		{entities.FrozenZone, 4, 0, -1, -2, -2, "SN0"},
+		{entities.SlushyZone, 2, 0, -3, 0, -2, "SN2"},
+		{entities.LiquidZone, 3, 7, 1, 4, 2, "SN3"},
+		{entities.LiquidZone, 2, 0, 1, 2, 4, "SN9"},
+		{entities.LiquidZone, 2, 4, 3, 3, 7, "SN4"},
+		{entities.LiquidZone, 2, 0, 1, 0, 7, "SN9"},
+	}
+	if len(record.Periods) != len(expected) {
+		t.Fatalf("Expected %d periods to reach the last forecast, got %d", len(expected), len(record.Periods))
+	}
+	for i, want := range expected {
+		got := record.Periods[i]
+		if got.Zone != want.zone || got.ProjectedDemand != want.demand || got.PlannedMPS != want.plannedMPS ||
+			got.ProjectedAvailable != want.available || got.AvailableToPromise != want.atp ||
+			got.CumulativeATP != want.cumulativeATP || got.TargetSerial != want.serial {
+			t.Errorf("Period %d: expected %s demand %d MPS %d PAB %d ATP %d cum %d %s, got %s demand %d MPS %d PAB %d ATP %d cum %d %s",
+				i, want.zone, want.demand, want.plannedMPS, want.available, want.atp, want.cumulativeATP, want.serial,
+				got.Zone, got.ProjectedDemand, got.PlannedMPS, got.ProjectedAvailable, got.AvailableToPromise,
+				got.CumulativeATP, got.TargetSerial)
+		}
+	}
+	if record.Periods[3].FirmMPS != 2 {
+		t.Errorf("Expected the firm order in period 3, got %d", record.Periods[3].FirmMPS)
+	}
+
+	// MRP explodes the firm and planned MPS quantities, against the stock the MPS left
+	demands := schedule.Demands()
+	var quantities []entities.Quantity
+	for _, demand := range demands {
+		quantities = append(quantities, demand.Quantity)
+	}
+	if len(demands) != 3 || quantities[0] != 7 || quantities[1] != 2 || quantities[2] != 4 {
+		t.Errorf("Expected MPS demands of 7, 2 and 4, got %v", quantities)
+	}
+	if !demands[0].NeedDate.Equal(day(14)) || demands[0].TargetSerial != "SN3" {
+		t.Errorf("Expected the first MPS demand at the start of period 2 for SN3, got %v %s",
+			demands[0].NeedDate, demands[0].TargetSerial)
+	}
+	if lots, _ := inventoryRepo.GetInventoryLots("ENGINE", "PLANT"); len(lots) != 1 || lots[0].Quantity != 3 {
+		t.Errorf("Expected building the MPS to leave the end item stock alone, got %d lots", len(lots))
+	}
+	if err := service.ReserveStock(schedule); err != nil {
+		t.Fatalf("Failed to reserve stock: %v", err)
+	}
+	if lots, _ := inventoryRepo.GetInventoryLots("ENGINE", "PLANT"); len(lots) != 0 {
+		t.Errorf("Expected the MPS to reserve the end item stock, %d lots left", len(lots))
+	}
+}
+
+func TestMPSService_BuildSchedule_InvalidConfig(t *testing.T) {
+	service := NewMPSService(memory.NewItemRepository(0), memory.NewInventoryRepository())
+	for _, config := range []Config{{PeriodDays: -1}, {Periods: -1}} {
+		if _, err := service.BuildSchedule(context.Background(), nil, nil, nil, nil, config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package mrp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// actionMessages compares scheduled receipts, firm planned orders and planned orders with the
+// requirements they cover, and returns what the planner should do about them, most urgent
+// first, along with the firm order suggestions of time fences:
+//   - a receipt (or firm planned order) due after the earliest requirement it covers is
+//     expedited to it, and one due before it is pushed out
+//   - a receipt nothing needs is cancelled, as is the part of one beyond its requirements
+//   - a receipt that falls short, where planned orders cover the rest at its part and
+//     location, is increased by their quantity instead of releasing them
+//   - any other planned order that starts today is released now
+//
+// netReqs are the requirements left for planned orders once receipts are netted.
+func actionMessages(
+	receiptPegs []receiptPeg,
+	orders []entities.PlannedOrder,
+	netReqs []*entities.NetRequirement,
+	fenceMessages []entities.ActionMessage,
+	now time.Time,
+) []entities.ActionMessage {
+	messages := append([]entities.ActionMessage(nil), fenceMessages...)
+
+	// The receipt due last at each part and location absorbs any planned orders there
+	lastReceipt := make(map[string]*receiptPeg)
+	for i := range receiptPegs {
+		peg := &receiptPegs[i]
+		receipt := peg.receipt
+		key := fmt.Sprintf("%s|%s", receipt.PartNumber, receipt.Location)
+		if last, exists := lastReceipt[key]; !exists || !receipt.DueDate.Before(last.receipt.DueDate) {
+			lastReceipt[key] = peg
+		}
+
+		message := entities.ActionMessage{
+			PartNumber:  receipt.PartNumber,
+			Location:    receipt.Location,
+			OrderNumber: receipt.OrderNumber,
+			Quantity:    receipt.Quantity,
+			DueDate:     receipt.DueDate,
+			NeedDate:    peg.needDate,
+		}
+		if peg.pegged > 0 {
+			days := shared.DaysBetween(shared.DateOf(peg.needDate), shared.DateOf(receipt.DueDate))
+			switch {
+			case days > 0:
+				message.Action, message.Days = entities.ActionExpedite, days
+				messages = append(messages, message)
+			case days < 0:
+				message.Action, message.Days = entities.ActionPushOut, -days
+				messages = append(messages, message)
+			}
+		}
+		if excess := receipt.Quantity - peg.pegged; excess > 0 {
+			message.Action, message.Days, message.Quantity = entities.ActionCancel, 0, excess
+			messages = append(messages, message)
+		}
+	}
+
+	earliestNeed := make(map[entities.PartNumber]time.Time)
+	for _, req := range netReqs {
+		if need, exists := earliestNeed[req.PartNumber]; !exists || req.NeedDate.Before(need) {
+			earliestNeed[req.PartNumber] = req.NeedDate
+		}
+	}
+
+	increases := make(map[*receiptPeg]*entities.ActionMessage)
+	var increaseOrder []*receiptPeg
+	for _, order := range orders {
+		need := earliestNeed[order.PartNumber]
+		if peg, exists := lastReceipt[fmt.Sprintf("%s|%s", order.PartNumber, order.Location)]; exists {
+			increase, seen := increases[peg]
+			if !seen {
+				increase = &entities.ActionMessage{
+					Action:      entities.ActionIncrease,
+					PartNumber:  order.PartNumber,
+					Location:    order.Location,
+					OrderNumber: peg.receipt.OrderNumber,
+					DueDate:     peg.receipt.DueDate,
+					NeedDate:    need,
+				}
+				increases[peg] = increase
+				increaseOrder = append(increaseOrder, peg)
+			}
+			increase.Quantity += order.Quantity
+			continue
+		}
+
+		if shared.DateOf(order.StartDate).After(shared.DateOf(now)) {
+			continue
+		}
+		messages = append(messages, entities.ActionMessage{
+			Action:     entities.ActionRelease,
+			PartNumber: order.PartNumber,
+			Location:   order.Location,
+			Quantity:   order.Quantity,
+			DueDate:    order.DueDate,
+			NeedDate:   need,
+			Days:       shared.DaysBetween(shared.DateOf(need), shared.DateOf(order.DueDate)),
+		})
+	}
+	for _, peg := range increaseOrder {
+		messages = append(messages, *increases[peg])
+	}
+
+	// Earliest need first, so the planner works down the messages in the order the
+	// requirements fall due; cancellations of receipts nothing needs come last
+	sort.SliceStable(messages, func(i, j int) bool {
+		a, b := &messages[i], &messages[j]
+		if !a.NeedDate.Equal(b.NeedDate) {
+			if a.NeedDate.IsZero() || b.NeedDate.IsZero() {
+				return b.NeedDate.IsZero()
+			}
+			return a.NeedDate.Before(b.NeedDate)
+		}
+		if a.Days != b.Days {
+			return a.Days > b.Days
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		if a.PartNumber != b.PartNumber {
+			return a.PartNumber < b.PartNumber
+		}
+		return a.OrderNumber < b.OrderNumber
+	})
+	return messages
+}
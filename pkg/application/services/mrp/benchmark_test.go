@@ -3,6 +3,7 @@ package mrp
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -145,8 +146,118 @@ func BenchmarkMRPService_LargeScale(b *testing.B) {
 	}
 }
 
+func BenchmarkMRPService_ParallelExplosion(b *testing.B) {
+	ctx := context.Background()
+
+	scenarios := []struct {
+		items, maxDepth, demands int
+	}{
+		{items: 5000, maxDepth: 6, demands: 100},
+		{items: 30000, maxDepth: 8, demands: 50},
+	}
+
+	for _, sc := range scenarios {
+		bomRepo, itemRepo, inventoryRepo, demandRepo, demands := setupGeneratedScenario(
+			sc.items, sc.maxDepth, sc.demands, 42)
+
+		for _, workers := range []int{1, 2, 4, 8} {
+			name := fmt.Sprintf("items=%d/demands=%d/workers=%d", sc.items, sc.demands, workers)
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					// A fresh service per run, so every run explodes instead of hitting the cache
+					service := NewMRPServiceWithConfig(EngineConfig{Concurrency: workers})
+					_, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+					if err != nil {
+						b.Fatalf("ExplodeDemand failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
 // Helper functions for benchmark setup
 
+// setupGeneratedScenario builds a scenario shaped like the ones `mrp generate` writes: items
+// spread over maxDepth levels, each assembly using 2-5 parts of the next level (so components
+// are shared between assemblies), and demands for random top level parts and serials
+func setupGeneratedScenario(
+	itemCount, maxDepth, demandCount int,
+	seed int64,
+) (*memory.BOMRepository, *memory.ItemRepository, *memory.InventoryRepository, *memory.DemandRepository, []*entities.DemandRequirement) {
+	rng := rand.New(rand.NewSource(seed))
+	bomRepo := memory.NewBOMRepository(itemCount * 3)
+	itemRepo := memory.NewItemRepository(itemCount)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	// Level sizes grow geometrically towards the leaves
+	levels := make([][]entities.PartNumber, maxDepth)
+	weight := 0
+	for level := 0; level < maxDepth; level++ {
+		weight += 1 << level
+	}
+	for level := 0; level < maxDepth; level++ {
+		count := itemCount * (1 << level) / weight
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			pn := entities.PartNumber(fmt.Sprintf("L%d_PART_%d", level, i))
+			levels[level] = append(levels[level], pn)
+			if err := itemRepo.SaveItem(&entities.Item{
+				PartNumber:    pn,
+				Description:   fmt.Sprintf("Level %d part %d", level, i),
+				LeadTimeDays:  5 + rng.Intn(30),
+				LotSizeRule:   entities.LotForLot,
+				MinOrderQty:   entities.Quantity(1),
+				UnitOfMeasure: "EA",
+			}); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	for level := 0; level < maxDepth-1; level++ {
+		children := levels[level+1]
+		for _, parent := range levels[level] {
+			childCount := 2 + rng.Intn(4)
+			used := make(map[entities.PartNumber]bool)
+			for findNumber := 1; findNumber <= childCount; findNumber++ {
+				child := children[rng.Intn(len(children))]
+				if used[child] {
+					continue
+				}
+				used[child] = true
+				if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+					ParentPN:    parent,
+					ChildPN:     child,
+					QtyPer:      entities.Quantity(1 + rng.Intn(3)),
+					FindNumber:  findNumber * 10,
+					Effectivity: entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+				}); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	demands := make([]*entities.DemandRequirement, demandCount)
+	for i := range demands {
+		demands[i] = &entities.DemandRequirement{
+			PartNumber:   levels[0][rng.Intn(len(levels[0]))],
+			Quantity:     entities.Quantity(1 + rng.Intn(3)),
+			NeedDate:     start.AddDate(0, 0, 90+rng.Intn(365)),
+			DemandSource: fmt.Sprintf("ORDER_%d", i),
+			Location:     "FACTORY",
+			TargetSerial: fmt.Sprintf("SN%03d", 1+rng.Intn(100)),
+		}
+	}
+
+	return bomRepo, itemRepo, inventoryRepo, demandRepo, demands
+}
+
 func setupDeepBOM(
 	levels int,
 ) (*memory.BOMRepository, *memory.ItemRepository, *memory.InventoryRepository, *memory.DemandRepository) {
@@ -262,7 +373,8 @@ func setupLargeScaleBOM(
 	inventoryRepo := memory.NewInventoryRepository()
 	demandRepo := memory.NewDemandRepository()
 
-	// Create a realistic hierarchy: 1 vehicle, 10 systems, 100 assemblies, rest components
+	// Create a realistic hierarchy: 1 vehicle, 10 systems, 100 assemblies, rest components. The
+	// vehicle is the top level part demanded, VEHICLE_SYSTEM.
 	levels := []struct {
 		prefix string
 		count  int
@@ -280,6 +392,9 @@ func setupLargeScaleBOM(
 
 		for i := 0; i < level.count; i++ {
 			partNum := entities.PartNumber(fmt.Sprintf("%s_%d", level.prefix, i))
+			if levelIdx == 0 {
+				partNum = "VEHICLE_SYSTEM"
+			}
 			currentLevelParts = append(currentLevelParts, partNum)
 
 			item := &entities.Item{
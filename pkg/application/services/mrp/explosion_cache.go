@@ -0,0 +1,89 @@
+package mrp
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// explosionCache is a least recently used cache of unit BOM explosions, safe for concurrent use
+type explosionCache struct {
+	mutex    sync.Mutex
+	capacity int                                     // 0 = unlimited
+	entries  map[dto.ExplosionCacheKey]*list.Element // Values are *explosionCacheEntry
+	order    *list.List                              // Most recently used first
+	stats    dto.CacheStats                          // Cumulative; Entries is filled in on read
+}
+
+// explosionCacheEntry is one element of the recency list
+type explosionCacheEntry struct {
+	key    dto.ExplosionCacheKey
+	result *dto.ExplosionResult
+}
+
+// newExplosionCache creates a cache holding at most capacity entries (0 = unlimited)
+func newExplosionCache(capacity int) *explosionCache {
+	return &explosionCache{
+		capacity: capacity,
+		entries:  make(map[dto.ExplosionCacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached explosion for key, marking it most recently used
+func (c *explosionCache) get(key dto.ExplosionCacheKey) (*dto.ExplosionResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*explosionCacheEntry).result, true
+}
+
+// put stores an explosion, evicting the least recently used entries beyond capacity
+func (c *explosionCache) put(key dto.ExplosionCacheKey, result *dto.ExplosionResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		element.Value.(*explosionCacheEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&explosionCacheEntry{key: key, result: result})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*explosionCacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// statistics returns the cumulative hit, miss and eviction counts and the current size
+func (c *explosionCache) statistics() dto.CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// snapshot returns a copy of the cached entries
+func (c *explosionCache) snapshot() map[dto.ExplosionCacheKey]*dto.ExplosionResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries := make(map[dto.ExplosionCacheKey]*dto.ExplosionResult, len(c.entries))
+	for key, element := range c.entries {
+		entries[key] = element.Value.(*explosionCacheEntry).result
+	}
+	return entries
+}
@@ -0,0 +1,50 @@
+package mrp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+func TestExplosionCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newExplosionCache(2)
+	key := func(pn entities.PartNumber) dto.ExplosionCacheKey {
+		return explosionCacheKey(pn, "TOP", "SN001", time.Time{})
+	}
+
+	cache.put(key("A"), &dto.ExplosionResult{})
+	cache.put(key("B"), &dto.ExplosionResult{})
+	if _, ok := cache.get(key("A")); !ok { // A is now more recently used than B
+		t.Fatal("Expected A to be cached")
+	}
+	cache.put(key("C"), &dto.ExplosionResult{})
+
+	if _, ok := cache.get(key("B")); ok {
+		t.Error("Expected B, the least recently used entry, to be evicted")
+	}
+	for _, pn := range []entities.PartNumber{"A", "C"} {
+		if _, ok := cache.get(key(pn)); !ok {
+			t.Errorf("Expected %s to stay cached", pn)
+		}
+	}
+
+	stats := cache.statistics()
+	expected := dto.CacheStats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2}
+	if stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+func TestExplosionCache_Unlimited(t *testing.T) {
+	cache := newExplosionCache(0)
+	for i := 0; i < 100; i++ {
+		cache.put(explosionCacheKey("A", "TOP", "SN001", time.Time{}.AddDate(0, 0, i)), &dto.ExplosionResult{})
+	}
+
+	stats := cache.statistics()
+	if stats.Entries != 100 || stats.Evictions != 0 {
+		t.Errorf("Expected 100 entries and no evictions, got %+v", stats)
+	}
+}
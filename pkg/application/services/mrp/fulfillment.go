@@ -0,0 +1,221 @@
+package mrp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// supply is a scheduled receipt or planned order a demand can be pegged to
+type supply struct {
+	quantity entities.Quantity
+	dueDate  time.Time
+	order    *entities.PlannedOrder
+	receipt  *entities.ScheduledReceipt
+}
+
+// projectFulfillment projects when each demand is fulfilled. The demands for a part at a
+// location are pegged in need date order, first to the stock allocated there, then to the
+// part's scheduled receipts and planned orders at that location in due date order; a demand is
+// fulfilled when the last unit it needs arrives. gatedBy maps each part to the child its start waits on,
+// which traces a late order down to the order holding it up.
+func projectFulfillment(
+	demands []*entities.DemandRequirement,
+	allocations []entities.AllocationResult,
+	receipts []entities.ScheduledReceipt,
+	orders []entities.PlannedOrder,
+	shortages []entities.Shortage,
+	depGraph DependencyGraph,
+	gatedBy map[entities.PartNumber]entities.PartNumber,
+	now time.Time,
+) []entities.DemandFulfillment {
+	stock := make(map[string]entities.Quantity)
+	for _, allocation := range allocations {
+		stock[fmt.Sprintf("%s|%s", allocation.PartNumber, allocation.Location)] += allocation.AllocatedQty
+	}
+
+	// Supplies are keyed like stock, by part and location, so a receipt or order at one site
+	// never fulfils a demand at another
+	supplies := make(map[string][]supply)
+	for i := range receipts {
+		receipt := &receipts[i]
+		key := fmt.Sprintf("%s|%s", receipt.PartNumber, receipt.Location)
+		supplies[key] = append(supplies[key],
+			supply{quantity: receipt.Quantity, dueDate: receipt.DueDate, receipt: receipt})
+	}
+	for i := range orders {
+		order := &orders[i]
+		key := fmt.Sprintf("%s|%s", order.PartNumber, order.Location)
+		supplies[key] = append(supplies[key],
+			supply{quantity: order.Quantity, dueDate: order.DueDate, order: order})
+	}
+	for _, partSupplies := range supplies {
+		sort.SliceStable(partSupplies, func(i, j int) bool {
+			return partSupplies[i].dueDate.Before(partSupplies[j].dueDate)
+		})
+	}
+
+	byKey := make(map[string][]int)
+	var keys []string
+	for i, demand := range demands {
+		key := fmt.Sprintf("%s|%s", demand.PartNumber, demand.Location)
+		if _, exists := byKey[key]; !exists {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], i)
+	}
+
+	fulfillment := make([]entities.DemandFulfillment, len(demands))
+	for _, key := range keys {
+		indexes := byKey[key]
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return demands[indexes[i]].NeedDate.Before(demands[indexes[j]].NeedDate)
+		})
+
+		partSupplies := supplies[key]
+		next, left := 0, entities.Quantity(0)
+		if len(partSupplies) > 0 {
+			left = partSupplies[0].quantity
+		}
+
+		for _, i := range indexes {
+			demand := demands[i]
+			f := entities.DemandFulfillment{
+				PartNumber:    demand.PartNumber,
+				Quantity:      demand.Quantity,
+				NeedDate:      demand.NeedDate,
+				DemandSource:  demand.DemandSource,
+				Location:      demand.Location,
+				TargetSerial:  demand.TargetSerial,
+				ProjectedDate: now,
+			}
+
+			f.StockQty = min(stock[key], demand.Quantity)
+			stock[key] -= f.StockQty
+
+			var supplying *supply
+			planned := false // Pegged to a planned order, so its components must be built too
+			for f.StockQty+f.OrderQty < demand.Quantity && next < len(partSupplies) {
+				qty := min(left, demand.Quantity-f.StockQty-f.OrderQty)
+				f.OrderQty += qty
+				left -= qty
+				supplying = &partSupplies[next]
+				planned = planned || supplying.order != nil
+				if left == 0 {
+					next++
+					if next < len(partSupplies) {
+						left = partSupplies[next].quantity
+					}
+				}
+			}
+			f.ShortQty = demand.Quantity - f.StockQty - f.OrderQty
+
+			// Copies, so that the report does not alias the result's orders and shortages
+			if shortage := gatingShortage(demand, f.ShortQty, planned, shortages, depGraph); shortage != nil {
+				gate := *shortage
+				f.GatingShortage = &gate
+				f.ProjectedDate = time.Time{}
+			} else {
+				if supplying != nil {
+					f.ProjectedDate = supplying.dueDate
+					gate := gatingSupply(demand.PartNumber, demand.Location, supplying, supplies, gatedBy)
+					if gate.receipt != nil {
+						receipt := *gate.receipt
+						f.GatingReceipt = &receipt
+					} else {
+						order := *gate.order
+						f.GatingOrder = &order
+					}
+				}
+				f.DaysLate = shared.DaysBetween(shared.DateOf(demand.NeedDate), shared.DateOf(f.ProjectedDate))
+			}
+			fulfillment[i] = f
+		}
+	}
+
+	return fulfillment
+}
+
+// gatingShortage returns the shortage leaving a demand unfulfilled: one on the demanded part,
+// at the demand's location when its supply falls short, or else the earliest needed shortage of a component of the
+// part for the same serial, when the demand waits on a planned order
+func gatingShortage(
+	demand *entities.DemandRequirement,
+	shortQty entities.Quantity,
+	planned bool,
+	shortages []entities.Shortage,
+	depGraph DependencyGraph,
+) *entities.Shortage {
+	if shortQty > 0 {
+		for i := range shortages {
+			if shortages[i].PartNumber == demand.PartNumber && shortages[i].Location == demand.Location {
+				return &shortages[i]
+			}
+		}
+		// The part was never netted, so there is no shortage record to point at
+		return &entities.Shortage{
+			PartNumber:   demand.PartNumber,
+			Location:     demand.Location,
+			ShortQty:     shortQty,
+			NeedDate:     demand.NeedDate,
+			DemandTrace:  demand.DemandSource,
+			TargetSerial: demand.TargetSerial,
+		}
+	}
+	if !planned {
+		return nil
+	}
+
+	components := make(map[entities.PartNumber]bool)
+	queue := []entities.PartNumber{demand.PartNumber}
+	for len(queue) > 0 {
+		node, exists := depGraph[queue[0]]
+		queue = queue[1:]
+		if !exists {
+			continue
+		}
+		for _, child := range node.DirectChildren {
+			if !components[child] {
+				components[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var gate *entities.Shortage
+	for i := range shortages {
+		shortage := &shortages[i]
+		if !components[shortage.PartNumber] || shortage.TargetSerial != demand.TargetSerial {
+			continue
+		}
+		if gate == nil || shortage.NeedDate.Before(gate.NeedDate) ||
+			(shortage.NeedDate.Equal(gate.NeedDate) && shortage.PartNumber < gate.PartNumber) {
+			gate = shortage
+		}
+	}
+	return gate
+}
+
+// gatingSupply follows the children holding up a part's start down to the last one, and
+// returns its last receipt or order at the location, the one the chain waits on. A part that
+// starts immediately is gated by the supply it is pegged to.
+func gatingSupply(
+	partNumber entities.PartNumber,
+	location string,
+	supplying *supply,
+	supplies map[string][]supply,
+	gatedBy map[entities.PartNumber]entities.PartNumber,
+) *supply {
+	gate := supplying
+	seen := map[entities.PartNumber]bool{partNumber: true}
+	for child, ok := gatedBy[partNumber]; ok && !seen[child]; child, ok = gatedBy[child] {
+		seen[child] = true
+		if childSupplies := supplies[fmt.Sprintf("%s|%s", child, location)]; len(childSupplies) > 0 {
+			gate = &childSupplies[len(childSupplies)-1]
+		}
+	}
+	return gate
+}
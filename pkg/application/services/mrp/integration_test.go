@@ -232,8 +232,10 @@ func TestMRPIntegration_PerformanceWithLargeBOM(t *testing.T) {
 
 	// Verify explosive growth was handled
 	// With 5 levels, 10 parts per level, qty 2 each:
-	// Level 4 (leaf) should have 2^4 = 16 units needed
-	expectedLeafQty := entities.Quantity(16) // 2^4
+	// Level 4 (leaf) should have 2^4 = 16 units needed per path down the BOM. Every part uses
+	// all 10 parts of the level below, so the leaf is reached through 10^3 paths (one choice of
+	// part on each of levels 1 to 3), and needs 10^3 * 2^4 = 16000 units in all.
+	expectedLeafQty := entities.Quantity(16000) // 10^3 * 2^4
 
 	foundLeafOrder := false
 	for _, order := range result.PlannedOrders {
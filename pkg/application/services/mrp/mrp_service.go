@@ -2,7 +2,9 @@ package mrp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"runtime/debug"
 	"sync"
 	"time"
@@ -19,6 +21,8 @@ type EngineConfig struct {
 	EnableGCPacing bool
 	// MaxCacheEntries limits the explosion cache size (0 = unlimited)
 	MaxCacheEntries int
+	// Concurrency is the number of workers exploding demands in parallel (0 or 1 = sequential)
+	Concurrency int
 }
 
 // DependencyNode represents a part in the dependency graph for forward scheduling
@@ -29,6 +33,10 @@ type DependencyNode struct {
 	Level          int
 	DirectChildren []entities.PartNumber // Parts this part depends on (immediate children only)
 	DirectParents  []entities.PartNumber // Parts that depend on this part (immediate parents only)
+
+	// ChildLeadTimeOffsets holds, per direct child, how many days after this part starts
+	// the child is needed (the smallest offset when a child is used at several find numbers)
+	ChildLeadTimeOffsets map[entities.PartNumber]int
 }
 
 // DependencyGraph maps part numbers to their dependency information
@@ -38,9 +46,17 @@ type DependencyGraph map[entities.PartNumber]*DependencyNode
 type MRPService struct {
 	config EngineConfig
 
-	// Memoization cache for BOM explosions
-	explosionCache map[dto.ExplosionCacheKey]*dto.ExplosionResult
-	cacheMutex     sync.RWMutex
+	// Memoization cache for BOM explosions, shared by every run of the service
+	explosionCache *explosionCache
+
+	observer ProgressObserver
+
+	// Open orders and firm planned orders netted before planning new ones
+	receipts   []entities.ScheduledReceipt
+	firmOrders []entities.PlannedOrder
+
+	// Time runs plan from; zero for the time each run starts
+	runDate time.Time
 }
 
 // NewMRPService creates a new MRP service with default configuration
@@ -48,6 +64,7 @@ func NewMRPService() *MRPService {
 	return NewMRPServiceWithConfig(EngineConfig{
 		EnableGCPacing:  true,
 		MaxCacheEntries: 10000,
+		Concurrency:     runtime.GOMAXPROCS(0),
 	})
 }
 
@@ -55,11 +72,18 @@ func NewMRPService() *MRPService {
 func NewMRPServiceWithConfig(config EngineConfig) *MRPService {
 	return &MRPService{
 		config:         config,
-		explosionCache: make(map[dto.ExplosionCacheKey]*dto.ExplosionResult),
+		explosionCache: newExplosionCache(config.MaxCacheEntries),
 	}
 }
 
-// ExplodeDemand performs complete MRP explosion with forward scheduling for the given demands
+// SetRunDate makes later runs plan as of date: orders start no earlier than it and time fences
+// are measured from it (the zero time to plan from the time of each run)
+func (s *MRPService) SetRunDate(date time.Time) {
+	s.runDate = date
+}
+
+// ExplodeDemand performs complete MRP explosion with forward scheduling for the given demands.
+// It stops with ctx.Err() once ctx is cancelled, and reports progress to the observer.
 func (s *MRPService) ExplodeDemand(
 	ctx context.Context,
 	demands []*entities.DemandRequirement,
@@ -81,42 +105,51 @@ func (s *MRPService) ExplodeDemand(
 		PlannedOrders:  make([]entities.PlannedOrder, 0, estimatedOrders),
 		Allocations:    make([]entities.AllocationResult, 0, len(demands)*10),
 		ShortageReport: make([]entities.Shortage, 0, estimatedOrders/2),
-		ExplosionCache: make(map[dto.ExplosionCacheKey]*dto.ExplosionResult),
 	}
 
 	// MULTI-PASS FORWARD SCHEDULING APPROACH
 
 	// Pass 1: Explode all demands to gross requirements using BOM traverser
-	var allGrossRequirements []*entities.GrossRequirement
-	targetSerial := "" // Will use first demand's target serial for dependency graph
-
-	for _, demand := range demands {
-		if targetSerial == "" {
-			targetSerial = demand.TargetSerial
-		}
+	statsBefore := s.explosionCache.statistics()
+	allGrossRequirements, err := s.explodeDemands(ctx, demands, bomRepo, itemRepo, inventoryRepo)
+	if err != nil {
+		return nil, err
+	}
 
-		grossReqs, err := s.explodeRequirements(
+	// Pass 1b: Phantom stock is used before the phantom's components, so when there is any,
+	// explode again allocating it as it is reached
+	var phantomAllocations map[string]*entities.AllocationResult
+	drawPhantoms, err := hasPhantomStock(allGrossRequirements, itemRepo, inventoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	if drawPhantoms {
+		allGrossRequirements, phantomAllocations, err = s.explodeDrawingPhantomStock(
 			ctx,
-			demand.PartNumber,
-			demand.TargetSerial,
-			demand.NeedDate,
-			demand.DemandSource,
-			demand.Location,
-			demand.Quantity,
+			demands,
 			bomRepo,
 			itemRepo,
 			inventoryRepo,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to explode demand for %s: %w", demand.PartNumber, err)
+			return nil, err
+		}
+	}
+
+	// Will use first demand's target serial, and the part it is a serial of, for dependency graph
+	targetSerial, endItem := "", entities.PartNumber("")
+	for _, demand := range demands {
+		if demand.TargetSerial != "" {
+			targetSerial, endItem = demand.TargetSerial, demand.PartNumber
+			break
 		}
-		allGrossRequirements = append(allGrossRequirements, grossReqs...)
 	}
 
 	// Pass 2: Allocate available inventory against gross requirements FIRST
 	allocations, netRequirements, err := s.allocateInventory(
 		ctx,
 		allGrossRequirements,
+		phantomAllocations,
 		inventoryRepo,
 	)
 	if err != nil {
@@ -125,12 +158,16 @@ func (s *MRPService) ExplodeDemand(
 
 	result.Allocations = allocations
 
+	// Pass 2b: Cover what inventory could not with scheduled receipts and firm planned orders
+	netRequirements, receiptPegs := s.netScheduledReceipts(netRequirements)
+
 	// Pass 3: Build dependency graph from gross requirements and BOM structure
 	depGraph, err := s.buildDependencyGraph(
 		ctx,
 		allGrossRequirements,
 		bomRepo,
 		itemRepo,
+		endItem,
 		targetSerial,
 	)
 	if err != nil {
@@ -138,124 +175,276 @@ func (s *MRPService) ExplodeDemand(
 	}
 
 	// Pass 4: Topological sort to get proper scheduling order
-	sortedParts := s.topologicalSort(depGraph)
+	sortedParts, err := s.topologicalSort(depGraph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to order parts for scheduling: %w", err)
+	}
+
+	// Pass 4b: Hold back requirements inside their demand time fence from new orders
+	now := s.runDate
+	if now.IsZero() {
+		now = time.Now()
+	}
+	plannableRequirements, fenceMessages := applyDemandTimeFences(netRequirements, depGraph, now)
 
 	// Pass 5: Forward schedule with dependency timing and inventory consideration
-	plannedOrders, err := s.scheduleForward(sortedParts, depGraph, allocations, netRequirements)
+	plannedOrders, gatedBy, scheduleMessages, err := s.scheduleForward(
+		ctx,
+		sortedParts,
+		depGraph,
+		allocations,
+		receiptPegs,
+		plannableRequirements,
+		now,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform forward scheduling: %w", err)
 	}
-	result.PlannedOrders = plannedOrders
+	fenceMessages = append(fenceMessages, scheduleMessages...)
+	result.PlannedOrders = make([]entities.PlannedOrder, 0, len(s.firmOrders)+len(plannedOrders))
+	result.PlannedOrders = append(result.PlannedOrders, s.firmOrders...)
+	result.PlannedOrders = append(result.PlannedOrders, plannedOrders...)
 
-	// Pass 6: Identify shortages
-	shortages := s.identifyShortages(netRequirements, plannedOrders)
+	// Pass 6: Identify shortages, including requirements held back by demand time fences
+	shortages := s.identifyShortages(netRequirements, plannedOrders, depGraph)
 	result.ShortageReport = shortages
 
-	// Pass 7: Copy explosion cache to result
-	s.cacheMutex.RLock()
-	for key, value := range s.explosionCache {
-		result.ExplosionCache[key] = value
-	}
-	s.cacheMutex.RUnlock()
+	// Pass 7: Project when the stock and orders pegged to each demand fulfill it
+	result.DemandFulfillment = projectFulfillment(
+		demands,
+		allocations,
+		s.receipts,
+		result.PlannedOrders,
+		shortages,
+		depGraph,
+		gatedBy,
+		now,
+	)
 
-	// Clean cache if it's getting too large
-	s.cleanCacheIfNeeded()
+	// Pass 8: Tell the planner what to release, firm, reschedule or cancel
+	result.ActionMessages = actionMessages(receiptPegs, plannedOrders, plannableRequirements, fenceMessages, now)
+
+	// Pass 9: Copy explosion cache and this run's cache use to result
+	result.ExplosionCache = s.explosionCache.snapshot()
+	statsAfter := s.explosionCache.statistics()
+	result.CacheStats = dto.CacheStats{
+		Hits:      statsAfter.Hits - statsBefore.Hits,
+		Misses:    statsAfter.Misses - statsBefore.Misses,
+		Evictions: statsAfter.Evictions - statsBefore.Evictions,
+		Entries:   statsAfter.Entries,
+	}
 
 	return result, nil
 }
 
-// explodeRequirements recursively explodes a part's BOM with memoization using BOMTraverser
-func (s *MRPService) explodeRequirements(
+// explodeDemands explodes every demand to gross requirements on up to config.Concurrency
+// workers. The requirements are merged in demand order, and a cached explosion expands to
+// exactly the requirements a fresh one produces, so the result does not depend on the number
+// of workers or on which of them fills the cache first.
+func (s *MRPService) explodeDemands(
 	ctx context.Context,
-	pn entities.PartNumber,
-	targetSerial string,
-	needDate time.Time,
-	demandTrace string,
-	location string,
-	quantity entities.Quantity,
+	demands []*entities.DemandRequirement,
 	bomRepo repositories.BOMRepository,
 	itemRepo repositories.ItemRepository,
 	inventoryRepo repositories.InventoryRepository,
 ) ([]*entities.GrossRequirement, error) {
+	keyByDate, err := hasDateEffectivity(bomRepo)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create cache key for memoization
-	cacheKey := dto.ExplosionCacheKey{
-		PartNumber: pn,
-		SerialEffectivity: entities.SerialEffectivity{
-			FromSerial: targetSerial,
-			ToSerial:   targetSerial,
-		},
-	}
-
-	// Check cache first
-	s.cacheMutex.RLock()
-	cached, exists := s.explosionCache[cacheKey]
-	s.cacheMutex.RUnlock()
-
-	if exists {
-		// Scale cached quantities by the current demand quantity
-		var scaledRequirements []*entities.GrossRequirement
-		for _, req := range cached.Requirements {
-			scaledReq := &entities.GrossRequirement{
-				PartNumber:   req.PartNumber,
-				Quantity:     req.Quantity * quantity,
-				NeedDate:     needDate.Add(-time.Duration(cached.LeadTimeDays) * 24 * time.Hour),
-				DemandTrace:  demandTrace + " -> " + req.DemandTrace,
-				Location:     location,
-				TargetSerial: req.TargetSerial,
-			}
-			scaledRequirements = append(scaledRequirements, scaledReq)
+	indexes := make([]int, len(demands))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	perDemand := make([][]*entities.GrossRequirement, len(demands))
+	var progressMutex sync.Mutex
+	exploded := 0
+
+	explode := func(ctx context.Context, i int) error {
+		demand := demands[i]
+		grossReqs, err := s.explodeRequirements(
+			ctx,
+			demand.PartNumber,
+			demand.TargetSerial,
+			demand.NeedDate,
+			demand.DemandSource,
+			demand.Location,
+			demand.Quantity,
+			keyByDate,
+			bomRepo,
+			itemRepo,
+			inventoryRepo,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to explode demand for %s: %w", demand.PartNumber, err)
 		}
-		return scaledRequirements, nil
+		perDemand[i] = grossReqs
+
+		progressMutex.Lock()
+		exploded++
+		s.reportProgress(StageExplode, exploded, len(demands))
+		progressMutex.Unlock()
+		return nil
 	}
 
-	// Use BOMTraverser with MRPVisitor to perform the explosion
-	bomTraverser := shared.NewBOMTraverser(bomRepo, itemRepo, inventoryRepo)
-	visitor := NewMRPVisitor(demandTrace, needDate)
-	result, err := bomTraverser.TraverseBOM(ctx, pn, targetSerial, location, quantity, 0, visitor)
-	if err != nil {
-		return nil, fmt.Errorf("failed to traverse BOM for %s: %w", pn, err)
+	s.reportProgress(StageExplode, 0, len(demands))
+	if err := s.runWorkers(ctx, indexes, explode); err != nil {
+		return nil, err
 	}
 
-	requirements := result.([]*entities.GrossRequirement)
+	total := 0
+	for _, grossReqs := range perDemand {
+		total += len(grossReqs)
+	}
+	allGrossRequirements := make([]*entities.GrossRequirement, 0, total)
+	for _, grossReqs := range perDemand {
+		allGrossRequirements = append(allGrossRequirements, grossReqs...)
+	}
+	return allGrossRequirements, nil
+}
 
-	// Get item master data for caching
-	item, err := itemRepo.GetItem(pn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get item %s: %w", pn, err)
+// runWorkers calls work for each index on up to config.Concurrency goroutines. The first
+// failure cancels the remaining work, and is returned rather than the cancellations it caused.
+func (s *MRPService) runWorkers(
+	ctx context.Context,
+	indexes []int,
+	work func(ctx context.Context, i int) error,
+) error {
+	workers := s.config.Concurrency
+	if workers > len(indexes) {
+		workers = len(indexes)
+	}
+	if workers <= 1 {
+		for _, i := range indexes {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := work(ctx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	errs := make([]error, len(indexes))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[j] = err
+					continue
+				}
+				if err := work(ctx, indexes[j]); err != nil {
+					errs[j] = err
+					cancel()
+				}
+			}
+		}()
 	}
 
-	// Cache the base requirements (without scaling)
-	baseRequirements := make([]entities.GrossRequirement, len(requirements))
-	for i, req := range requirements {
-		baseRequirements[i] = entities.GrossRequirement{
-			PartNumber:   req.PartNumber,
-			Quantity:     req.Quantity / quantity, // Scale back to unit quantity
-			NeedDate:     req.NeedDate,
-			DemandTrace:  string(req.PartNumber), // Generic trace for caching
-			Location:     req.Location,
-			TargetSerial: req.TargetSerial,
+	for j := range indexes {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	var cancelled error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case !errors.Is(err, context.Canceled):
+			return err
+		case cancelled == nil:
+			cancelled = err
 		}
 	}
+	return cancelled
+}
 
-	explosionResult := &dto.ExplosionResult{
-		Requirements: baseRequirements,
-		LeadTimeDays: item.LeadTimeDays,
-		ComputedAt:   time.Now(),
+// explosionCacheKey returns the memoization key for exploding a part for one serial of an end
+// item and date
+func explosionCacheKey(
+	pn entities.PartNumber,
+	endItem entities.PartNumber,
+	targetSerial string,
+	needDate time.Time,
+) dto.ExplosionCacheKey {
+	return dto.ExplosionCacheKey{
+		PartNumber:   pn,
+		EndItem:      endItem,
+		TargetSerial: targetSerial,
+		NeedDate:     needDate,
 	}
+}
 
-	// Store in cache
-	s.cacheMutex.Lock()
-	s.explosionCache[cacheKey] = explosionResult
-	s.cacheMutex.Unlock()
+// hasDateEffectivity reports whether any BOM line is date effective; only then does the need
+// date of a part change its explosion
+func hasDateEffectivity(bomRepo repositories.BOMRepository) (bool, error) {
+	lines, err := bomRepo.GetAllBOMLines()
+	if err != nil {
+		return false, fmt.Errorf("failed to get BOM lines: %w", err)
+	}
+	for _, line := range lines {
+		if !line.DateEffectivity.FromDate.IsZero() || !line.DateEffectivity.ToDate.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	return requirements, nil
+// explodeRequirements explodes a part's BOM using BOMTraverser. The explosion below every
+// part is memoized per unit, with need dates relative to the part's, so it is reused for any
+// quantity, location and need date (for any need date only when keyByDate is false).
+func (s *MRPService) explodeRequirements(
+	ctx context.Context,
+	pn entities.PartNumber,
+	targetSerial string,
+	needDate time.Time,
+	demandTrace string,
+	location string,
+	quantity entities.Quantity,
+	keyByDate bool,
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+) ([]*entities.GrossRequirement, error) {
+	bomTraverser := shared.NewBOMTraverser(bomRepo, itemRepo, inventoryRepo)
+	visitor := NewMRPVisitor(demandTrace, needDate)
+	visitor.cache = s.explosionCache
+	visitor.keyByDate = keyByDate
+
+	result, err := bomTraverser.TraverseBOM(
+		ctx,
+		pn,
+		targetSerial,
+		location,
+		quantity,
+		needDate,
+		0,
+		visitor,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse BOM for %s: %w", pn, err)
+	}
+
+	return result.([]*entities.GrossRequirement), nil
 }
 
-// allocateInventory allocates available inventory against gross requirements
+// allocateInventory allocates available inventory against gross requirements, taking the
+// allocations of parts whose stock the explosion already drew from drawn
 func (s *MRPService) allocateInventory(
 	ctx context.Context,
 	grossReqs []*entities.GrossRequirement,
+	drawn map[string]*entities.AllocationResult,
 	inventoryRepo repositories.InventoryRepository,
 ) ([]entities.AllocationResult, []*entities.NetRequirement, error) {
 	var allocations []entities.AllocationResult
@@ -269,7 +458,13 @@ func (s *MRPService) allocateInventory(
 	}
 
 	// Process each group
-	for _, reqs := range reqGroups {
+	done := 0
+	s.reportProgress(StageNet, done, len(reqGroups))
+	for key, reqs := range reqGroups {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		done++
 		if len(reqs) == 0 {
 			continue
 		}
@@ -281,17 +476,21 @@ func (s *MRPService) allocateInventory(
 		}
 
 		// Try to allocate inventory
-		allocation, err := inventoryRepo.AllocateInventory(
-			firstReq.PartNumber,
-			firstReq.Location,
-			totalQty,
-		)
-		if err != nil {
-			return nil, nil, fmt.Errorf(
-				"failed to allocate inventory for %s: %w",
+		allocation, exists := drawn[key]
+		if !exists {
+			var err error
+			allocation, err = inventoryRepo.AllocateInventory(
 				firstReq.PartNumber,
-				err,
+				firstReq.Location,
+				totalQty,
 			)
+			if err != nil {
+				return nil, nil, fmt.Errorf(
+					"failed to allocate inventory for %s: %w",
+					firstReq.PartNumber,
+					err,
+				)
+			}
 		}
 
 		allocations = append(allocations, *allocation)
@@ -324,6 +523,7 @@ func (s *MRPService) allocateInventory(
 				}
 			}
 		}
+		s.reportProgress(StageNet, done, len(reqGroups))
 	}
 
 	return allocations, netRequirements, nil
@@ -334,30 +534,14 @@ func (s *MRPService) applyLotSizing(
 	netQty entities.Quantity,
 	item *entities.Item,
 ) entities.Quantity {
-	switch item.LotSizeRule {
-	case entities.LotForLot:
-		return netQty
-	case entities.MinimumQty:
-		if netQty < item.MinOrderQty {
-			return item.MinOrderQty
-		}
-		return netQty
-	case entities.StandardPack:
-		// Round up to nearest standard pack size (using MinOrderQty as pack size)
-		if item.MinOrderQty > 0 {
-			packs := (netQty + item.MinOrderQty - 1) / item.MinOrderQty
-			return packs * item.MinOrderQty
-		}
-		return netQty
-	default:
-		return netQty
-	}
+	return item.LotSize(netQty)
 }
 
-// identifyShortages identifies unfulfilled demand
+// identifyShortages identifies unfulfilled demand (phantoms are supplied by their children)
 func (s *MRPService) identifyShortages(
 	netReqs []*entities.NetRequirement,
 	orders []entities.PlannedOrder,
+	depGraph DependencyGraph,
 ) []entities.Shortage {
 	var shortages []entities.Shortage
 
@@ -371,6 +555,9 @@ func (s *MRPService) identifyShortages(
 	// Check each net requirement against planned orders
 	reqMap := make(map[string]entities.Quantity)
 	for _, netReq := range netReqs {
+		if node, exists := depGraph[netReq.PartNumber]; exists && node.Item.Phantom {
+			continue
+		}
 		key := fmt.Sprintf("%s|%s", netReq.PartNumber, netReq.Location)
 		reqMap[key] += netReq.Quantity
 	}
@@ -405,18 +592,32 @@ func (s *MRPService) identifyShortages(
 	return shortages
 }
 
-// buildDependencyGraph constructs a dependency graph from gross requirements and BOM structure
+// buildDependencyGraph constructs a dependency graph from gross requirements and BOM structure,
+// resolving effectivity for targetSerial, a serial of endItem
 func (s *MRPService) buildDependencyGraph(
 	ctx context.Context,
 	grossRequirements []*entities.GrossRequirement,
 	bomRepo repositories.BOMRepository,
 	itemRepo repositories.ItemRepository,
+	endItem entities.PartNumber,
 	targetSerial string,
 ) (DependencyGraph, error) {
 	depGraph := make(DependencyGraph)
 
+	// Distinct need dates per part, used to evaluate date effectivity
+	needDates := make(map[entities.PartNumber][]time.Time)
+	seenNeedDate := make(map[entities.PartNumber]map[int64]bool)
+
 	// Create nodes for all parts from gross requirements
 	for _, req := range grossRequirements {
+		if seenNeedDate[req.PartNumber] == nil {
+			seenNeedDate[req.PartNumber] = make(map[int64]bool)
+		}
+		if key := req.NeedDate.UnixNano(); !seenNeedDate[req.PartNumber][key] {
+			seenNeedDate[req.PartNumber][key] = true
+			needDates[req.PartNumber] = append(needDates[req.PartNumber], req.NeedDate)
+		}
+
 		if _, exists := depGraph[req.PartNumber]; !exists {
 			item, err := itemRepo.GetItem(req.PartNumber)
 			if err != nil {
@@ -430,6 +631,8 @@ func (s *MRPService) buildDependencyGraph(
 				Level:          0, // Will be calculated later
 				DirectChildren: []entities.PartNumber{},
 				DirectParents:  []entities.PartNumber{},
+
+				ChildLeadTimeOffsets: make(map[entities.PartNumber]int),
 			}
 		} else {
 			// Accumulate quantities if part appears multiple times
@@ -437,41 +640,64 @@ func (s *MRPService) buildDependencyGraph(
 		}
 	}
 
-	// Build parent-child relationships by querying BOM for each part
+	// Build parent-child relationships by querying BOM for each part. A find number whose
+	// alternates are split by date has a child for every date the part is needed on, so the
+	// parent waits for each alternate the explosion uses.
 	for partNumber := range depGraph {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		alternateGroups, err := bomRepo.GetAlternateGroups(partNumber)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get alternate groups for %s: %w", partNumber, err)
 		}
 
-		for findNumber := range alternateGroups {
-			effectiveAlternates, err := bomRepo.GetEffectiveAlternates(
-				partNumber,
-				findNumber,
-				targetSerial,
-			)
-			if err != nil {
-				return nil, fmt.Errorf(
-					"failed to get effective alternates for %s find %d: %w",
+		for findNumber, group := range alternateGroups {
+			dates := needDates[partNumber]
+			if !groupHasDateEffectivity(group) && len(dates) > 1 {
+				dates = dates[:1] // Every date selects the same alternate
+			}
+
+			linked := make(map[entities.PartNumber]bool)
+			for _, needDate := range dates {
+				effectiveAlternates, err := bomRepo.GetEffectiveAlternates(
 					partNumber,
 					findNumber,
-					err,
+					endItem,
+					targetSerial,
+					needDate,
 				)
-			}
+				if err != nil {
+					return nil, fmt.Errorf(
+						"failed to get effective alternates for %s find %d: %w",
+						partNumber,
+						findNumber,
+						err,
+					)
+				}
 
-			if len(effectiveAlternates) > 0 {
 				selectedAlternate := shared.SelectBestAlternateByPriority(effectiveAlternates)
-				if selectedAlternate != nil {
-					// Add parent-child relationship
-					depGraph[partNumber].DirectChildren = append(
-						depGraph[partNumber].DirectChildren,
-						selectedAlternate.ChildPN,
-					)
+				if selectedAlternate == nil || linked[selectedAlternate.ChildPN] {
+					continue
+				}
+				linked[selectedAlternate.ChildPN] = true
 
-					// Add child-parent relationship if child exists in graph
-					if childNode, exists := depGraph[selectedAlternate.ChildPN]; exists {
-						childNode.DirectParents = append(childNode.DirectParents, partNumber)
-					}
+				// Add parent-child relationship
+				parentNode := depGraph[partNumber]
+				parentNode.DirectChildren = append(
+					parentNode.DirectChildren,
+					selectedAlternate.ChildPN,
+				)
+
+				offset := selectedAlternate.LeadTimeOffsetDays
+				if existing, exists := parentNode.ChildLeadTimeOffsets[selectedAlternate.ChildPN]; !exists ||
+					offset < existing {
+					parentNode.ChildLeadTimeOffsets[selectedAlternate.ChildPN] = offset
+				}
+
+				// Add child-parent relationship if child exists in graph
+				if childNode, exists := depGraph[selectedAlternate.ChildPN]; exists {
+					childNode.DirectParents = append(childNode.DirectParents, partNumber)
 				}
 			}
 		}
@@ -483,6 +709,16 @@ func (s *MRPService) buildDependencyGraph(
 	return depGraph, nil
 }
 
+// groupHasDateEffectivity reports whether any line of an alternate group is limited to a date window
+func groupHasDateEffectivity(group []*entities.BOMLine) bool {
+	for _, line := range group {
+		if !line.DateEffectivity.FromDate.IsZero() || !line.DateEffectivity.ToDate.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
 // calculateBOMLevels assigns BOM levels to parts in the dependency graph
 func (s *MRPService) calculateBOMLevels(depGraph DependencyGraph) {
 	// Find all leaf parts (no children)
@@ -529,21 +765,27 @@ func (s *MRPService) calculateBOMLevels(depGraph DependencyGraph) {
 	}
 }
 
-// topologicalSort returns parts in dependency order (children before parents)
-func (s *MRPService) topologicalSort(depGraph DependencyGraph) []entities.PartNumber {
+// topologicalSort returns parts in dependency order (children before parents). Parts on a
+// cycle can never be ordered, so a cycle is returned as an *entities.CyclicBOMError.
+func (s *MRPService) topologicalSort(depGraph DependencyGraph) ([]entities.PartNumber, error) {
 	// Kahn's algorithm for topological sorting
 	inDegree := make(map[entities.PartNumber]int)
 	queue := make([]entities.PartNumber, 0)
 	result := make([]entities.PartNumber, 0, len(depGraph))
 
-	// Calculate in-degree for each node (number of dependencies)
-	for partNumber := range depGraph {
-		inDegree[partNumber] = len(depGraph[partNumber].DirectChildren)
+	// Calculate in-degree for each node (number of dependencies within the graph; children
+	// outside it never release their parent through DirectParents)
+	for partNumber, node := range depGraph {
+		for _, child := range node.DirectChildren {
+			if _, exists := depGraph[child]; exists {
+				inDegree[partNumber]++
+			}
+		}
 	}
 
 	// Add all nodes with no dependencies (leaf parts) to queue
-	for partNumber, degree := range inDegree {
-		if degree == 0 {
+	for partNumber := range depGraph {
+		if inDegree[partNumber] == 0 {
 			queue = append(queue, partNumber)
 		}
 	}
@@ -564,30 +806,72 @@ func (s *MRPService) topologicalSort(depGraph DependencyGraph) []entities.PartNu
 		}
 	}
 
-	// Check for cycles (shouldn't happen in a valid BOM)
 	if len(result) != len(depGraph) {
-		// Return what we have - dependency cycle detection could be added here
-		return result
+		return nil, &entities.CyclicBOMError{Cycle: findCycle(depGraph, inDegree)}
 	}
 
-	return result
+	return result, nil
 }
 
-// scheduleForward performs forward scheduling based on dependency graph and inventory allocation
+// findCycle returns a cycle among the parts Kahn's algorithm could not order (those left
+// with a positive in-degree). Each of them has a child that is also unordered, so following
+// such children must eventually revisit a part.
+func findCycle(depGraph DependencyGraph, inDegree map[entities.PartNumber]int) []entities.PartNumber {
+	var start entities.PartNumber
+	for partNumber, degree := range inDegree {
+		if degree > 0 && (start == "" || partNumber < start) {
+			start = partNumber
+		}
+	}
+
+	position := make(map[entities.PartNumber]int)
+	var path []entities.PartNumber
+	for current := start; ; {
+		if i, seen := position[current]; seen {
+			return append(path[i:], current)
+		}
+		position[current] = len(path)
+		path = append(path, current)
+
+		for _, child := range depGraph[current].DirectChildren {
+			if inDegree[child] > 0 {
+				current = child
+				break
+			}
+		}
+	}
+}
+
+// scheduleForward performs forward scheduling based on dependency graph and inventory allocation.
+// It also returns, for each part whose start waits on a child, the child it waits on. Planning
+// time fences are measured from now, the time of the run, and nothing starts before it.
 func (s *MRPService) scheduleForward(
+	ctx context.Context,
 	sortedParts []entities.PartNumber,
 	depGraph DependencyGraph,
 	allocations []entities.AllocationResult,
+	receiptPegs []receiptPeg,
 	netRequirements []*entities.NetRequirement,
-) ([]entities.PlannedOrder, error) {
+	now time.Time,
+) ([]entities.PlannedOrder, map[entities.PartNumber]entities.PartNumber, []entities.ActionMessage, error) {
 	var allOrders []entities.PlannedOrder
+	var fenceMessages []entities.ActionMessage
 	completionTimes := make(map[entities.PartNumber]time.Time)
+	gatedBy := make(map[entities.PartNumber]entities.PartNumber)
 
 	// Initialize completion times for parts with full inventory allocation
 	for _, allocation := range allocations {
 		if allocation.RemainingDemand == 0 {
 			// Part is fully satisfied by inventory - available immediately
-			completionTimes[allocation.PartNumber] = time.Now()
+			completionTimes[allocation.PartNumber] = now
+		}
+	}
+
+	// Parts covered by scheduled receipts are available once the receipts arrive
+	for _, peg := range receiptPegs {
+		partNumber := peg.receipt.PartNumber
+		if peg.pegged > 0 && peg.receipt.DueDate.After(completionTimes[partNumber]) {
+			completionTimes[partNumber] = peg.receipt.DueDate
 		}
 	}
 
@@ -610,17 +894,36 @@ func (s *MRPService) scheduleForward(
 	}
 
 	// Schedule parts in dependency order
-	for _, partNumber := range sortedParts {
+	for i, partNumber := range sortedParts {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		s.reportProgress(StageSchedule, i, len(sortedParts))
 		node := depGraph[partNumber]
 		netReq := netReqMap[partNumber]
 
+		// Phantoms are blown through: no order, and parents wait only on the phantom's children
+		if node.Item.Phantom {
+			if netReq != nil && netReq.Quantity > 0 {
+				completion, gatingChild := s.calculateEarliestStartTime(node, completionTimes, now)
+				completionTimes[partNumber] = completion
+				if gatingChild != "" {
+					gatedBy[partNumber] = gatingChild
+				}
+			}
+			continue
+		}
+
 		// Skip parts that don't need production (fully covered by inventory)
 		if netReq == nil || netReq.Quantity <= 0 {
 			continue
 		}
 
 		// Calculate earliest start time based on when direct children complete
-		earliestStart := s.calculateEarliestStartTime(node, completionTimes)
+		earliestStart, gatingChild := s.calculateEarliestStartTime(node, completionTimes, now)
+		if gatingChild != "" {
+			gatedBy[partNumber] = gatingChild
+		}
 
 		// Apply lot sizing to net requirements
 		orderQty := s.applyLotSizing(netReq.Quantity, node.Item)
@@ -636,46 +939,88 @@ func (s *MRPService) scheduleForward(
 			orderType = entities.Make // Default fallback
 		}
 
+		// No new order falls due inside the planning time fence; it is planned at the fence,
+		// and if that makes it late the planner is asked to firm an earlier one
+		fenced := false
+		if node.Item.PlanningTimeFenceDays > 0 {
+			leadTime := time.Duration(node.Item.PlanningLeadTimeDays()) * 24 * time.Hour
+			fence := now.AddDate(0, 0, node.Item.PlanningTimeFenceDays)
+			if earliestStart.Add(leadTime).Before(fence) {
+				earliestStart = fence.Add(-leadTime)
+				fenced = true
+			}
+		}
+
 		// Split orders if they exceed max order quantity and schedule sequentially
 		partOrders := s.splitOrderByMaxQtyForward(orderQty, node.Item, netReq, orderType, earliestStart)
 		allOrders = append(allOrders, partOrders...)
+		if fenced && len(partOrders) > 0 && partOrders[0].DueDate.After(netReq.NeedDate) {
+			fenceMessages = append(fenceMessages, entities.ActionMessage{
+				Action:     entities.ActionFirm,
+				PartNumber: partNumber,
+				Location:   netReq.Location,
+				Quantity:   orderQty,
+				DueDate:    partOrders[0].DueDate,
+				NeedDate:   netReq.NeedDate,
+				Days:       shared.DaysBetween(shared.DateOf(netReq.NeedDate), shared.DateOf(partOrders[0].DueDate)),
+			})
+		}
 
 		// Record completion time for this part (when last order completes)
 		if len(partOrders) > 0 {
 			latestCompletion := partOrders[len(partOrders)-1].DueDate
-			completionTimes[partNumber] = latestCompletion
+			if latestCompletion.After(completionTimes[partNumber]) {
+				completionTimes[partNumber] = latestCompletion
+			}
 		}
 	}
+	s.reportProgress(StageSchedule, len(sortedParts), len(sortedParts))
 
-	return allOrders, nil
+	return allOrders, gatedBy, fenceMessages, nil
 }
 
-// calculateEarliestStartTime determines when a part can start based on child completion times
+// calculateEarliestStartTime determines when a part can start based on child completion times,
+// and which child holds it up (empty when it can start now, the time of the run)
 func (s *MRPService) calculateEarliestStartTime(
 	node *DependencyNode,
 	completionTimes map[entities.PartNumber]time.Time,
-) time.Time {
+	now time.Time,
+) (time.Time, entities.PartNumber) {
 	if len(node.DirectChildren) == 0 {
 		// Leaf part - can start immediately (or based on material availability)
-		return time.Now()
+		return now, ""
 	}
 
-	// Find latest completion time among direct children
-	var latestChildCompletion time.Time
+	// Find the latest start allowed by direct children. A child needed N days into the
+	// parent's build only has to complete N days after the parent starts.
+	leadTimeDays := node.Item.PlanningLeadTimeDays()
+	var latestChildConstraint time.Time
+	var gatingChild entities.PartNumber
 	for _, childPN := range node.DirectChildren {
 		if childCompletion, exists := completionTimes[childPN]; exists {
-			if childCompletion.After(latestChildCompletion) {
-				latestChildCompletion = childCompletion
+			offsetDays := node.ChildLeadTimeOffsets[childPN]
+			if offsetDays > leadTimeDays {
+				offsetDays = leadTimeDays // A child can't be needed after the parent finishes
+			}
+			constraint := childCompletion.Add(-time.Duration(offsetDays) * 24 * time.Hour)
+			if constraint.After(latestChildConstraint) {
+				latestChildConstraint = constraint
+				gatingChild = childPN
 			}
 		}
 	}
 
 	// If no children have completion times yet, start immediately
-	if latestChildCompletion.IsZero() {
-		return time.Now()
+	if latestChildConstraint.IsZero() {
+		return now, ""
 	}
 
-	return latestChildCompletion
+	// Offsets can pull the start before today, but nothing can start in the past
+	if latestChildConstraint.Before(now) {
+		return now, ""
+	}
+
+	return latestChildConstraint, gatingChild
 }
 
 // splitOrderByMaxQtyForward splits orders with forward scheduling starting from earliest start time
@@ -688,8 +1033,9 @@ func (s *MRPService) splitOrderByMaxQtyForward(
 ) []entities.PlannedOrder {
 	var orders []entities.PlannedOrder
 
-	// If quantity is within max limit, create single order
-	if totalQty <= item.MaxOrderQty {
+	// If quantity is within max limit (or there is no limit), create single order. Without the
+	// MaxOrderQty <= 0 check an item with no max would split into orders of 0 forever.
+	if item.MaxOrderQty <= 0 || totalQty <= item.MaxOrderQty {
 		dueDate := earliestStart.Add(time.Duration(item.LeadTimeDays) * 24 * time.Hour)
 		order, err := entities.NewPlannedOrder(
 			netReq.PartNumber,
@@ -750,28 +1096,3 @@ func (s *MRPService) splitOrderByMaxQtyForward(
 
 	return orders
 }
-
-// cleanCacheIfNeeded removes old cache entries if cache size exceeds limit
-func (s *MRPService) cleanCacheIfNeeded() {
-	if s.config.MaxCacheEntries <= 0 {
-		return
-	}
-
-	s.cacheMutex.Lock()
-	defer s.cacheMutex.Unlock()
-
-	if len(s.explosionCache) > s.config.MaxCacheEntries {
-		// Simple LRU eviction - remove oldest entries
-		var oldestTime time.Time
-		var oldestKey dto.ExplosionCacheKey
-
-		for key, value := range s.explosionCache {
-			if oldestTime.IsZero() || value.ComputedAt.Before(oldestTime) {
-				oldestTime = value.ComputedAt
-				oldestKey = key
-			}
-		}
-
-		delete(s.explosionCache, oldestKey)
-	}
-}
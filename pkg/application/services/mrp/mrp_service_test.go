@@ -2,12 +2,16 @@ package mrp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/shared"
 	testhelpers "github.com/vsinha/mrp/pkg/application/services/testing"
 	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/services"
 	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
 )
 
@@ -80,6 +84,45 @@ func TestMRPService_ExplodeDemand_SingleLevel(t *testing.T) {
 	}
 }
 
+func TestMRPService_ExplodeDemand_RunDate(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testhelpers.BuildSimpleTestData()
+
+	// Plan a year ahead of the wall clock, as an as-of run would
+	runDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := newTestMRPService()
+	service.SetRunDate(runDate)
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     entities.Quantity(1),
+			NeedDate:     runDate.AddDate(0, 3, 0),
+			DemandSource: "TEST_ORDER",
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		},
+	}
+
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	starts := make(map[entities.PartNumber]time.Time)
+	for _, order := range result.PlannedOrders {
+		starts[order.PartNumber] = order.StartDate
+	}
+	if !starts["COMPONENT_A"].Equal(runDate) {
+		t.Errorf("Expected the component to start on the run date %s, got %s",
+			runDate.Format("2006-01-02"), starts["COMPONENT_A"].Format("2006-01-02"))
+	}
+	if want := runDate.AddDate(0, 0, 15); !starts["ASSEMBLY_A"].Equal(want) {
+		t.Errorf("Expected the assembly to start when the component arrives on %s, got %s",
+			want.Format("2006-01-02"), starts["ASSEMBLY_A"].Format("2006-01-02"))
+	}
+}
+
 func TestMRPService_ExplodeDemand_SerialEffectivity(t *testing.T) {
 	ctx := context.Background()
 
@@ -975,3 +1018,1139 @@ func TestMRPService_ForwardScheduling_ParallelBranches(t *testing.T) {
 		t.Errorf("Root assembly should start when critical path (Branch B) completes")
 	}
 }
+
+func TestMRPService_PhantomAssembly(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	items := []*entities.Item{
+		{
+			PartNumber:    "SATURN_V",
+			Description:   "Saturn V Launch Vehicle",
+			LeadTimeDays:  30,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		},
+		{
+			PartNumber:    "AVIONICS_PACKAGE",
+			Description:   "Avionics Kit",
+			LeadTimeDays:  90, // Ignored because the kit is a phantom
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+			Phantom:       true,
+		},
+		{
+			PartNumber:    "GUIDANCE_UNIT",
+			Description:   "Guidance Unit",
+			LeadTimeDays:  20,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		},
+	}
+	for _, item := range items {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	bomLines := []*entities.BOMLine{
+		{
+			ParentPN:    "SATURN_V",
+			ChildPN:     "AVIONICS_PACKAGE",
+			QtyPer:      entities.Quantity(1),
+			FindNumber:  400,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""},
+		},
+		{
+			ParentPN:    "AVIONICS_PACKAGE",
+			ChildPN:     "GUIDANCE_UNIT",
+			QtyPer:      entities.Quantity(1),
+			FindNumber:  100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""},
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	// One phantom kit is already on the shelf and should be consumed
+	lot, err := entities.NewInventoryLot(
+		"AVIONICS_PACKAGE",
+		"KIT_LOT_001",
+		"KSC",
+		entities.Quantity(1),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		entities.Available,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create inventory lot: %v", err)
+	}
+	if err := inventoryRepo.SaveInventoryLot(lot); err != nil {
+		t.Fatalf("Failed to save inventory lot: %v", err)
+	}
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "SATURN_V",
+			Quantity:     entities.Quantity(2),
+			NeedDate:     time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_PROGRAM",
+			Location:     "KSC",
+			TargetSerial: "SA506",
+		},
+	}
+
+	service := newTestMRPService()
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	var vehicleOrder, guidanceOrder *entities.PlannedOrder
+	for i := range result.PlannedOrders {
+		switch result.PlannedOrders[i].PartNumber {
+		case "AVIONICS_PACKAGE":
+			t.Errorf("Expected no planned order for phantom, got %+v", result.PlannedOrders[i])
+		case "SATURN_V":
+			vehicleOrder = &result.PlannedOrders[i]
+		case "GUIDANCE_UNIT":
+			guidanceOrder = &result.PlannedOrders[i]
+		}
+	}
+	if vehicleOrder == nil || guidanceOrder == nil {
+		t.Fatalf("Expected orders for parent and phantom's child, got %+v", result.PlannedOrders)
+	}
+
+	// The kit on the shelf covers one vehicle, so guidance units are only needed for the other
+	if guidanceOrder.Quantity != 1 {
+		t.Errorf("Expected 1 GUIDANCE_UNIT ordered, got %d", guidanceOrder.Quantity)
+	}
+
+	// Phantom has zero lead time: the parent starts as soon as the phantom's children complete
+	if !vehicleOrder.StartDate.Equal(guidanceOrder.DueDate) {
+		t.Errorf("Expected SATURN_V to start at GUIDANCE_UNIT due date %v, got %v",
+			guidanceOrder.DueDate, vehicleOrder.StartDate)
+	}
+
+	// On-hand phantom stock is consumed
+	foundPhantomAllocation := false
+	for _, alloc := range result.Allocations {
+		if alloc.PartNumber == "AVIONICS_PACKAGE" {
+			foundPhantomAllocation = true
+			if alloc.AllocatedQty != 1 {
+				t.Errorf("Expected 1 phantom kit allocated, got %d", alloc.AllocatedQty)
+			}
+		}
+	}
+	if !foundPhantomAllocation {
+		t.Error("Expected allocation record for phantom stock")
+	}
+
+	// The remaining phantom requirement is not a shortage
+	for _, shortage := range result.ShortageReport {
+		if shortage.PartNumber == "AVIONICS_PACKAGE" {
+			t.Errorf("Expected no shortage for phantom, got %+v", shortage)
+		}
+	}
+}
+
+func TestMRPService_ForwardScheduling_LeadTimeOffset(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	items := []*entities.Item{
+		{
+			PartNumber:    "S_IC_STAGE",
+			Description:   "S-IC First Stage",
+			LeadTimeDays:  240,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(5),
+			UnitOfMeasure: "EA",
+		},
+		{
+			PartNumber:    "F1_NOZZLE",
+			Description:   "F-1 Nozzle Extension",
+			LeadTimeDays:  60,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		},
+	}
+	for _, item := range items {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// The nozzle is only needed 40 days into the stage build
+	bomLine := &entities.BOMLine{
+		ParentPN:           "S_IC_STAGE",
+		ChildPN:            "F1_NOZZLE",
+		QtyPer:             entities.Quantity(5),
+		FindNumber:         100,
+		Effectivity:        entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""},
+		LeadTimeOffsetDays: 40,
+	}
+	if err := bomRepo.SaveBOMLine(bomLine); err != nil {
+		t.Fatalf("Failed to save BOM line: %v", err)
+	}
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "S_IC_STAGE",
+			Quantity:     entities.Quantity(1),
+			NeedDate:     time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_11",
+			Location:     "MICHOUD",
+			TargetSerial: "SA506",
+		},
+	}
+
+	service := newTestMRPService()
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	var stageOrder, nozzleOrder *entities.PlannedOrder
+	for i := range result.PlannedOrders {
+		switch result.PlannedOrders[i].PartNumber {
+		case "S_IC_STAGE":
+			stageOrder = &result.PlannedOrders[i]
+		case "F1_NOZZLE":
+			nozzleOrder = &result.PlannedOrders[i]
+		}
+	}
+	if stageOrder == nil || nozzleOrder == nil {
+		t.Fatalf("Expected orders for stage and nozzle, got %+v", result.PlannedOrders)
+	}
+
+	// Stage starts 40 days before the nozzle completes, so the two builds overlap
+	expectedStageStart := nozzleOrder.DueDate.Add(-40 * 24 * time.Hour)
+	if !stageOrder.StartDate.Equal(expectedStageStart) {
+		t.Errorf("Expected stage start %v (nozzle due - 40 days), got %v",
+			expectedStageStart, stageOrder.StartDate)
+	}
+	if !stageOrder.StartDate.Before(nozzleOrder.DueDate) {
+		t.Errorf("Expected stage build to overlap nozzle build")
+	}
+}
+
+func TestMRPService_DateEffectivity(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	for _, pn := range []entities.PartNumber{"F1_ENGINE", "F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"} {
+		item := &entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  30,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		}
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// The V2 turbopump cuts in for anything needed from 2027 onward, for every serial
+	cutIn := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	allSerials := entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""}
+	bomLines := []*entities.BOMLine{
+		{
+			ParentPN:        "F1_ENGINE",
+			ChildPN:         "F1_TURBOPUMP_V1",
+			QtyPer:          entities.Quantity(1),
+			FindNumber:      100,
+			Effectivity:     allSerials,
+			DateEffectivity: entities.DateEffectivity{ToDate: cutIn.AddDate(0, 0, -1)},
+		},
+		{
+			ParentPN:        "F1_ENGINE",
+			ChildPN:         "F1_TURBOPUMP_V2",
+			QtyPer:          entities.Quantity(1),
+			FindNumber:      100,
+			Effectivity:     allSerials,
+			DateEffectivity: entities.DateEffectivity{FromDate: cutIn},
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     entities.Quantity(2),
+			NeedDate:     time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "SA506",
+		},
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     entities.Quantity(3),
+			NeedDate:     time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC),
+			DemandSource: "APOLLO_12",
+			Location:     "KSC",
+			TargetSerial: "SA507",
+		},
+	}
+
+	service := newTestMRPService()
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	ordered := make(map[entities.PartNumber]entities.Quantity)
+	for _, order := range result.PlannedOrders {
+		ordered[order.PartNumber] += order.Quantity
+	}
+
+	if ordered["F1_TURBOPUMP_V1"] != 2 {
+		t.Errorf("Expected 2 V1 turbopumps for the 2026 demand, got %d", ordered["F1_TURBOPUMP_V1"])
+	}
+	if ordered["F1_TURBOPUMP_V2"] != 3 {
+		t.Errorf("Expected 3 V2 turbopumps for the 2027 demand, got %d", ordered["F1_TURBOPUMP_V2"])
+	}
+
+	// The engines are needed on both sides of the cut-in, so they wait for both turbopumps
+	var grossRequirements []*entities.GrossRequirement
+	for _, demand := range demands {
+		grossRequirements = append(grossRequirements, &entities.GrossRequirement{
+			PartNumber:   demand.PartNumber,
+			Quantity:     demand.Quantity,
+			NeedDate:     demand.NeedDate,
+			DemandTrace:  demand.DemandSource,
+			Location:     demand.Location,
+			TargetSerial: demand.TargetSerial,
+		})
+	}
+	depGraph, err := service.buildDependencyGraph(ctx, grossRequirements, bomRepo, itemRepo, "F1_ENGINE", "SA506")
+	if err != nil {
+		t.Fatalf("buildDependencyGraph failed: %v", err)
+	}
+	if children := fmt.Sprint(depGraph["F1_ENGINE"].DirectChildren); children != "[F1_TURBOPUMP_V1 F1_TURBOPUMP_V2]" {
+		t.Errorf("Expected both turbopumps as children of F1_ENGINE, got %s", children)
+	}
+}
+
+func TestMRPService_ExplodeDemand_EndItemSerialScheme(t *testing.T) {
+	ctx := context.Background()
+
+	// Only the end item has the dashed scheme; effectivity on lines below it uses its serials
+	saturn, err := services.NewSerialScheme("saturn", `SA-`, `[A-Z]?`, []string{"SATURN_V"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+	serialComp, err := services.NewSerialComparatorWithSchemes([]*services.SerialScheme{saturn})
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+
+	bomRepo := memory.NewBOMRepository(4)
+	bomRepo.SetSerialComparator(serialComp)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	for _, pn := range []entities.PartNumber{"SATURN_V", "S_IC", "F1_ENGINE", "F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"} {
+		item := &entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  10,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   entities.Quantity(1),
+			MaxOrderQty:   entities.Quantity(10),
+			UnitOfMeasure: "EA",
+		}
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// SA-99 sorts before SA-100 only under the end item's scheme
+	allSerials := entities.SerialEffectivity{FromSerial: "SA-1"}
+	bomLines := []*entities.BOMLine{
+		{ParentPN: "SATURN_V", ChildPN: "S_IC", QtyPer: 1, FindNumber: 100, Effectivity: allSerials},
+		{ParentPN: "S_IC", ChildPN: "F1_ENGINE", QtyPer: 5, FindNumber: 100, Effectivity: allSerials},
+		{
+			ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V1", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA-1", ToSerial: "SA-99"},
+		},
+		{
+			ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V2", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA-100"},
+		},
+	}
+	for _, line := range bomLines {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+	if err := serialComp.ValidateBOMSerials(bomLines); err != nil {
+		t.Fatalf("Expected end item serials on sub-assembly lines to be valid, got: %v", err)
+	}
+	if err := serialComp.ValidateSerialEffectivity(bomLines); err != nil {
+		t.Fatalf("Expected SA-1..SA-99 and SA-100.. not to overlap, got: %v", err)
+	}
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "SATURN_V",
+			Quantity:     entities.Quantity(1),
+			NeedDate:     time.Now().AddDate(0, 6, 0),
+			DemandSource: "APOLLO_11",
+			Location:     "KSC",
+			TargetSerial: "SA-506",
+		},
+	}
+
+	service := newTestMRPService()
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	ordered := make(map[entities.PartNumber]entities.Quantity)
+	for _, order := range result.PlannedOrders {
+		ordered[order.PartNumber] += order.Quantity
+	}
+	if ordered["F1_TURBOPUMP_V2"] != 5 {
+		t.Errorf("Expected 5 V2 turbopumps for SA-506, got %d", ordered["F1_TURBOPUMP_V2"])
+	}
+	if ordered["F1_TURBOPUMP_V1"] != 0 {
+		t.Errorf("Expected no V1 turbopumps for SA-506, got %d", ordered["F1_TURBOPUMP_V1"])
+	}
+}
+
+func TestMRPService_ExplodeDemand_CyclicBOM(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testhelpers.BuildSimpleTestData()
+
+	// COMPONENT_A -> SUBCOMPONENT_B -> COMPONENT_A
+	if err := itemRepo.SaveItem(&entities.Item{
+		PartNumber:    "SUBCOMPONENT_B",
+		Description:   "Test Subcomponent B",
+		LeadTimeDays:  10,
+		LotSizeRule:   entities.LotForLot,
+		MinOrderQty:   1,
+		MaxOrderQty:   100,
+		UnitOfMeasure: "EA",
+	}); err != nil {
+		t.Fatalf("Failed to save item: %v", err)
+	}
+	for _, line := range []*entities.BOMLine{
+		{ParentPN: "COMPONENT_A", ChildPN: "SUBCOMPONENT_B", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+		{ParentPN: "SUBCOMPONENT_B", ChildPN: "COMPONENT_A", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+	} {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     1,
+			NeedDate:     time.Now().Add(90 * 24 * time.Hour),
+			DemandSource: "TEST_ORDER",
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		},
+	}
+
+	service := newTestMRPService()
+	_, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+
+	var cyclic *entities.CyclicBOMError
+	if !errors.As(err, &cyclic) {
+		t.Fatalf("Expected a CyclicBOMError, got %v", err)
+	}
+	expected := []entities.PartNumber{"COMPONENT_A", "SUBCOMPONENT_B", "COMPONENT_A"}
+	if fmt.Sprint(cyclic.Cycle) != fmt.Sprint(expected) {
+		t.Errorf("Expected cycle %v, got %v", expected, cyclic.Cycle)
+	}
+}
+
+func TestMRPService_TopologicalSort(t *testing.T) {
+	node := func(pn entities.PartNumber, children, parents []entities.PartNumber) *DependencyNode {
+		return &DependencyNode{PartNumber: pn, DirectChildren: children, DirectParents: parents}
+	}
+	service := newTestMRPService()
+
+	// ENGINE's NOZZLE is not in the graph; ENGINE must still be ordered after TURBOPUMP
+	graph := DependencyGraph{
+		"ENGINE":    node("ENGINE", []entities.PartNumber{"TURBOPUMP", "NOZZLE"}, nil),
+		"TURBOPUMP": node("TURBOPUMP", nil, []entities.PartNumber{"ENGINE"}),
+	}
+	sorted, err := service.topologicalSort(graph)
+	if err != nil {
+		t.Fatalf("topologicalSort failed: %v", err)
+	}
+	if fmt.Sprint(sorted) != "[TURBOPUMP ENGINE]" {
+		t.Errorf("Expected [TURBOPUMP ENGINE], got %v", sorted)
+	}
+
+	// STAGE -> ENGINE -> TURBOPUMP -> ENGINE
+	graph = DependencyGraph{
+		"STAGE":     node("STAGE", []entities.PartNumber{"ENGINE"}, nil),
+		"ENGINE":    node("ENGINE", []entities.PartNumber{"TURBOPUMP"}, []entities.PartNumber{"STAGE", "TURBOPUMP"}),
+		"TURBOPUMP": node("TURBOPUMP", []entities.PartNumber{"ENGINE"}, []entities.PartNumber{"ENGINE"}),
+	}
+	_, err = service.topologicalSort(graph)
+
+	var cyclic *entities.CyclicBOMError
+	if !errors.As(err, &cyclic) {
+		t.Fatalf("Expected a CyclicBOMError, got %v", err)
+	}
+	if fmt.Sprint(cyclic.Parts()) != "[ENGINE TURBOPUMP]" {
+		t.Errorf("Expected ENGINE and TURBOPUMP on the cycle, got %v", cyclic.Cycle)
+	}
+}
+
+func TestMRPService_ExplodeDemand_Cancelled(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testhelpers.BuildSimpleTestData()
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     1,
+			NeedDate:     time.Now().Add(30 * 24 * time.Hour),
+			DemandSource: "TEST_ORDER",
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	service := newTestMRPService()
+	_, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMRPService_ProgressObserver(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testhelpers.BuildSimpleTestData()
+
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     1,
+			NeedDate:     time.Now().Add(30 * 24 * time.Hour),
+			DemandSource: "ORDER_1",
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		},
+		{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     1,
+			NeedDate:     time.Now().Add(60 * 24 * time.Hour),
+			DemandSource: "ORDER_2",
+			Location:     "FACTORY",
+			TargetSerial: "SN002",
+		},
+	}
+
+	var updates []Progress
+	service := newTestMRPService()
+	service.SetProgressObserver(ProgressObserverFunc(func(progress Progress) {
+		updates = append(updates, progress)
+	}))
+
+	if _, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo); err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	// Every stage reports, in order, and finishes with Done == Total
+	last := make(map[ProgressStage]Progress)
+	previous := StageExplode
+	for _, update := range updates {
+		if update.Stage < previous {
+			t.Errorf("Stage %v reported after %v", update.Stage, previous)
+		}
+		if update.Done > update.Total {
+			t.Errorf("%v reported %d of %d", update.Stage, update.Done, update.Total)
+		}
+		previous = update.Stage
+		last[update.Stage] = update
+	}
+	for _, stage := range []ProgressStage{StageExplode, StageNet, StageSchedule} {
+		final, ok := last[stage]
+		if !ok {
+			t.Errorf("No progress reported for %v", stage)
+			continue
+		}
+		if final.Done != final.Total {
+			t.Errorf("%v finished at %d of %d", stage, final.Done, final.Total)
+		}
+	}
+	if last[StageExplode].Total != len(demands) {
+		t.Errorf("Expected %d demands to explode, got %d", len(demands), last[StageExplode].Total)
+	}
+}
+
+func TestMRPService_ExplodeDemands_ParallelMatchesSequential(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, _, demands := setupGeneratedScenario(500, 5, 40, 7)
+
+	// Repeat some demands under another source, so later explosions hit the cache
+	for i := 0; i < 10; i++ {
+		repeat := *demands[i]
+		repeat.DemandSource = fmt.Sprintf("REPEAT_%d", i)
+		demands = append(demands, &repeat)
+	}
+
+	explode := func(workers int) []*entities.GrossRequirement {
+		service := NewMRPServiceWithConfig(EngineConfig{Concurrency: workers})
+		grossReqs, err := service.explodeDemands(ctx, demands, bomRepo, itemRepo, inventoryRepo)
+		if err != nil {
+			t.Fatalf("explodeDemands with %d workers failed: %v", workers, err)
+		}
+		return grossReqs
+	}
+
+	sequential := explode(1)
+	for _, workers := range []int{2, 8} {
+		parallel := explode(workers)
+		if len(parallel) != len(sequential) {
+			t.Fatalf("%d workers: expected %d requirements, got %d", workers, len(sequential), len(parallel))
+		}
+		for i := range sequential {
+			if *parallel[i] != *sequential[i] {
+				t.Fatalf("%d workers: requirement %d is %+v, sequentially %+v",
+					workers, i, *parallel[i], *sequential[i])
+			}
+		}
+	}
+}
+
+func TestMRPService_ExplodeDemands_ParallelError(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, _, demands := setupGeneratedScenario(200, 4, 20, 3)
+	demands[5] = &entities.DemandRequirement{
+		PartNumber:   "UNKNOWN_PART",
+		Quantity:     1,
+		NeedDate:     time.Now().Add(30 * 24 * time.Hour),
+		DemandSource: "BAD_ORDER",
+		Location:     "FACTORY",
+		TargetSerial: "SN001",
+	}
+
+	service := NewMRPServiceWithConfig(EngineConfig{Concurrency: 4})
+	_, err := service.explodeDemands(ctx, demands, bomRepo, itemRepo, inventoryRepo)
+
+	var notFound *entities.ItemNotFoundError
+	if !errors.As(err, &notFound) || notFound.PartNumber != "UNKNOWN_PART" {
+		t.Fatalf("Expected ItemNotFoundError for UNKNOWN_PART, got %v", err)
+	}
+}
+
+func TestMRPService_ExplosionCache_HitMatchesFreshExplosion(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, _ := setupDeepBOM(5)
+
+	first := &entities.DemandRequirement{
+		PartNumber:   "LEVEL_0",
+		Quantity:     1,
+		NeedDate:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		DemandSource: "ORDER_1",
+		Location:     "FACTORY",
+		TargetSerial: "SN001",
+	}
+	// Same part and serial, so the explosion of LEVEL_0 is cached; every other detail differs
+	second := &entities.DemandRequirement{
+		PartNumber:   "LEVEL_0",
+		Quantity:     3,
+		NeedDate:     time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		DemandSource: "ORDER_2",
+		Location:     "VANDENBERG",
+		TargetSerial: "SN001",
+	}
+
+	fresh, err := newTestMRPService().explodeDemands(
+		ctx, []*entities.DemandRequirement{second}, bomRepo, itemRepo, inventoryRepo)
+	if err != nil {
+		t.Fatalf("explodeDemands failed: %v", err)
+	}
+
+	service := newTestMRPService()
+	both, err := service.explodeDemands(
+		ctx, []*entities.DemandRequirement{first, second}, bomRepo, itemRepo, inventoryRepo)
+	if err != nil {
+		t.Fatalf("explodeDemands failed: %v", err)
+	}
+	if stats := service.explosionCache.statistics(); stats.Hits != 1 {
+		t.Fatalf("Expected the second demand to hit the cache once, got %+v", stats)
+	}
+
+	cached := both[len(both)-len(fresh):]
+	for i := range fresh {
+		if *cached[i] != *fresh[i] {
+			t.Errorf("Requirement %d from the cache is %+v, exploded fresh %+v", i, *cached[i], *fresh[i])
+		}
+	}
+}
+
+func TestMRPService_ExplodeDemand_CacheStats(t *testing.T) {
+	ctx := context.Background()
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testhelpers.BuildSimpleTestData()
+
+	demand := func(source string) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   "ASSEMBLY_A",
+			Quantity:     1,
+			NeedDate:     time.Now().Add(30 * 24 * time.Hour),
+			DemandSource: source,
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		}
+	}
+
+	service := newTestMRPService()
+	result, err := service.ExplodeDemand(ctx, []*entities.DemandRequirement{demand("ORDER_1")},
+		bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+	if result.CacheStats.Hits != 0 || result.CacheStats.Misses == 0 {
+		t.Errorf("Expected only misses on the first run, got %+v", result.CacheStats)
+	}
+
+	// Stats are per run, while the cache itself carries over
+	result, err = service.ExplodeDemand(ctx, []*entities.DemandRequirement{demand("ORDER_2")},
+		bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+	expected := dto.CacheStats{Hits: 1, Entries: len(result.ExplosionCache)}
+	if result.CacheStats != expected {
+		t.Errorf("Expected stats %+v on the second run, got %+v", expected, result.CacheStats)
+	}
+}
+
+func TestMRPService_ExplodeDemand_DemandFulfillment(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(2)
+	itemRepo := memory.NewItemRepository(2)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	for _, item := range []*entities.Item{
+		{PartNumber: "PARENT_ASSY", LeadTimeDays: 10, LotSizeRule: entities.LotForLot, MinOrderQty: 1},
+		{PartNumber: "CHILD_COMP", LeadTimeDays: 5, LotSizeRule: entities.LotForLot, MinOrderQty: 1},
+	} {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+	if err := bomRepo.SaveBOMLine(&entities.BOMLine{
+		ParentPN:    "PARENT_ASSY",
+		ChildPN:     "CHILD_COMP",
+		QtyPer:      1,
+		FindNumber:  100,
+		Effectivity: entities.SerialEffectivity{FromSerial: "SN001"},
+	}); err != nil {
+		t.Fatalf("Failed to save BOM line: %v", err)
+	}
+	if err := inventoryRepo.SaveInventoryLot(&entities.InventoryLot{
+		PartNumber:  "PARENT_ASSY",
+		LotNumber:   "LOT001",
+		Location:    "FACTORY",
+		Quantity:    1,
+		ReceiptDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status:      entities.Available,
+	}); err != nil {
+		t.Fatalf("Failed to save inventory: %v", err)
+	}
+
+	// Stock covers the earliest demand; the other two wait on one order for two assemblies,
+	// due 15 days out once its child is built
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	demand := func(source string, needInDays int) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   "PARENT_ASSY",
+			Quantity:     1,
+			NeedDate:     today.AddDate(0, 0, needInDays),
+			DemandSource: source,
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		}
+	}
+	demands := []*entities.DemandRequirement{
+		demand("ON_TIME", 60),
+		demand("LATE", 5),
+		demand("FROM_STOCK", 3),
+	}
+
+	result, err := newTestMRPService().ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	if len(result.DemandFulfillment) != len(demands) {
+		t.Fatalf("Expected fulfillment of %d demands, got %d", len(demands), len(result.DemandFulfillment))
+	}
+	bySource := make(map[string]entities.DemandFulfillment)
+	for i, fulfillment := range result.DemandFulfillment {
+		if fulfillment.DemandSource != demands[i].DemandSource {
+			t.Errorf("Expected fulfillment %d for %s, got %s", i, demands[i].DemandSource, fulfillment.DemandSource)
+		}
+		bySource[fulfillment.DemandSource] = fulfillment
+	}
+
+	fromStock := bySource["FROM_STOCK"]
+	if !fromStock.CoveredByStock() || fromStock.IsLate() || fromStock.GatingOrder != nil {
+		t.Errorf("Expected FROM_STOCK covered by stock, on time and ungated, got %+v", fromStock)
+	}
+
+	late := bySource["LATE"]
+	if late.CoveredByStock() || late.OrderQty != 1 {
+		t.Errorf("Expected LATE covered by a planned order, got stock %d, orders %d", late.StockQty, late.OrderQty)
+	}
+	if late.DaysLate != 10 || !late.IsLate() {
+		t.Errorf("Expected LATE to be 10 days late, got %d", late.DaysLate)
+	}
+	if late.GatingOrder == nil || late.GatingOrder.PartNumber != "CHILD_COMP" {
+		t.Errorf("Expected LATE to be gated by the CHILD_COMP order, got %+v", late.GatingOrder)
+	}
+
+	onTime := bySource["ON_TIME"]
+	if onTime.IsLate() || onTime.DaysLate != -45 {
+		t.Errorf("Expected ON_TIME to be 45 days early, got %d days late", onTime.DaysLate)
+	}
+
+	if lateDemands := result.LateDemands(); len(lateDemands) != 1 || lateDemands[0].DemandSource != "LATE" {
+		t.Errorf("Expected only LATE to be reported late, got %+v", lateDemands)
+	}
+}
+
+func TestProjectFulfillment_SupplyAtAnotherLocation(t *testing.T) {
+	today := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	demand := func(location string) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   "WIDGET",
+			Quantity:     1,
+			NeedDate:     today.AddDate(0, 0, 20),
+			DemandSource: location,
+			Location:     location,
+			TargetSerial: "SN001",
+		}
+	}
+	demands := []*entities.DemandRequirement{demand("FACTORY"), demand("DEPOT")}
+
+	// Only the depot has supply, enough for both demands
+	receipts := []entities.ScheduledReceipt{{
+		OrderNumber: "PO-1",
+		PartNumber:  "WIDGET",
+		Quantity:    2,
+		DueDate:     today.AddDate(0, 0, 5),
+		Location:    "DEPOT",
+		OrderType:   entities.Buy,
+	}}
+
+	fulfillment := projectFulfillment(demands, nil, receipts, nil, nil, DependencyGraph{}, nil, today)
+
+	depot := fulfillment[1]
+	if depot.OrderQty != 1 || depot.IsLate() || depot.GatingReceipt == nil {
+		t.Errorf("Expected the DEPOT demand on time from the depot receipt, got %+v", depot)
+	}
+
+	factory := fulfillment[0]
+	if factory.OrderQty != 0 || factory.GatingReceipt != nil {
+		t.Errorf("Expected the depot receipt not to fulfil the FACTORY demand, got %d units from %+v",
+			factory.OrderQty, factory.GatingReceipt)
+	}
+	if factory.ShortQty != 1 || factory.GatingShortage == nil || factory.GatingShortage.Location != "FACTORY" {
+		t.Errorf("Expected the FACTORY demand gated by a shortage there, got %+v", factory.GatingShortage)
+	}
+}
+
+func TestMRPService_ExplodeDemand_ScheduledReceiptActionMessages(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(5)
+	itemRepo := memory.NewItemRepository(5)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	for _, partNumber := range []entities.PartNumber{"EXPEDITE", "PUSH_OUT", "INCREASE", "RELEASE"} {
+		if err := itemRepo.SaveItem(&entities.Item{
+			PartNumber:   partNumber,
+			LeadTimeDays: 10,
+			LotSizeRule:  entities.LotForLot,
+			MinOrderQty:  1,
+		}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	demand := func(partNumber entities.PartNumber, qty entities.Quantity, needInDays int) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   partNumber,
+			Quantity:     qty,
+			NeedDate:     today.AddDate(0, 0, needInDays),
+			DemandSource: string(partNumber),
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		}
+	}
+	demands := []*entities.DemandRequirement{
+		demand("EXPEDITE", 2, 30),
+		demand("PUSH_OUT", 2, 60),
+		demand("INCREASE", 5, 30),
+		demand("RELEASE", 4, 3),
+	}
+
+	receipt := func(orderNumber string, partNumber entities.PartNumber, qty entities.Quantity, dueInDays int) entities.ScheduledReceipt {
+		return entities.ScheduledReceipt{
+			OrderNumber: orderNumber,
+			PartNumber:  partNumber,
+			Quantity:    qty,
+			DueDate:     today.AddDate(0, 0, dueInDays),
+			Location:    "FACTORY",
+			OrderType:   entities.Buy,
+		}
+	}
+
+	service := newTestMRPService()
+	service.SetScheduledReceipts([]entities.ScheduledReceipt{
+		receipt("PO-EXPEDITE", "EXPEDITE", 2, 40),
+		receipt("PO-PUSH_OUT", "PUSH_OUT", 5, 20),
+		receipt("PO-INCREASE", "INCREASE", 2, 25),
+		receipt("PO-UNUSED", "UNUSED", 4, 10),
+	})
+
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	// Receipts cover EXPEDITE and PUSH_OUT in full, and two of the five INCREASE
+	plannedQty := make(map[entities.PartNumber]entities.Quantity)
+	for _, order := range result.PlannedOrders {
+		plannedQty[order.PartNumber] += order.Quantity
+	}
+	expectedPlanned := map[entities.PartNumber]entities.Quantity{"INCREASE": 3, "RELEASE": 4}
+	if len(plannedQty) != len(expectedPlanned) {
+		t.Errorf("Expected planned orders for %v, got %v", expectedPlanned, plannedQty)
+	}
+	for partNumber, qty := range expectedPlanned {
+		if plannedQty[partNumber] != qty {
+			t.Errorf("Expected %d planned for %s, got %d", qty, partNumber, plannedQty[partNumber])
+		}
+	}
+
+	expected := []entities.ActionMessage{
+		{Action: entities.ActionRelease, PartNumber: "RELEASE", Quantity: 4, Days: 7},
+		{Action: entities.ActionExpedite, PartNumber: "EXPEDITE", OrderNumber: "PO-EXPEDITE", Quantity: 2, Days: 10},
+		{Action: entities.ActionPushOut, PartNumber: "INCREASE", OrderNumber: "PO-INCREASE", Quantity: 2, Days: 5},
+		{Action: entities.ActionIncrease, PartNumber: "INCREASE", OrderNumber: "PO-INCREASE", Quantity: 3},
+		{Action: entities.ActionPushOut, PartNumber: "PUSH_OUT", OrderNumber: "PO-PUSH_OUT", Quantity: 5, Days: 40},
+		{Action: entities.ActionCancel, PartNumber: "PUSH_OUT", OrderNumber: "PO-PUSH_OUT", Quantity: 3},
+		{Action: entities.ActionCancel, PartNumber: "UNUSED", OrderNumber: "PO-UNUSED", Quantity: 4},
+	}
+	if len(result.ActionMessages) != len(expected) {
+		t.Fatalf("Expected %d action messages, got %d: %+v", len(expected), len(result.ActionMessages), result.ActionMessages)
+	}
+	for i, want := range expected {
+		got := result.ActionMessages[i]
+		if got.Action != want.Action || got.PartNumber != want.PartNumber || got.OrderNumber != want.OrderNumber ||
+			got.Quantity != want.Quantity || got.Days != want.Days {
+			t.Errorf("Action message %d: expected %s %s %s qty %d days %d, got %s %s %s qty %d days %d", i,
+				want.Action, want.PartNumber, want.OrderNumber, want.Quantity, want.Days,
+				got.Action, got.PartNumber, got.OrderNumber, got.Quantity, got.Days)
+		}
+	}
+
+	// The late receipt gates the demand it covers
+	for _, fulfillment := range result.DemandFulfillment {
+		if fulfillment.PartNumber != "EXPEDITE" {
+			continue
+		}
+		if fulfillment.GatingReceipt == nil || fulfillment.GatingReceipt.OrderNumber != "PO-EXPEDITE" {
+			t.Errorf("Expected EXPEDITE to be gated by PO-EXPEDITE, got %+v", fulfillment.GatingReceipt)
+		}
+		if fulfillment.DaysLate != 10 {
+			t.Errorf("Expected EXPEDITE to be 10 days late, got %d", fulfillment.DaysLate)
+		}
+	}
+}
+
+func TestActionMessages_OrderedByNeedDate(t *testing.T) {
+	today := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	message := func(action entities.ActionType, partNumber entities.PartNumber, needInDays, days int) entities.ActionMessage {
+		m := entities.ActionMessage{Action: action, PartNumber: partNumber, Days: days}
+		if needInDays >= 0 {
+			m.NeedDate = today.AddDate(0, 0, needInDays)
+		}
+		return m
+	}
+
+	messages := actionMessages(nil, nil, nil, []entities.ActionMessage{
+		message(entities.ActionPushOut, "LATER", 30, 5),
+		message(entities.ActionCancel, "UNNEEDED", -1, 0),
+		message(entities.ActionExpedite, "SOONER", 10, 3),
+		message(entities.ActionRelease, "SOONER_MORE", 10, 8),
+		message(entities.ActionIncrease, "TIED", 20, 0),
+		message(entities.ActionFirm, "TIED", 20, 0),
+	}, today)
+
+	// Earliest need first, then most days to move, then the more urgent action
+	expected := []entities.PartNumber{"SOONER_MORE", "SOONER", "TIED", "TIED", "LATER", "UNNEEDED"}
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected %d messages, got %+v", len(expected), messages)
+	}
+	for i, partNumber := range expected {
+		if messages[i].PartNumber != partNumber {
+			t.Errorf("Message %d: expected %s, got %s %s", i, partNumber, messages[i].Action, messages[i].PartNumber)
+		}
+	}
+	if messages[2].Action != entities.ActionFirm || messages[3].Action != entities.ActionIncrease {
+		t.Errorf("Expected Firm before Increase at the same need date and days, got %s then %s",
+			messages[2].Action, messages[3].Action)
+	}
+}
+
+func TestMRPService_ExplodeDemand_FirmPlannedOrdersAndTimeFences(t *testing.T) {
+	ctx := context.Background()
+
+	bomRepo := memory.NewBOMRepository(3)
+	itemRepo := memory.NewItemRepository(3)
+	inventoryRepo := memory.NewInventoryRepository()
+	demandRepo := memory.NewDemandRepository()
+
+	for _, item := range []*entities.Item{
+		{PartNumber: "FIRMED", LeadTimeDays: 10, LotSizeRule: entities.LotForLot, MinOrderQty: 1},
+		{PartNumber: "DEMAND_FENCED", LeadTimeDays: 10, LotSizeRule: entities.LotForLot, MinOrderQty: 1,
+			DemandTimeFenceDays: 20},
+		{PartNumber: "PLANNING_FENCED", LeadTimeDays: 10, LotSizeRule: entities.LotForLot, MinOrderQty: 1,
+			PlanningTimeFenceDays: 30},
+	} {
+		if err := itemRepo.SaveItem(item); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	demand := func(partNumber entities.PartNumber, qty entities.Quantity, needInDays int) *entities.DemandRequirement {
+		return &entities.DemandRequirement{
+			PartNumber:   partNumber,
+			Quantity:     qty,
+			NeedDate:     today.AddDate(0, 0, needInDays),
+			DemandSource: string(partNumber),
+			Location:     "FACTORY",
+			TargetSerial: "SN001",
+		}
+	}
+	demands := []*entities.DemandRequirement{
+		demand("FIRMED", 5, 40),
+		demand("DEMAND_FENCED", 4, 10),
+		demand("PLANNING_FENCED", 2, 20),
+	}
+
+	firmOrder, err := entities.NewFirmPlannedOrder(
+		"FPO-1", "FIRMED", 3, today.AddDate(0, 0, 5), today.AddDate(0, 0, 15), "FACTORY", entities.Make,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create firm planned order: %v", err)
+	}
+
+	service := newTestMRPService()
+	service.SetFirmPlannedOrders([]entities.PlannedOrder{*firmOrder})
+
+	result, err := service.ExplodeDemand(ctx, demands, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("ExplodeDemand failed: %v", err)
+	}
+
+	// The firm order is returned unchanged, and MRP plans only what it does not cover
+	ordersByPart := make(map[entities.PartNumber][]entities.PlannedOrder)
+	for _, order := range result.PlannedOrders {
+		ordersByPart[order.PartNumber] = append(ordersByPart[order.PartNumber], order)
+	}
+	firmed := ordersByPart["FIRMED"]
+	if len(firmed) != 2 || firmed[0] != *firmOrder || firmed[1].Firm || firmed[1].Quantity != 2 {
+		t.Errorf("Expected firm order FPO-1 unchanged and a planned order for 2, got %+v", firmed)
+	}
+
+	// Nothing is planned inside the demand time fence, so the requirement is short
+	if orders := ordersByPart["DEMAND_FENCED"]; len(orders) != 0 {
+		t.Errorf("Expected no orders inside the demand time fence, got %+v", orders)
+	}
+	fencedShort := false
+	for _, shortage := range result.ShortageReport {
+		if shortage.PartNumber == "DEMAND_FENCED" && shortage.ShortQty == 4 {
+			fencedShort = true
+		}
+	}
+	if !fencedShort {
+		t.Errorf("Expected DEMAND_FENCED to be short 4, got %+v", result.ShortageReport)
+	}
+
+	// The planning time fence holds the order to 30 days out, 10 days after it is needed
+	planningFenced := ordersByPart["PLANNING_FENCED"]
+	if len(planningFenced) != 1 || shared.DaysBetween(today, shared.DateOf(planningFenced[0].DueDate)) != 30 {
+		t.Errorf("Expected one PLANNING_FENCED order due at the fence, got %+v", planningFenced)
+	}
+
+	expected := []entities.ActionMessage{
+		{Action: entities.ActionFirm, PartNumber: "DEMAND_FENCED", Quantity: 4},
+		{Action: entities.ActionFirm, PartNumber: "PLANNING_FENCED", Quantity: 2, Days: 10},
+		{Action: entities.ActionPushOut, PartNumber: "FIRMED", OrderNumber: "FPO-1", Quantity: 3, Days: 25},
+		{Action: entities.ActionIncrease, PartNumber: "FIRMED", OrderNumber: "FPO-1", Quantity: 2},
+	}
+	if len(result.ActionMessages) != len(expected) {
+		t.Fatalf("Expected %d action messages, got %d: %+v", len(expected), len(result.ActionMessages), result.ActionMessages)
+	}
+	for i, want := range expected {
+		got := result.ActionMessages[i]
+		if got.Action != want.Action || got.PartNumber != want.PartNumber || got.OrderNumber != want.OrderNumber ||
+			got.Quantity != want.Quantity || got.Days != want.Days {
+			t.Errorf("Action message %d: expected %s %s %s qty %d days %d, got %s %s %s qty %d days %d", i,
+				want.Action, want.PartNumber, want.OrderNumber, want.Quantity, want.Days,
+				got.Action, got.PartNumber, got.OrderNumber, got.Quantity, got.Days)
+		}
+	}
+	if !result.ActionMessages[0].DueDate.IsZero() {
+		t.Errorf("Expected no order behind the demand time fence suggestion, got due %v", result.ActionMessages[0].DueDate)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/vsinha/mrp/pkg/application/dto"
 	"github.com/vsinha/mrp/pkg/application/services/shared"
 	"github.com/vsinha/mrp/pkg/domain/entities"
 )
@@ -12,11 +13,22 @@ import (
 type MRPVisitor struct {
 	demandTrace string
 	needDate    time.Time
+
+	// cache memoizes the explosion below each node; nil disables memoization
+	cache *explosionCache
+	// keyByDate adds the node's need date to cache keys, for BOMs with date effectivity
+	keyByDate bool
+
+	// phantomStock, when set, allocates phantom stock as it is reached, and remaining holds
+	// how much of each node on the current path its stock leaves to explode into its children
+	phantomStock *phantomStock
+	remaining    []entities.Quantity
 }
 
 // MRPNodeData holds data for an MRP node during traversal
 type MRPNodeData struct {
 	SelfRequirement *entities.GrossRequirement
+	Cached          *dto.ExplosionResult // Explosion of the node found in the cache, if any
 }
 
 // NewMRPVisitor creates a new MRP visitor
@@ -27,16 +39,25 @@ func NewMRPVisitor(demandTrace string, needDate time.Time) *MRPVisitor {
 	}
 }
 
-// VisitNode creates a gross requirement for this node
+// VisitNode creates a gross requirement for this node, and skips the node's children when
+// their explosion is cached or phantom stock covers the node
 func (v *MRPVisitor) VisitNode(
 	ctx context.Context,
 	nodeCtx shared.BOMNodeContext,
 ) (interface{}, bool, error) {
+	quantity := nodeCtx.Quantity
+	if v.phantomStock != nil {
+		var err error
+		if quantity, err = v.drawPhantomStock(nodeCtx); err != nil {
+			return nil, false, err
+		}
+	}
+
 	// Create requirement for this part itself
 	req := &entities.GrossRequirement{
 		PartNumber:   nodeCtx.PartNumber,
-		Quantity:     nodeCtx.Quantity,
-		NeedDate:     v.needDate,
+		Quantity:     quantity,
+		NeedDate:     v.nodeNeedDate(nodeCtx),
 		DemandTrace:  v.demandTrace,
 		Location:     nodeCtx.Location,
 		TargetSerial: nodeCtx.TargetSerial,
@@ -46,7 +67,17 @@ func (v *MRPVisitor) VisitNode(
 		SelfRequirement: req,
 	}
 
-	// Always continue traversal for MRP
+	if v.phantomStock != nil {
+		return nodeData, v.remaining[len(v.remaining)-1] > 0, nil
+	}
+
+	if v.cache != nil {
+		if cached, ok := v.cache.get(v.cacheKey(nodeCtx, req.NeedDate)); ok {
+			nodeData.Cached = cached
+			return nodeData, false, nil
+		}
+	}
+
 	return nodeData, true, nil
 }
 
@@ -58,6 +89,12 @@ func (v *MRPVisitor) ProcessChildren(
 	childResults []interface{},
 ) (interface{}, error) {
 	mrpNodeData := nodeData.(*MRPNodeData)
+	if v.phantomStock != nil {
+		v.remaining = v.remaining[:len(v.remaining)-1]
+	}
+	if mrpNodeData.Cached != nil {
+		return v.expand(mrpNodeData.SelfRequirement, mrpNodeData.Cached), nil
+	}
 
 	// Start with this node's requirement
 	var allRequirements []*entities.GrossRequirement
@@ -71,5 +108,86 @@ func (v *MRPVisitor) ProcessChildren(
 		}
 	}
 
+	if v.cache != nil && nodeCtx.Quantity > 0 {
+		self := mrpNodeData.SelfRequirement
+		v.cache.put(v.cacheKey(nodeCtx, self.NeedDate), unitExplosion(self, allRequirements))
+	}
+
 	return allRequirements, nil
 }
+
+// drawPhantomStock returns how much of a node is required, given what the stock of phantoms
+// above it covers, and allocates the node's own stock if it is a phantom
+func (v *MRPVisitor) drawPhantomStock(nodeCtx shared.BOMNodeContext) (entities.Quantity, error) {
+	quantity := nodeCtx.Quantity
+	if n := len(v.remaining); n > 0 {
+		quantity = nodeCtx.BOMLine.QtyPer * v.remaining[n-1]
+	}
+
+	remaining := quantity
+	if nodeCtx.Item.Phantom && quantity > 0 {
+		drawn, err := v.phantomStock.draw(nodeCtx.PartNumber, nodeCtx.Location, quantity)
+		if err != nil {
+			return 0, err
+		}
+		remaining -= drawn
+	}
+	v.remaining = append(v.remaining, remaining)
+	return quantity, nil
+}
+
+// cacheKey returns the key of a node's explosion
+func (v *MRPVisitor) cacheKey(nodeCtx shared.BOMNodeContext, needDate time.Time) dto.ExplosionCacheKey {
+	if !v.keyByDate {
+		needDate = time.Time{}
+	}
+	return explosionCacheKey(nodeCtx.PartNumber, nodeCtx.EndItem, nodeCtx.TargetSerial, needDate)
+}
+
+// expand scales a cached unit explosion to the quantity, need date and location of self
+func (v *MRPVisitor) expand(
+	self *entities.GrossRequirement,
+	cached *dto.ExplosionResult,
+) []*entities.GrossRequirement {
+	requirements := make([]*entities.GrossRequirement, len(cached.Requirements))
+	for i, exploded := range cached.Requirements {
+		requirements[i] = &entities.GrossRequirement{
+			PartNumber:   exploded.PartNumber,
+			Quantity:     exploded.QtyPer * self.Quantity,
+			NeedDate:     self.NeedDate.AddDate(0, 0, exploded.NeedDateOffset),
+			DemandTrace:  v.demandTrace,
+			Location:     self.Location,
+			TargetSerial: self.TargetSerial,
+		}
+	}
+	return requirements
+}
+
+// unitExplosion turns the requirements of an explosion rooted at self into quantities per unit
+// of self and need dates relative to self's
+func unitExplosion(
+	self *entities.GrossRequirement,
+	requirements []*entities.GrossRequirement,
+) *dto.ExplosionResult {
+	exploded := make([]dto.ExplodedRequirement, len(requirements))
+	for i, req := range requirements {
+		exploded[i] = dto.ExplodedRequirement{
+			PartNumber:     req.PartNumber,
+			QtyPer:         req.Quantity / self.Quantity,
+			NeedDateOffset: shared.DaysBetween(self.NeedDate, req.NeedDate),
+		}
+	}
+	return &dto.ExplosionResult{
+		Requirements: exploded,
+		ComputedAt:   time.Now(),
+	}
+}
+
+// nodeNeedDate returns the backward-scheduled need date computed by the traverser,
+// falling back to the demand's need date when traversing without dates
+func (v *MRPVisitor) nodeNeedDate(nodeCtx shared.BOMNodeContext) time.Time {
+	if nodeCtx.NeedDate.IsZero() {
+		return v.needDate
+	}
+	return nodeCtx.NeedDate
+}
@@ -0,0 +1,129 @@
+package mrp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+)
+
+// phantomStock allocates the on-hand stock of phantoms as an explosion reaches them, keeping
+// one allocation per part and location
+type phantomStock struct {
+	inventoryRepo repositories.InventoryRepository
+	allocations   map[string]*entities.AllocationResult
+}
+
+// newPhantomStock creates a phantomStock that allocates from inventoryRepo
+func newPhantomStock(inventoryRepo repositories.InventoryRepository) *phantomStock {
+	return &phantomStock{
+		inventoryRepo: inventoryRepo,
+		allocations:   make(map[string]*entities.AllocationResult),
+	}
+}
+
+// draw allocates up to quantity of a phantom at a location, and returns how much it got
+func (ps *phantomStock) draw(
+	partNumber entities.PartNumber,
+	location string,
+	quantity entities.Quantity,
+) (entities.Quantity, error) {
+	allocation, err := ps.inventoryRepo.AllocateInventory(partNumber, location, quantity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate inventory for %s: %w", partNumber, err)
+	}
+
+	key := fmt.Sprintf("%s|%s", partNumber, location)
+	if existing, exists := ps.allocations[key]; exists {
+		existing.AllocatedQty += allocation.AllocatedQty
+		existing.RemainingDemand += allocation.RemainingDemand
+		existing.AllocatedFrom = append(existing.AllocatedFrom, allocation.AllocatedFrom...)
+	} else {
+		ps.allocations[key] = allocation
+	}
+	return allocation.AllocatedQty, nil
+}
+
+// hasPhantomStock reports whether any phantom among the gross requirements has stock at the
+// location it is required at
+func hasPhantomStock(
+	grossReqs []*entities.GrossRequirement,
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+) (bool, error) {
+	checked := make(map[string]bool)
+	for _, req := range grossReqs {
+		key := fmt.Sprintf("%s|%s", req.PartNumber, req.Location)
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		item, err := itemRepo.GetItem(req.PartNumber)
+		if err != nil {
+			return false, fmt.Errorf("failed to get item %s: %w", req.PartNumber, err)
+		}
+		if !item.Phantom {
+			continue
+		}
+
+		lots, err := inventoryRepo.GetInventoryLots(req.PartNumber, req.Location)
+		if err != nil {
+			return false, fmt.Errorf("failed to get inventory for %s: %w", req.PartNumber, err)
+		}
+		for _, lot := range lots {
+			if lot.Quantity > 0 {
+				return true, nil
+			}
+		}
+		serials, err := inventoryRepo.GetSerializedInventory(req.PartNumber, req.Location)
+		if err != nil {
+			return false, fmt.Errorf("failed to get inventory for %s: %w", req.PartNumber, err)
+		}
+		if len(serials) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// explodeDrawingPhantomStock explodes every demand again, one after another in demand order,
+// allocating phantom stock as the explosion reaches it so a phantom's components are only
+// required for what its stock doesn't cover. It returns the gross requirements and the
+// allocations of phantom stock by part and location. The explosion cache is not used, as
+// cached explosions don't know the stock they were drawn against.
+func (s *MRPService) explodeDrawingPhantomStock(
+	ctx context.Context,
+	demands []*entities.DemandRequirement,
+	bomRepo repositories.BOMRepository,
+	itemRepo repositories.ItemRepository,
+	inventoryRepo repositories.InventoryRepository,
+) ([]*entities.GrossRequirement, map[string]*entities.AllocationResult, error) {
+	stock := newPhantomStock(inventoryRepo)
+	var allGrossRequirements []*entities.GrossRequirement
+
+	for _, demand := range demands {
+		bomTraverser := shared.NewBOMTraverser(bomRepo, itemRepo, inventoryRepo)
+		visitor := NewMRPVisitor(demand.DemandSource, demand.NeedDate)
+		visitor.phantomStock = stock
+
+		result, err := bomTraverser.TraverseBOM(
+			ctx,
+			demand.PartNumber,
+			demand.TargetSerial,
+			demand.Location,
+			demand.Quantity,
+			demand.NeedDate,
+			0,
+			visitor,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to explode demand for %s: %w", demand.PartNumber, err)
+		}
+		allGrossRequirements = append(allGrossRequirements, result.([]*entities.GrossRequirement)...)
+	}
+
+	return allGrossRequirements, stock.allocations, nil
+}
@@ -0,0 +1,59 @@
+package mrp
+
+// ProgressStage identifies a phase of an MRP run
+type ProgressStage int
+
+const (
+	StageExplode ProgressStage = iota
+	StageNet
+	StageSchedule
+)
+
+// String method for ProgressStage enum
+func (s ProgressStage) String() string {
+	switch s {
+	case StageExplode:
+		return "Exploding demands"
+	case StageNet:
+		return "Netting parts"
+	case StageSchedule:
+		return "Scheduling orders"
+	default:
+		return "Unknown"
+	}
+}
+
+// Progress reports how far an MRP run has got through one stage: Done of Total demands
+// exploded, part/location groups netted or parts scheduled
+type Progress struct {
+	Stage ProgressStage
+	Done  int
+	Total int
+}
+
+// ProgressObserver receives progress updates while ExplodeDemand runs. Updates for a stage
+// arrive in order and one at a time, but demand explosion updates may come from worker
+// goroutines.
+type ProgressObserver interface {
+	OnProgress(progress Progress)
+}
+
+// ProgressObserverFunc adapts a function to a ProgressObserver
+type ProgressObserverFunc func(progress Progress)
+
+// OnProgress calls f
+func (f ProgressObserverFunc) OnProgress(progress Progress) {
+	f(progress)
+}
+
+// SetProgressObserver registers an observer for the progress of later runs (nil to remove)
+func (s *MRPService) SetProgressObserver(observer ProgressObserver) {
+	s.observer = observer
+}
+
+// reportProgress notifies the observer, if any
+func (s *MRPService) reportProgress(stage ProgressStage, done, total int) {
+	if s.observer != nil {
+		s.observer.OnProgress(Progress{Stage: stage, Done: done, Total: total})
+	}
+}
@@ -0,0 +1,115 @@
+package mrp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// SetScheduledReceipts registers the open orders later runs net against requirements before
+// planning new orders (nil to remove)
+func (s *MRPService) SetScheduledReceipts(receipts []entities.ScheduledReceipt) {
+	s.receipts = receipts
+}
+
+// SetFirmPlannedOrders registers the planned orders the planner has fixed; later runs net
+// them against requirements and return them unchanged with the plan (nil to remove)
+func (s *MRPService) SetFirmPlannedOrders(orders []entities.PlannedOrder) {
+	s.firmOrders = orders
+}
+
+// receiptPeg records the requirements a scheduled receipt or firm planned order covers
+type receiptPeg struct {
+	receipt  *entities.ScheduledReceipt
+	firm     bool              // The receipt stands for a firm planned order
+	pegged   entities.Quantity // Units of requirements it covers
+	needDate time.Time         // Need date of the earliest requirement it covers (zero when none)
+}
+
+// netScheduledReceipts covers net requirements with the scheduled receipts and firm planned
+// orders at their part and location, earliest due to earliest need, and returns the
+// requirements left for new planned orders with what each receipt or firm order covers
+func (s *MRPService) netScheduledReceipts(
+	netReqs []*entities.NetRequirement,
+) ([]*entities.NetRequirement, []receiptPeg) {
+	if len(s.receipts) == 0 && len(s.firmOrders) == 0 {
+		return netReqs, nil
+	}
+
+	pegs := make([]receiptPeg, 0, len(s.receipts)+len(s.firmOrders))
+	for i := range s.receipts {
+		pegs = append(pegs, receiptPeg{receipt: &s.receipts[i]})
+	}
+	for _, order := range s.firmOrders {
+		pegs = append(pegs, receiptPeg{
+			receipt: &entities.ScheduledReceipt{
+				OrderNumber: order.OrderNumber,
+				PartNumber:  order.PartNumber,
+				Quantity:    order.Quantity,
+				DueDate:     order.DueDate,
+				Location:    order.Location,
+				OrderType:   order.OrderType,
+			},
+			firm: true,
+		})
+	}
+	receiptsByKey := make(map[string][]*receiptPeg)
+	for i := range pegs {
+		key := fmt.Sprintf("%s|%s", pegs[i].receipt.PartNumber, pegs[i].receipt.Location)
+		receiptsByKey[key] = append(receiptsByKey[key], &pegs[i])
+	}
+
+	reqsByKey := make(map[string][]*entities.NetRequirement)
+	for _, req := range netReqs {
+		key := fmt.Sprintf("%s|%s", req.PartNumber, req.Location)
+		reqsByKey[key] = append(reqsByKey[key], req)
+	}
+
+	remaining := make(map[*entities.NetRequirement]entities.Quantity)
+	for key, keyPegs := range receiptsByKey {
+		sort.SliceStable(keyPegs, func(i, j int) bool {
+			return keyPegs[i].receipt.DueDate.Before(keyPegs[j].receipt.DueDate)
+		})
+		reqs := append([]*entities.NetRequirement(nil), reqsByKey[key]...)
+		sort.SliceStable(reqs, func(i, j int) bool {
+			return reqs[i].NeedDate.Before(reqs[j].NeedDate)
+		})
+		for _, req := range reqs {
+			remaining[req] = req.Quantity
+		}
+
+		next := 0
+		for _, peg := range keyPegs {
+			for peg.pegged < peg.receipt.Quantity && next < len(reqs) {
+				req := reqs[next]
+				if peg.pegged == 0 {
+					peg.needDate = req.NeedDate
+				}
+				qty := min(peg.receipt.Quantity-peg.pegged, remaining[req])
+				peg.pegged += qty
+				remaining[req] -= qty
+				if remaining[req] == 0 {
+					next++
+				}
+			}
+		}
+	}
+
+	var netted []*entities.NetRequirement
+	for _, req := range netReqs {
+		qty, covered := remaining[req]
+		if !covered {
+			netted = append(netted, req)
+			continue
+		}
+		if qty == 0 {
+			continue
+		}
+		left := *req
+		left.Quantity = qty
+		netted = append(netted, &left)
+	}
+	return netted, pegs
+}
@@ -0,0 +1,49 @@
+package mrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/shared"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// applyDemandTimeFences holds back the net requirements needed inside their item's demand time
+// fence: MRP plans no new orders for them, so they are reported short, and suggests firming an
+// order for each part and location instead. It returns the requirements left to plan.
+func applyDemandTimeFences(
+	netReqs []*entities.NetRequirement,
+	depGraph DependencyGraph,
+	now time.Time,
+) ([]*entities.NetRequirement, []entities.ActionMessage) {
+	var plannable []*entities.NetRequirement
+	var messages []entities.ActionMessage
+	held := make(map[string]int) // Index of each part and location's message
+
+	for _, req := range netReqs {
+		node, exists := depGraph[req.PartNumber]
+		if !exists || node.Item.Phantom || node.Item.DemandTimeFenceDays <= 0 ||
+			!shared.DateOf(req.NeedDate).Before(shared.DateOf(now).AddDate(0, 0, node.Item.DemandTimeFenceDays)) {
+			plannable = append(plannable, req)
+			continue
+		}
+
+		key := fmt.Sprintf("%s|%s", req.PartNumber, req.Location)
+		if i, exists := held[key]; exists {
+			messages[i].Quantity += req.Quantity
+			if req.NeedDate.Before(messages[i].NeedDate) {
+				messages[i].NeedDate = req.NeedDate
+			}
+			continue
+		}
+		held[key] = len(messages)
+		messages = append(messages, entities.ActionMessage{
+			Action:     entities.ActionFirm,
+			PartNumber: req.PartNumber,
+			Location:   req.Location,
+			Quantity:   req.Quantity,
+			NeedDate:   req.NeedDate,
+		})
+	}
+	return plannable, messages
+}
@@ -2,6 +2,7 @@ package orchestration
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -72,6 +73,132 @@ func TestPlanningOrchestrator_AnalyzeCriticalPathWithMRPResults(t *testing.T) {
 	}
 }
 
+func TestPlanningOrchestrator_RunCompletePlanningAnalyzesEveryDemand(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testinghelpers.BuildAerospaceTestData()
+
+	mrpService := mrp.NewMRPService()
+	criticalPathService := criticalpath.NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	orchestrator := NewPlanningOrchestrator(
+		mrpService,
+		criticalPathService,
+		bomRepo,
+		itemRepo,
+		inventoryRepo,
+		demandRepo,
+	)
+
+	// The first demand is due long after the second, which cannot be met
+	demands := []*entities.DemandRequirement{
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     1,
+			NeedDate:     time.Now().AddDate(10, 0, 0),
+			DemandSource: "LATER_FLIGHT",
+			Location:     "KSC",
+			TargetSerial: "AS502",
+		},
+		{
+			PartNumber:   "F1_ENGINE",
+			Quantity:     1,
+			NeedDate:     time.Now(),
+			DemandSource: "NEXT_FLIGHT",
+			Location:     "KSC",
+			TargetSerial: "AS507",
+		},
+	}
+
+	planningResult, err := orchestrator.RunCompletePlanning(context.Background(), demands, 3)
+	if err != nil {
+		t.Fatalf("Failed to run complete planning: %v", err)
+	}
+	t.Logf("  %s", planningResult.GetSummary())
+
+	program := planningResult.Program
+	if len(program.Demands) != 2 {
+		t.Fatalf("Expected critical paths for both demands, got %d", len(program.Demands))
+	}
+	if late := program.LateDemands(); len(late) != 1 || late[0].Demand != demands[1] {
+		t.Errorf("Expected only the next flight to be late, got %d late demands", len(late))
+	}
+	if planningResult.CriticalPath != program.Demands[1].Analysis {
+		t.Error("Expected the planning critical path to be that of the late demand")
+	}
+	if len(program.SharedComponents) == 0 {
+		t.Error("Expected the two engines to share components")
+	}
+	for _, part := range program.Bottlenecks {
+		if part.LatenessDays > program.Demands[1].DaysLate*len(part.Demands) {
+			t.Errorf("Expected %s to account for at most the days the demands are late, got %d",
+				part.PartNumber, part.LatenessDays)
+		}
+	}
+}
+
+func TestPlanningOrchestrator_AnalyzeCriticalPathFromSchedule(t *testing.T) {
+	bomRepo, itemRepo, inventoryRepo, demandRepo := testinghelpers.BuildAerospaceTestData()
+
+	mrpService := mrp.NewMRPService()
+	criticalPathService := criticalpath.NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
+	orchestrator := NewPlanningOrchestrator(
+		mrpService,
+		criticalPathService,
+		bomRepo,
+		itemRepo,
+		inventoryRepo,
+		demandRepo,
+	)
+
+	demand := &entities.DemandRequirement{
+		PartNumber:   "F1_ENGINE",
+		Quantity:     1,
+		NeedDate:     time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+		DemandSource: "TEST_SCHEDULE",
+		Location:     "KSC",
+		TargetSerial: "AS502",
+	}
+
+	ctx := context.Background()
+	mrpResult, err := mrpService.ExplodeDemand(
+		ctx, []*entities.DemandRequirement{demand}, bomRepo, itemRepo, inventoryRepo, demandRepo)
+	if err != nil {
+		t.Fatalf("Failed to run MRP: %v", err)
+	}
+	if len(mrpResult.PlannedOrders) == 0 {
+		t.Fatal("Expected planned orders")
+	}
+
+	analysis, err := orchestrator.AnalyzeCriticalPathFromSchedule(
+		ctx, demand.PartNumber, demand.TargetSerial, demand.Location, demand.NeedDate, 5, mrpResult)
+	if err != nil {
+		t.Fatalf("Failed to analyze critical path: %v", err)
+	}
+
+	// The critical path spans the Gantt chart, from the first order start to the last order due
+	first, last := mrpResult.PlannedOrders[0].StartDate, mrpResult.PlannedOrders[0].DueDate
+	for _, order := range mrpResult.PlannedOrders {
+		if order.StartDate.Before(first) {
+			first = order.StartDate
+		}
+		if order.DueDate.After(last) {
+			last = order.DueDate
+		}
+	}
+	ganttDays := int(math.Round(last.Sub(first).Hours() / 24))
+	if analysis.CriticalPath.EffectiveLeadTime != ganttDays {
+		t.Errorf("Expected critical path of %d days to match the schedule, got %d",
+			ganttDays, analysis.CriticalPath.EffectiveLeadTime)
+	}
+
+	for _, node := range analysis.CriticalPath.PathDetails {
+		if node.ScheduledDue.IsZero() {
+			continue
+		}
+		if days := int(math.Round(node.ScheduledDue.Sub(node.ScheduledStart).Hours() / 24)); node.EffectiveLeadTime != days {
+			t.Errorf("Expected %s to take its %d scheduled days, got %d", node.PartNumber, days, node.EffectiveLeadTime)
+		}
+	}
+}
+
 func TestBOMTraverser_AllocationContext(t *testing.T) {
 	bomRepo, itemRepo, inventoryRepo, _ := testinghelpers.BuildAerospaceTestData()
 
@@ -97,7 +224,7 @@ func TestBOMTraverser_AllocationContext(t *testing.T) {
 	bomTraverser.SetAllocationContext(allocations)
 
 	// Create a visitor to test allocation context
-	visitor := criticalpath.NewCriticalPathVisitor(inventoryRepo, nil)
+	visitor := criticalpath.NewCriticalPathVisitor(inventoryRepo, nil, 3, criticalpath.RankByTotalLeadTime)
 
 	// Test traversal
 	result, err := bomTraverser.TraverseBOM(
@@ -106,6 +233,7 @@ func TestBOMTraverser_AllocationContext(t *testing.T) {
 		"AS502",
 		"KSC",
 		5,
+		time.Time{},
 		0,
 		visitor,
 	)
@@ -113,7 +241,7 @@ func TestBOMTraverser_AllocationContext(t *testing.T) {
 		t.Fatalf("Failed to traverse BOM with allocation context: %v", err)
 	}
 
-	paths := result.([]entities.CriticalPath)
+	paths := visitor.Paths(result, 0)
 	if len(paths) == 0 {
 		t.Error("Expected at least one path")
 	}
@@ -349,7 +477,7 @@ func TestMRPService_CriticalPathAnalysis_SimpleCase(t *testing.T) {
 	analysis, err := orchestrator.AnalyzeCriticalPathForPart(
 		ctx,
 		"ROCKET_ENGINE",
-		"AS507",
+		"SN507",
 		"KENNEDY",
 		5,
 	)
@@ -44,7 +44,8 @@ func NewPlanningOrchestrator(
 // PlanningResult contains the combined results of MRP and Critical Path analysis
 type PlanningResult struct {
 	MRPResult         *dto.MRPResult
-	CriticalPath      *entities.CriticalPathAnalysis
+	CriticalPath      *entities.CriticalPathAnalysis // Analysis of the demand with the least slack
+	Program           *entities.ProgramCriticalPath  // Critical paths of every demand
 	PlanningDate      time.Time
 	TotalParts        int
 	TotalLeadTime     int
@@ -74,26 +75,20 @@ func (po *PlanningOrchestrator) RunCompletePlanning(
 		return nil, fmt.Errorf("failed to run MRP explosion: %w", err)
 	}
 
-	// Step 2: Run critical path analysis using MRP allocation results
-	// Use the first demand as representative for critical path analysis
-	primaryDemand := demands[0]
-	criticalPath, err := po.criticalPathService.AnalyzeCriticalPathWithAllocations(
-		ctx,
-		primaryDemand.PartNumber,
-		primaryDemand.TargetSerial,
-		primaryDemand.Location,
-		topPaths,
-		mrpResult.Allocations,
-	)
+	// Step 2: Run critical path analysis of every demand using MRP allocation results
+	planningDate := time.Now()
+	program, err := po.AnalyzeProgram(ctx, demands, topPaths, entities.LeadTimeMode, mrpResult, planningDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze critical path: %w", err)
+		return nil, err
 	}
 
-	// Step 3: Create combined result
+	// Step 3: Create combined result, summarizing the demand with the least slack
+	criticalPath := program.MostCritical().Analysis
 	result := &PlanningResult{
 		MRPResult:         mrpResult,
 		CriticalPath:      criticalPath,
-		PlanningDate:      time.Now(),
+		Program:           program,
+		PlanningDate:      planningDate,
 		TotalParts:        len(mrpResult.PlannedOrders),
 		TotalLeadTime:     criticalPath.CriticalPath.TotalLeadTime,
 		EffectiveLeadTime: criticalPath.CriticalPath.EffectiveLeadTime,
@@ -102,6 +97,50 @@ func (po *PlanningOrchestrator) RunCompletePlanning(
 	return result, nil
 }
 
+// AnalyzeProgram performs critical path analysis of every demand using existing MRP results,
+// timing parts by lead time or by the planned order schedule, and combines the analyses into
+// bottleneck and shared component reports. Each build is projected to start on asOf. A demand
+// that cannot be analyzed fails the whole analysis, since the program reports would be
+// missing its bottlenecks.
+func (po *PlanningOrchestrator) AnalyzeProgram(
+	ctx context.Context,
+	demands []*entities.DemandRequirement,
+	topPaths int,
+	mode entities.CriticalPathMode,
+	mrpResult *dto.MRPResult,
+	asOf time.Time,
+) (*entities.ProgramCriticalPath, error) {
+	analyze := po.AnalyzeCriticalPathWithMRPResults
+	if mode == entities.ScheduleMode {
+		analyze = po.AnalyzeCriticalPathFromSchedule
+	}
+
+	demandPaths := make([]entities.DemandCriticalPath, 0, len(demands))
+	for _, demand := range demands {
+		analysis, err := analyze(
+			ctx,
+			demand.PartNumber,
+			demand.TargetSerial,
+			demand.Location,
+			demand.NeedDate,
+			topPaths,
+			mrpResult,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze critical path for %s: %w", demand.PartNumber, err)
+		}
+		demandPaths = append(demandPaths, criticalpath.NewDemandCriticalPath(demand, analysis, asOf))
+	}
+
+	return criticalpath.AnalyzeProgram(
+		asOf,
+		demandPaths,
+		mrpResult.PlannedOrders,
+		mrpResult.Allocations,
+		mrpResult.ShortageReport,
+	), nil
+}
+
 // AnalyzeCriticalPathForDemand performs critical path analysis for a specific demand using MRP allocation results
 func (po *PlanningOrchestrator) AnalyzeCriticalPathForDemand(
 	ctx context.Context,
@@ -127,6 +166,7 @@ func (po *PlanningOrchestrator) AnalyzeCriticalPathForDemand(
 		demand.PartNumber,
 		demand.TargetSerial,
 		demand.Location,
+		demand.NeedDate,
 		topPaths,
 		mrpResult.Allocations,
 	)
@@ -159,6 +199,7 @@ func (po *PlanningOrchestrator) AnalyzeCriticalPathWithMRPResults(
 	partNumber entities.PartNumber,
 	targetSerial string,
 	location string,
+	needDate time.Time,
 	topPaths int,
 	mrpResult *dto.MRPResult,
 ) (*entities.CriticalPathAnalysis, error) {
@@ -167,7 +208,31 @@ func (po *PlanningOrchestrator) AnalyzeCriticalPathWithMRPResults(
 		partNumber,
 		targetSerial,
 		location,
+		needDate,
+		topPaths,
+		mrpResult.Allocations,
+	)
+}
+
+// AnalyzeCriticalPathFromSchedule performs critical path analysis on the planned order dates of
+// existing MRP results, matching the Gantt chart
+func (po *PlanningOrchestrator) AnalyzeCriticalPathFromSchedule(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	targetSerial string,
+	location string,
+	needDate time.Time,
+	topPaths int,
+	mrpResult *dto.MRPResult,
+) (*entities.CriticalPathAnalysis, error) {
+	return po.criticalPathService.AnalyzeCriticalPathFromSchedule(
+		ctx,
+		partNumber,
+		targetSerial,
+		location,
+		needDate,
 		topPaths,
+		mrpResult.PlannedOrders,
 		mrpResult.Allocations,
 	)
 }
@@ -180,6 +245,11 @@ func (result *PlanningResult) GetSummary() string {
 		len(result.MRPResult.Allocations),
 		len(result.MRPResult.ShortageReport))
 	summary += fmt.Sprintf("  Critical Path: %s\n", result.CriticalPath.GetCriticalPathSummary())
+	summary += fmt.Sprintf("  Program: %d demands, %d late, %d bottleneck parts, %d shared components\n",
+		len(result.Program.Demands),
+		len(result.Program.LateDemands()),
+		len(result.Program.Bottlenecks),
+		len(result.Program.SharedComponents))
 	summary += fmt.Sprintf(
 		"  Inventory Coverage: %.1f%%",
 		result.CriticalPath.GetInventoryCoverage(),
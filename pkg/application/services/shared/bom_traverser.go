@@ -3,6 +3,8 @@ package shared
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
 	"github.com/vsinha/mrp/pkg/domain/repositories"
@@ -14,9 +16,13 @@ type BOMNodeContext struct {
 	Item              *entities.Item
 	Quantity          entities.Quantity
 	TargetSerial      string
+	EndItem           entities.PartNumber // Root of the traversal, whose serial TargetSerial is
 	Location          string
 	Level             int
-	AllocationContext *AllocationContext // Optional allocation info
+	AllocationContext *AllocationContext  // Optional allocation info
+	BOMLine           *entities.BOMLine   // Line that introduced this node from its parent (nil at the root)
+	NeedDate          time.Time           // Backward-scheduled need date (zero when traversing without dates)
+	Alternates        []*entities.BOMLine // Effective lines at BOMLine's find number, BOMLine included (nil at the root)
 }
 
 // BOMNodeVisitor defines the interface for processing nodes during BOM traversal
@@ -82,16 +88,65 @@ func (bt *BOMTraverser) ClearAllocationContext() {
 	bt.allocationMap.Clear()
 }
 
-// TraverseBOM performs BOM traversal with alternate selection using the visitor pattern
+// TraverseBOM performs BOM traversal with alternate selection using the visitor pattern.
+// needDate is when the root is required; each child's need date is backward scheduled from
+// it and used to evaluate date effectivity. A zero needDate disables date effectivity.
+// targetSerial is a serial of the root part, so effectivity is resolved with the root's serial
+// schemes at every level. A part reached again below itself stops the traversal with an
+// *entities.CyclicBOMError, an assembly none of whose lines is effective stops it with an
+// *entities.NoEffectiveAlternateError, and a cancelled ctx stops it with ctx.Err().
 func (bt *BOMTraverser) TraverseBOM(
 	ctx context.Context,
 	partNumber entities.PartNumber,
 	targetSerial string,
 	location string,
 	quantity entities.Quantity,
+	needDate time.Time,
 	level int,
 	visitor BOMNodeVisitor,
 ) (interface{}, error) {
+	return bt.traverse(
+		ctx,
+		partNumber,
+		targetSerial,
+		location,
+		quantity,
+		needDate,
+		level,
+		nil,
+		nil,
+		nil,
+		visitor,
+	)
+}
+
+// traverse visits a node reached through bomLine, selected from alternates (both nil at the root),
+// and recurses into its children. path holds the ancestors of the node, root first.
+func (bt *BOMTraverser) traverse(
+	ctx context.Context,
+	partNumber entities.PartNumber,
+	targetSerial string,
+	location string,
+	quantity entities.Quantity,
+	needDate time.Time,
+	level int,
+	bomLine *entities.BOMLine,
+	alternates []*entities.BOMLine,
+	path []entities.PartNumber,
+	visitor BOMNodeVisitor,
+) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, ancestor := range path {
+		if ancestor == partNumber {
+			cycle := append(append([]entities.PartNumber{}, path[i:]...), partNumber)
+			return nil, &entities.CyclicBOMError{Cycle: cycle}
+		}
+	}
+	path = append(path, partNumber)
+
 	// Get item master data
 	item, err := bt.itemRepo.GetItem(partNumber)
 	if err != nil {
@@ -107,9 +162,13 @@ func (bt *BOMTraverser) TraverseBOM(
 		Item:              item,
 		Quantity:          quantity,
 		TargetSerial:      targetSerial,
+		EndItem:           path[0],
 		Location:          location,
 		Level:             level,
 		AllocationContext: allocationCtx,
+		BOMLine:           bomLine,
+		NeedDate:          needDate,
+		Alternates:        alternates,
 	}
 
 	// Visit this node
@@ -129,14 +188,23 @@ func (bt *BOMTraverser) TraverseBOM(
 		return nil, fmt.Errorf("failed to get alternate groups for %s: %w", partNumber, err)
 	}
 
+	// Visit find numbers in order, so children are always reported in the same order
+	findNumbers := make([]int, 0, len(alternateGroups))
+	for findNumber := range alternateGroups {
+		findNumbers = append(findNumbers, findNumber)
+	}
+	sort.Ints(findNumbers)
+
 	var childResults []interface{}
 
 	// For each FindNumber group, select best alternate and traverse
-	for findNumber := range alternateGroups {
+	for _, findNumber := range findNumbers {
 		effectiveAlternates, err := bt.bomRepo.GetEffectiveAlternates(
 			partNumber,
 			findNumber,
+			path[0],
 			targetSerial,
+			needDate,
 		)
 		if err != nil {
 			return nil, fmt.Errorf(
@@ -148,7 +216,7 @@ func (bt *BOMTraverser) TraverseBOM(
 		}
 
 		if len(effectiveAlternates) == 0 {
-			continue // No effective alternates for this serial
+			continue // No effective alternates for this serial and date
 		}
 
 		// Select the best alternate from effective ones
@@ -159,13 +227,17 @@ func (bt *BOMTraverser) TraverseBOM(
 
 		// Recursively traverse the selected alternate
 		childQty := selectedAlternate.QtyPer * quantity
-		childResult, err := bt.TraverseBOM(
+		childResult, err := bt.traverse(
 			ctx,
 			selectedAlternate.ChildPN,
 			targetSerial,
 			location,
 			childQty,
+			ChildNeedDate(needDate, item, selectedAlternate),
 			level+1,
+			selectedAlternate,
+			effectiveAlternates,
+			path,
 			visitor,
 		)
 		if err != nil {
@@ -179,6 +251,31 @@ func (bt *BOMTraverser) TraverseBOM(
 		childResults = append(childResults, childResult)
 	}
 
+	// An assembly none of whose lines is in effect can't be built for this serial and date
+	if len(findNumbers) > 0 && len(childResults) == 0 {
+		return nil, &entities.NoEffectiveAlternateError{ParentPN: partNumber, Serial: targetSerial}
+	}
+
 	// Let visitor process the children results
 	return visitor.ProcessChildren(ctx, nodeCtx, nodeData, childResults)
 }
+
+// ChildNeedDate backward schedules a component: it is needed when its parent starts, plus the
+// lead time offset of the BOM line that consumes it (never later than the parent's need date).
+// A zero parent need date yields a zero child need date.
+func ChildNeedDate(
+	parentNeedDate time.Time,
+	parent *entities.Item,
+	line *entities.BOMLine,
+) time.Time {
+	if parentNeedDate.IsZero() {
+		return time.Time{}
+	}
+
+	parentStart := parentNeedDate.AddDate(0, 0, -parent.PlanningLeadTimeDays())
+	needDate := parentStart.AddDate(0, 0, line.LeadTimeOffsetDays)
+	if needDate.After(parentNeedDate) {
+		return parentNeedDate
+	}
+	return needDate
+}
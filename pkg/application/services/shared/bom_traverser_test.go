@@ -0,0 +1,125 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+// countingVisitor counts the nodes it visits
+type countingVisitor struct {
+	visited int
+}
+
+func (v *countingVisitor) VisitNode(ctx context.Context, nodeCtx BOMNodeContext) (interface{}, bool, error) {
+	v.visited++
+	return nil, true, nil
+}
+
+func (v *countingVisitor) ProcessChildren(
+	ctx context.Context,
+	nodeCtx BOMNodeContext,
+	nodeData interface{},
+	childResults []interface{},
+) (interface{}, error) {
+	return nil, nil
+}
+
+func TestBOMTraverser_CyclicBOM(t *testing.T) {
+	bomRepo := memory.NewBOMRepository(3)
+	itemRepo := memory.NewItemRepository(3)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	for _, pn := range []entities.PartNumber{"STAGE", "ENGINE", "TURBOPUMP"} {
+		if err := itemRepo.SaveItem(&entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  30,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   1,
+			MaxOrderQty:   10,
+			UnitOfMeasure: "EA",
+		}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// STAGE -> ENGINE -> TURBOPUMP -> ENGINE; SaveBOMLine does not reject cycles
+	for _, line := range []*entities.BOMLine{
+		{ParentPN: "STAGE", ChildPN: "ENGINE", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+		{ParentPN: "ENGINE", ChildPN: "TURBOPUMP", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+		{ParentPN: "TURBOPUMP", ChildPN: "ENGINE", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+	} {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	traverser := NewBOMTraverser(bomRepo, itemRepo, inventoryRepo)
+	visitor := &countingVisitor{}
+	_, err := traverser.TraverseBOM(context.Background(), "STAGE", "SN001", "FACTORY", 1, time.Time{}, 0, visitor)
+
+	var cyclic *entities.CyclicBOMError
+	if !errors.As(err, &cyclic) {
+		t.Fatalf("Expected a CyclicBOMError, got %v", err)
+	}
+	expected := []entities.PartNumber{"ENGINE", "TURBOPUMP", "ENGINE"}
+	if fmt.Sprint(cyclic.Cycle) != fmt.Sprint(expected) {
+		t.Errorf("Expected cycle %v, got %v", expected, cyclic.Cycle)
+	}
+	if visitor.visited != 3 {
+		t.Errorf("Expected STAGE, ENGINE and TURBOPUMP to be visited once each, got %d visits", visitor.visited)
+	}
+}
+
+func TestBOMTraverser_SharedComponentIsNotACycle(t *testing.T) {
+	bomRepo := memory.NewBOMRepository(3)
+	itemRepo := memory.NewItemRepository(3)
+	inventoryRepo := memory.NewInventoryRepository()
+
+	for _, pn := range []entities.PartNumber{"STAGE", "ENGINE", "TURBOPUMP"} {
+		if err := itemRepo.SaveItem(&entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  30,
+			LotSizeRule:   entities.LotForLot,
+			MinOrderQty:   1,
+			MaxOrderQty:   10,
+			UnitOfMeasure: "EA",
+		}); err != nil {
+			t.Fatalf("Failed to save item: %v", err)
+		}
+	}
+
+	// TURBOPUMP is used both directly by STAGE and below ENGINE
+	for _, line := range []*entities.BOMLine{
+		{ParentPN: "STAGE", ChildPN: "ENGINE", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+		{ParentPN: "STAGE", ChildPN: "TURBOPUMP", QtyPer: 1, FindNumber: 200,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+		{ParentPN: "ENGINE", ChildPN: "TURBOPUMP", QtyPer: 1, FindNumber: 100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+	} {
+		if err := bomRepo.SaveBOMLine(line); err != nil {
+			t.Fatalf("Failed to save BOM line: %v", err)
+		}
+	}
+
+	traverser := NewBOMTraverser(bomRepo, itemRepo, inventoryRepo)
+	visitor := &countingVisitor{}
+	if _, err := traverser.TraverseBOM(context.Background(), "STAGE", "SN001", "FACTORY", 1,
+		time.Time{}, 0, visitor); err != nil {
+		t.Fatalf("Expected no error for a shared component, got %v", err)
+	}
+	if visitor.visited != 4 {
+		t.Errorf("Expected 4 visits (TURBOPUMP twice), got %d", visitor.visited)
+	}
+}
@@ -0,0 +1,17 @@
+package shared
+
+import (
+	"math"
+	"time"
+)
+
+// DateOf returns the midnight starting a time's day, so that differences count calendar days
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// DaysBetween returns the number of calendar days from one date to another, rounding away the
+// hour lost or gained over a daylight saving change
+func DaysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,31 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysBetween(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		from, to time.Time
+		expected int
+	}{
+		{"same day", time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC), 0},
+		{"a week later", time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 1, 0, 0, 0, time.UTC), 7},
+		{"a week earlier", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), -7},
+		// Clocks spring forward on March 8, so these midnights are 6 days and 23 hours apart
+		{"over daylight saving", time.Date(2026, 3, 2, 0, 0, 0, 0, newYork), time.Date(2026, 3, 9, 0, 0, 0, 0, newYork), 7},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if days := DaysBetween(DateOf(tc.from), DateOf(tc.to)); days != tc.expected {
+				t.Errorf("Expected %d days, got %d", tc.expected, days)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/services"
+	"github.com/vsinha/mrp/pkg/domain/services/bom_validator"
+)
+
+// ScenarioData is the raw content of a scenario, before any repository is built from it
+type ScenarioData struct {
+	Items           []*entities.Item
+	BOMLines        []*entities.BOMLine
+	LotInventory    []*entities.InventoryLot
+	SerialInventory []*entities.SerializedInventory
+	Demands         []*entities.DemandRequirement
+	ECOs            []*entities.ECO
+}
+
+// ValidationService runs consistency checks over scenario data
+type ValidationService struct {
+	serialComp *services.SerialComparator
+}
+
+// NewValidationService creates a new validation service
+func NewValidationService(serialComp *services.SerialComparator) *ValidationService {
+	if serialComp == nil {
+		serialComp = services.NewSerialComparator()
+	}
+	return &ValidationService{
+		serialComp: serialComp,
+	}
+}
+
+// Validate runs every check over the scenario and returns the findings, errors first
+func (s *ValidationService) Validate(data ScenarioData) *dto.ValidationReport {
+	itemsByPN := make(map[entities.PartNumber]*entities.Item, len(data.Items))
+	for _, item := range data.Items {
+		itemsByPN[item.PartNumber] = item
+	}
+
+	var issues []dto.ValidationIssue
+	issues = append(issues, s.ValidateStructure(data.BOMLines, data.Items)...)
+	issues = append(issues, s.ValidateReachability(data.BOMLines, data.Items, data.Demands)...)
+	issues = append(issues, s.ValidateSerials(data.BOMLines, data.Demands, data.ECOs)...)
+	issues = append(issues, s.ValidateEffectivity(data.BOMLines)...)
+	issues = append(issues, s.ValidateLotSizing(data.Items)...)
+	issues = append(issues, s.ValidateInventory(data.LotInventory, data.SerialInventory, itemsByPN)...)
+	issues = append(issues, s.ValidateDemands(data.Demands, data.BOMLines, itemsByPN)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity < issues[j].Severity
+	})
+
+	report := &dto.ValidationReport{Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == dto.SeverityError {
+			report.ErrorCount++
+		} else {
+			report.WarningCount++
+		}
+	}
+	return report
+}
+
+// ValidateStructure checks the BOM for cycles, duplicate lines and parts missing from the
+// item master
+func (s *ValidationService) ValidateStructure(
+	bomLines []*entities.BOMLine,
+	items []*entities.Item,
+) []dto.ValidationIssue {
+	bomSlice := make([]entities.BOMLine, len(bomLines))
+	for i, line := range bomLines {
+		bomSlice[i] = *line
+	}
+	itemSlice := make([]entities.Item, len(items))
+	for i, item := range items {
+		itemSlice[i] = *item
+	}
+
+	var issues []dto.ValidationIssue
+
+	structure := bom_validator.ValidateBOM(bomSlice)
+	for _, cycle := range structure.CyclePaths {
+		path := make([]string, len(cycle))
+		for i, pn := range cycle {
+			path[i] = string(pn)
+		}
+		issues = append(issues, newIssue(dto.SeverityError, "BOMCycle", cycle[0], 0,
+			"BOM cycle: "+strings.Join(path, " -> ")))
+	}
+
+	// Duplicates come back as (duplicate, first occurrence) pairs
+	for i := 0; i+1 < len(structure.DuplicateLines); i += 2 {
+		line := structure.DuplicateLines[i]
+		issues = append(issues, newIssue(dto.SeverityWarning, "DuplicateBOMLine", line.ParentPN, line.FindNumber,
+			fmt.Sprintf("%s is listed more than once with the same effectivity", line.ChildPN)))
+	}
+
+	consistency := bom_validator.ValidateBOMItemConsistency(bomSlice, itemSlice)
+	orphaned := append([]entities.PartNumber{}, consistency.OrphanedParts...)
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i] < orphaned[j] })
+	for _, pn := range orphaned {
+		issues = append(issues, newIssue(dto.SeverityError, "OrphanedPart", pn, 0,
+			"used in the BOM but not defined in the item master"))
+	}
+
+	return issues
+}
+
+// ValidateReachability reports items that are neither demanded nor used, at any depth, in
+// the BOM of a demanded part. Without demands there is nothing to reach from, so nothing is
+// reported.
+func (s *ValidationService) ValidateReachability(
+	bomLines []*entities.BOMLine,
+	items []*entities.Item,
+	demands []*entities.DemandRequirement,
+) []dto.ValidationIssue {
+	if len(demands) == 0 {
+		return nil
+	}
+
+	children := make(map[entities.PartNumber][]entities.PartNumber)
+	for _, line := range bomLines {
+		children[line.ParentPN] = append(children[line.ParentPN], line.ChildPN)
+	}
+
+	reached := make(map[entities.PartNumber]bool)
+	var queue []entities.PartNumber
+	for _, demand := range demands {
+		if !reached[demand.PartNumber] {
+			reached[demand.PartNumber] = true
+			queue = append(queue, demand.PartNumber)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if !reached[child] {
+				reached[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var issues []dto.ValidationIssue
+	for _, item := range items {
+		if !reached[item.PartNumber] {
+			issues = append(issues, newIssue(dto.SeverityWarning, "UnreachableItem", item.PartNumber, 0,
+				"not demanded and not used in the BOM of any demanded part"))
+		}
+	}
+	return issues
+}
+
+// ValidateSerials checks that demand serials fit the demanded parts' serial schemes, and BOM
+// effectivity and ECO cut-in serials fit those of an end item above their lines
+func (s *ValidationService) ValidateSerials(
+	bomLines []*entities.BOMLine,
+	demands []*entities.DemandRequirement,
+	ecos []*entities.ECO,
+) []dto.ValidationIssue {
+	above := services.EndItemsAbove(bomLines)
+	var issues []dto.ValidationIssue
+	for _, line := range bomLines {
+		for _, serial := range []string{line.Effectivity.FromSerial, line.Effectivity.ToSerial} {
+			if serial == "" {
+				continue
+			}
+			if err := s.serialComp.ValidateEffectivitySerial(above, line.ParentPN, serial); err != nil {
+				issues = append(issues, newIssue(dto.SeverityError, "InvalidSerial", line.ParentPN, line.FindNumber,
+					fmt.Sprintf("%s effectivity: %v", line.ChildPN, err)))
+			}
+		}
+	}
+
+	for _, demand := range demands {
+		if demand.TargetSerial == "" {
+			continue
+		}
+		if err := s.serialComp.ValidateSerial(demand.PartNumber, demand.TargetSerial); err != nil {
+			issues = append(issues, newIssue(dto.SeverityError, "InvalidSerial", demand.PartNumber, 0,
+				fmt.Sprintf("demand %s: %v", demand.DemandSource, err)))
+		}
+	}
+
+	for _, e := range ecos {
+		if e.CutInSerial == "" {
+			continue
+		}
+		for _, change := range e.Changes {
+			if err := s.serialComp.ValidateEffectivitySerial(above, change.Line.ParentPN, e.CutInSerial); err != nil {
+				issues = append(issues, newIssue(dto.SeverityError, "InvalidSerial", change.Line.ParentPN,
+					change.Line.FindNumber, fmt.Sprintf("ECO %s cut-in: %v", e.ID, err)))
+			}
+		}
+	}
+	return issues
+}
+
+// ValidateEffectivity checks every parent find number group for serial gaps, overlapping
+// alternates with the same priority and inverted ranges. Overlaps are warnings: planning
+// still picks one of the alternates.
+func (s *ValidationService) ValidateEffectivity(bomLines []*entities.BOMLine) []dto.ValidationIssue {
+	var issues []dto.ValidationIssue
+	for _, issue := range s.serialComp.ValidateEffectivityCoverage(bomLines) {
+		severity := dto.SeverityError
+		if issue.Type == services.EffectivityOverlap {
+			severity = dto.SeverityWarning
+		}
+		issues = append(issues, newIssue(severity, "Effectivity"+issue.Type.String(),
+			issue.ParentPN, issue.FindNumber, issue.Message))
+	}
+	return issues
+}
+
+// ValidateLotSizing reports order quantity settings that planning silently ignores
+func (s *ValidationService) ValidateLotSizing(items []*entities.Item) []dto.ValidationIssue {
+	var issues []dto.ValidationIssue
+	for _, item := range items {
+		var message string
+		switch {
+		case item.Phantom && item.LotSizeRule != entities.LotForLot:
+			message = fmt.Sprintf("phantom items are never ordered, so lot sizing rule %s is ignored",
+				item.LotSizeRule)
+		case item.LotSizeRule == entities.LotForLot && item.MinOrderQty > 1:
+			message = fmt.Sprintf("min order qty %d is ignored by the LotForLot rule", item.MinOrderQty)
+		case item.LotSizeRule == entities.StandardPack && item.MinOrderQty > 0 &&
+			item.MaxOrderQty%item.MinOrderQty != 0:
+			message = fmt.Sprintf("max order qty %d is not a multiple of the pack size %d",
+				item.MaxOrderQty, item.MinOrderQty)
+		default:
+			continue
+		}
+		issues = append(issues, newIssue(dto.SeverityWarning, "LotSizing", item.PartNumber, 0, message))
+	}
+	return issues
+}
+
+// ValidateInventory reports inventory held for parts missing from the item master; planning
+// never allocates it
+func (s *ValidationService) ValidateInventory(
+	lotInventory []*entities.InventoryLot,
+	serialInventory []*entities.SerializedInventory,
+	itemsByPN map[entities.PartNumber]*entities.Item,
+) []dto.ValidationIssue {
+	var issues []dto.ValidationIssue
+	for _, lot := range lotInventory {
+		if itemsByPN[lot.PartNumber] == nil {
+			issues = append(issues, newIssue(dto.SeverityWarning, "UnknownInventoryPart", lot.PartNumber, 0,
+				fmt.Sprintf("lot %s is for a part not in the item master", lot.LotNumber)))
+		}
+	}
+	for _, unit := range serialInventory {
+		if itemsByPN[unit.PartNumber] == nil {
+			issues = append(issues, newIssue(dto.SeverityWarning, "UnknownInventoryPart", unit.PartNumber, 0,
+				fmt.Sprintf("serial %s is for a part not in the item master", unit.SerialNumber)))
+		}
+	}
+	return issues
+}
+
+// ValidateDemands reports demands for parts missing from the item master, and demands whose
+// serial is outside the effectivity of every BOM line of the demanded assembly, which would
+// plan the assembly without any components
+func (s *ValidationService) ValidateDemands(
+	demands []*entities.DemandRequirement,
+	bomLines []*entities.BOMLine,
+	itemsByPN map[entities.PartNumber]*entities.Item,
+) []dto.ValidationIssue {
+	linesByParent := make(map[entities.PartNumber][]*entities.BOMLine)
+	for _, line := range bomLines {
+		linesByParent[line.ParentPN] = append(linesByParent[line.ParentPN], line)
+	}
+
+	var issues []dto.ValidationIssue
+	for _, demand := range demands {
+		if itemsByPN[demand.PartNumber] == nil {
+			issues = append(issues, newIssue(dto.SeverityError, "DemandUnknownPart", demand.PartNumber, 0,
+				fmt.Sprintf("demand %s is for a part not in the item master", demand.DemandSource)))
+			continue
+		}
+
+		lines := linesByParent[demand.PartNumber]
+		if demand.TargetSerial == "" || len(lines) == 0 {
+			continue
+		}
+		effective := false
+		for _, line := range lines {
+			if s.serialComp.IsSerialInRangeForPart(demand.PartNumber, demand.TargetSerial, line.Effectivity) {
+				effective = true
+				break
+			}
+		}
+		if !effective {
+			issues = append(issues, newIssue(dto.SeverityError, "DemandUnknownSerial", demand.PartNumber, 0,
+				fmt.Sprintf("demand %s is for serial %s, which no BOM line of %s is effective for",
+					demand.DemandSource, demand.TargetSerial, demand.PartNumber)))
+		}
+	}
+	return issues
+}
+
+// newIssue creates a validation issue with its severity name filled in
+func newIssue(
+	severity dto.ValidationSeverity,
+	check string,
+	partNumber entities.PartNumber,
+	findNumber int,
+	message string,
+) dto.ValidationIssue {
+	return dto.ValidationIssue{
+		Severity:     severity,
+		SeverityName: severity.String(),
+		Check:        check,
+		PartNumber:   partNumber,
+		FindNumber:   findNumber,
+		Message:      message,
+	}
+}
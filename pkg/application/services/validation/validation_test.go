@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+func TestValidationService_ValidateEffectivity(t *testing.T) {
+	lines := []*entities.BOMLine{
+		{
+			ParentPN:    "F1_ENGINE",
+			ChildPN:     "F1_TURBOPUMP_V1",
+			QtyPer:      1,
+			FindNumber:  100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA501", ToSerial: "SA507"},
+		},
+		{
+			ParentPN:    "F1_ENGINE",
+			ChildPN:     "F1_TURBOPUMP_V2",
+			QtyPer:      1,
+			FindNumber:  100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA509", ToSerial: ""},
+		},
+		{
+			ParentPN:    "F1_ENGINE",
+			ChildPN:     "VALVE_MAIN",
+			QtyPer:      6,
+			FindNumber:  200,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""},
+		},
+	}
+
+	issues := NewValidationService(nil).ValidateEffectivity(lines)
+	if len(issues) != 1 {
+		t.Fatalf("Expected one gap, got %+v", issues)
+	}
+	if issues[0].Check != "EffectivityGap" || issues[0].Severity != dto.SeverityError ||
+		issues[0].PartNumber != "F1_ENGINE" || issues[0].FindNumber != 100 {
+		t.Errorf("Expected an EffectivityGap at F1_ENGINE find 100, got %+v", issues[0])
+	}
+}
+
+func TestValidationService_Validate(t *testing.T) {
+	item := func(pn entities.PartNumber, rule entities.LotSizeRule, minQty, maxQty entities.Quantity) *entities.Item {
+		return &entities.Item{
+			PartNumber:    pn,
+			Description:   string(pn),
+			LeadTimeDays:  30,
+			LotSizeRule:   rule,
+			MinOrderQty:   minQty,
+			MaxOrderQty:   maxQty,
+			UnitOfMeasure: "EA",
+		}
+	}
+	line := func(parent, child entities.PartNumber, findNumber int, from string) *entities.BOMLine {
+		return &entities.BOMLine{
+			ParentPN:    parent,
+			ChildPN:     child,
+			QtyPer:      1,
+			FindNumber:  findNumber,
+			Effectivity: entities.SerialEffectivity{FromSerial: from, ToSerial: ""},
+		}
+	}
+
+	data := ScenarioData{
+		Items: []*entities.Item{
+			item("F1_ENGINE", entities.LotForLot, 1, 10),
+			item("F1_TURBOPUMP", entities.LotForLot, 1, 10),
+			item("GSE_CART", entities.LotForLot, 1, 10),
+			item("BOLT", entities.StandardPack, 50, 120),
+			item("HEAT_SHIELD", entities.LotForLot, 1, 10),
+		},
+		BOMLines: []*entities.BOMLine{
+			line("F1_ENGINE", "F1_TURBOPUMP", 100, "SA501"),
+			line("F1_ENGINE", "F1_TURBOPUMP", 100, "SA501"),
+			line("F1_ENGINE", "BOLT", 200, "SA501"),
+			line("F1_ENGINE", "INJECTOR", 300, "SA501"),
+			line("F1_TURBOPUMP", "HEAT_SHIELD", 100, "SA501"),
+			line("HEAT_SHIELD", "F1_TURBOPUMP", 100, "SA501"),
+		},
+		LotInventory: []*entities.InventoryLot{
+			{PartNumber: "NOZZLE", LotNumber: "LOT-1", Location: "MAIN", Quantity: 5},
+		},
+		Demands: []*entities.DemandRequirement{
+			{PartNumber: "F1_ENGINE", Quantity: 1, DemandSource: "APOLLO_8", TargetSerial: "SA503"},
+			{PartNumber: "F1_ENGINE", Quantity: 1, DemandSource: "PROTOTYPE", TargetSerial: "SA100"},
+			{PartNumber: "J2_ENGINE", Quantity: 1, DemandSource: "APOLLO_9", TargetSerial: "SA504"},
+		},
+	}
+
+	report := NewValidationService(nil).Validate(data)
+
+	found := make(map[string]dto.ValidationIssue)
+	for _, issue := range report.Issues {
+		found[issue.Check] = issue
+	}
+
+	expected := map[string]dto.ValidationSeverity{
+		"BOMCycle":             dto.SeverityError,
+		"OrphanedPart":         dto.SeverityError,
+		"DemandUnknownPart":    dto.SeverityError,
+		"DemandUnknownSerial":  dto.SeverityError,
+		"DuplicateBOMLine":     dto.SeverityWarning,
+		"EffectivityOverlap":   dto.SeverityWarning,
+		"UnreachableItem":      dto.SeverityWarning,
+		"LotSizing":            dto.SeverityWarning,
+		"UnknownInventoryPart": dto.SeverityWarning,
+	}
+	for check, severity := range expected {
+		issue, ok := found[check]
+		if !ok {
+			t.Errorf("Expected a %s finding, got %+v", check, report.Issues)
+			continue
+		}
+		if issue.Severity != severity || issue.SeverityName != severity.String() {
+			t.Errorf("Expected %s to be a %s, got %+v", check, severity, issue)
+		}
+	}
+
+	if found["UnreachableItem"].PartNumber != "GSE_CART" {
+		t.Errorf("Expected GSE_CART to be unreachable, got %+v", found["UnreachableItem"])
+	}
+	if found["DemandUnknownSerial"].Message == "" || found["OrphanedPart"].PartNumber != "INJECTOR" {
+		t.Errorf("Unexpected findings: %+v", report.Issues)
+	}
+
+	// Errors are listed before warnings and counted separately
+	if !report.HasErrors() || report.ErrorCount+report.WarningCount != len(report.Issues) {
+		t.Errorf("Expected error and warning counts to cover all issues, got %+v", report)
+	}
+	for i := 1; i < len(report.Issues); i++ {
+		if report.Issues[i-1].Severity > report.Issues[i].Severity {
+			t.Fatalf("Expected errors before warnings, got %+v", report.Issues)
+		}
+	}
+}
+
+func TestValidationService_Validate_CleanScenario(t *testing.T) {
+	data := ScenarioData{
+		Items: []*entities.Item{
+			{PartNumber: "F1_ENGINE", LotSizeRule: entities.LotForLot, MinOrderQty: 1, MaxOrderQty: 10},
+			{PartNumber: "F1_TURBOPUMP", LotSizeRule: entities.LotForLot, MinOrderQty: 1, MaxOrderQty: 10},
+		},
+		BOMLines: []*entities.BOMLine{
+			{
+				ParentPN:    "F1_ENGINE",
+				ChildPN:     "F1_TURBOPUMP",
+				QtyPer:      1,
+				FindNumber:  100,
+				Effectivity: entities.SerialEffectivity{FromSerial: "SA501", ToSerial: ""},
+			},
+		},
+		Demands: []*entities.DemandRequirement{
+			{PartNumber: "F1_ENGINE", Quantity: 1, DemandSource: "APOLLO_8", TargetSerial: "SA503"},
+		},
+	}
+
+	report := NewValidationService(nil).Validate(data)
+	if len(report.Issues) != 0 || report.HasErrors() {
+		t.Errorf("Expected no findings, got %+v", report.Issues)
+	}
+}
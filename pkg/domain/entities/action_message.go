@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+// ActionType is what an action message asks a planner to do. Types are ordered by urgency.
+type ActionType int
+
+const (
+	ActionExpedite ActionType = iota // Pull a scheduled receipt in to its need date
+	ActionFirm                       // Firm a planned order that a time fence kept MRP from creating
+	ActionRelease                    // Release a planned order that should start now
+	ActionIncrease                   // Increase a scheduled receipt instead of releasing a new order
+	ActionCancel                     // Cancel a scheduled receipt, or the part of it nothing needs
+	ActionPushOut                    // Push a scheduled receipt out to its need date
+)
+
+// String method for ActionType enum
+func (a ActionType) String() string {
+	switch a {
+	case ActionExpedite:
+		return "Expedite"
+	case ActionFirm:
+		return "Firm"
+	case ActionRelease:
+		return "Release"
+	case ActionIncrease:
+		return "Increase"
+	case ActionCancel:
+		return "Cancel"
+	case ActionPushOut:
+		return "Push Out"
+	default:
+		return "Unknown"
+	}
+}
+
+// ActionMessage tells a planner how to bring a scheduled receipt or planned order in line
+// with the plan
+type ActionMessage struct {
+	Action      ActionType `json:"action"`
+	PartNumber  PartNumber `json:"part_number"`
+	Location    string     `json:"location"`
+	OrderNumber string     `json:"order_number,omitempty"` // Scheduled receipt or firm planned order acted on; empty for planned orders
+	Quantity    Quantity   `json:"quantity"`               // Units to release, add or cancel; the receipt quantity for reschedules
+	DueDate     time.Time  `json:"due_date"`               // Current due date of the receipt or planned order; zero when there is none
+	NeedDate    time.Time  `json:"need_date"`              // When the plan first needs it
+	Days        int        `json:"days"`                   // Days to expedite or push out; days late for releases and firm orders
+}
@@ -1,6 +1,9 @@
 package entities
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // SerialEffectivity defines the range of serials for which a BOM line is effective
 type SerialEffectivity struct {
@@ -22,6 +25,55 @@ func NewSerialEffectivity(fromSerial, toSerial string) (*SerialEffectivity, erro
 	}, nil
 }
 
+// DateEffectivity defines the date window in which a BOM line is effective
+type DateEffectivity struct {
+	FromDate time.Time // zero = effective from the beginning
+	ToDate   time.Time // zero = open ended
+}
+
+// NewDateEffectivity creates a validated DateEffectivity
+func NewDateEffectivity(fromDate, toDate time.Time) (*DateEffectivity, error) {
+	if !fromDate.IsZero() && !toDate.IsZero() && toDate.Before(fromDate) {
+		return nil, fmt.Errorf(
+			"to date %s cannot be before from date %s",
+			toDate.Format("2006-01-02"),
+			fromDate.Format("2006-01-02"),
+		)
+	}
+
+	return &DateEffectivity{
+		FromDate: fromDate,
+		ToDate:   toDate,
+	}, nil
+}
+
+// IsEffectiveOn checks if a date falls within the (inclusive) effectivity window.
+// A zero date means no date is known, so date effectivity does not filter it out.
+func (d DateEffectivity) IsEffectiveOn(date time.Time) bool {
+	if date.IsZero() {
+		return true
+	}
+	if !d.FromDate.IsZero() && date.Before(d.FromDate) {
+		return false
+	}
+	if !d.ToDate.IsZero() && date.After(d.ToDate) {
+		return false
+	}
+	return true
+}
+
+// IsUnbounded reports whether the window places no date restriction at all
+func (d DateEffectivity) IsUnbounded() bool {
+	return d.FromDate.IsZero() && d.ToDate.IsZero()
+}
+
+// Overlaps reports whether two date windows share at least one day
+func (d DateEffectivity) Overlaps(other DateEffectivity) bool {
+	startsBeforeOtherEnds := other.ToDate.IsZero() || !d.FromDate.After(other.ToDate)
+	otherStartsBeforeEnd := d.ToDate.IsZero() || !other.FromDate.After(d.ToDate)
+	return startsBeforeOtherEnds && otherStartsBeforeEnd
+}
+
 // BOMLine represents a single line in a Bill of Materials
 type BOMLine struct {
 	ParentPN PartNumber
@@ -39,6 +91,11 @@ type BOMLine struct {
 
 	Effectivity SerialEffectivity
 
+	// DateEffectivity restricts the line to requirements needed within a date window, e.g.
+	// "use the V2 turbopump from 2025-09-01". A line is effective only when both its serial
+	// and date effectivity match; the zero value is effective on every date.
+	DateEffectivity DateEffectivity
+
 	// Priority determines selection order for alternates at the same FindNumber.
 	// Multiple BOM lines with the same ParentPN and FindNumber represent alternates.
 	// Lower numbers = higher priority (1 = primary, 2 = first alternate, etc.).
@@ -48,6 +105,13 @@ type BOMLine struct {
 	// with MRP selecting the highest priority part that satisfies serial effectivity.
 	// Inventory availability may also influence selection within the same priority level.
 	Priority int
+
+	// LeadTimeOffsetDays is the number of days after the parent starts that this component
+	// is actually needed. Zero means the component must be complete before the parent starts.
+	//
+	// Example: The S-IC stage build runs 240 days, but the F-1 nozzle is only needed 40 days
+	// in, so its offset is 40 and the nozzle can still be in work while the stage build begins.
+	LeadTimeOffsetDays int
 }
 
 // NewBOMLine creates a validated BOMLine
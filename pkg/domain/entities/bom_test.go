@@ -1,6 +1,9 @@
 package entities
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestBOMLine_Validation(t *testing.T) {
 	effectivity := SerialEffectivity{FromSerial: "SN001", ToSerial: ""}
@@ -118,6 +121,57 @@ func TestSerialEffectivity_Validation(t *testing.T) {
 	}
 }
 
+func TestDateEffectivity(t *testing.T) {
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	if _, err := NewDateEffectivity(to, from); err == nil {
+		t.Error("Expected error when to date is before from date")
+	}
+
+	window, err := NewDateEffectivity(from, to)
+	if err != nil {
+		t.Fatalf("Failed to create date effectivity: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected bool
+	}{
+		{"before window", from.AddDate(0, 0, -1), false},
+		{"from date inclusive", from, true},
+		{"inside window", from.AddDate(0, 1, 0), true},
+		{"to date inclusive", to, true},
+		{"after window", to.AddDate(0, 0, 1), false},
+		{"unknown date", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.IsEffectiveOn(tt.date); got != tt.expected {
+				t.Errorf("IsEffectiveOn(%s) = %v, expected %v", tt.date.Format("2006-01-02"), got, tt.expected)
+			}
+		})
+	}
+
+	openEnded := DateEffectivity{FromDate: from}
+	if !openEnded.IsEffectiveOn(to.AddDate(10, 0, 0)) {
+		t.Error("Expected open-ended window to be effective far in the future")
+	}
+	if !(DateEffectivity{}).IsUnbounded() {
+		t.Error("Expected zero value to be unbounded")
+	}
+
+	later := DateEffectivity{FromDate: to.AddDate(0, 0, 1)}
+	if window.Overlaps(later) || later.Overlaps(*window) {
+		t.Error("Expected windows on either side of a date not to overlap")
+	}
+	if !window.Overlaps(DateEffectivity{FromDate: to}) || !window.Overlaps(DateEffectivity{}) {
+		t.Error("Expected windows sharing a day, or an unbounded window, to overlap")
+	}
+}
+
 func TestBOMAlternatesExample(t *testing.T) {
 	// Example: F-1 engine can use different turbopump versions based on serial effectivity
 
@@ -2,20 +2,54 @@ package entities
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
+// CriticalPathMode represents how critical path analysis times parts
+type CriticalPathMode int
+
+const (
+	LeadTimeMode CriticalPathMode = iota // Item lead times, scaled down by inventory coverage
+	ScheduleMode                         // Dates of the planned orders from an MRP run
+)
+
+// String method for CriticalPathMode enum
+func (m CriticalPathMode) String() string {
+	switch m {
+	case LeadTimeMode:
+		return "Lead Time"
+	case ScheduleMode:
+		return "Schedule"
+	default:
+		return "Unknown"
+	}
+}
+
 // CriticalPathNode represents a node in the critical path analysis
 type CriticalPathNode struct {
-	PartNumber        PartNumber
-	Description       string
-	LeadTimeDays      int
-	CumulativeTime    int
-	Level             int
-	HasInventory      bool
-	InventoryQty      Quantity
-	RequiredQty       Quantity
-	EffectiveLeadTime int // Lead time after considering inventory
+	PartNumber         PartNumber
+	Description        string
+	LeadTimeDays       int
+	CumulativeTime     int
+	Level              int
+	HasInventory       bool
+	InventoryQty       Quantity
+	RequiredQty        Quantity
+	EffectiveLeadTime  int // Lead time after considering inventory, or planned order span in schedule mode
+	LeadTimeOffsetDays int // Days into the parent's build when this part is needed
+
+	// Span of the part's planned orders, in schedule mode (zero when inventory covers the part)
+	ScheduledStart time.Time
+	ScheduledDue   time.Time
+
+	// Schedule of the part in days from the start of the build, and how many days it can slip
+	EarliestStart  int
+	EarliestFinish int
+	LatestStart    int
+	LatestFinish   int
+	TotalFloat     int // Days the part can slip without delaying the top level part
+	FreeFloat      int // Days the part can slip without delaying any parent's earliest start
 }
 
 // CriticalPath represents a complete path through the BOM with timing information
@@ -33,10 +67,12 @@ type CriticalPathAnalysis struct {
 	TopLevelPart PartNumber
 	TargetSerial string
 	Location     string
+	Mode         CriticalPathMode
 	AnalysisDate time.Time
-	CriticalPath CriticalPath   // The longest path
-	TopPaths     []CriticalPath // Top N longest paths
-	TotalPaths   int            // Total number of paths analyzed
+	CriticalPath CriticalPath       // The longest path
+	TopPaths     []CriticalPath     // Top N longest paths
+	TotalPaths   int                // Total number of paths analyzed
+	PartFloat    []CriticalPathNode // Schedule and float of every part, least float first
 }
 
 // GetCriticalPathSummary returns a formatted summary of the critical path
@@ -85,3 +121,102 @@ func (analysis *CriticalPathAnalysis) GetInventoryCoverage() float64 {
 
 	return float64(pathsWithInventory) / float64(len(analysis.TopPaths)) * 100.0
 }
+
+// ZeroFloatParts returns the parts that cannot slip without delaying the top level part
+func (analysis *CriticalPathAnalysis) ZeroFloatParts() []CriticalPathNode {
+	var parts []CriticalPathNode
+	for _, node := range analysis.PartFloat {
+		if node.TotalFloat == 0 {
+			parts = append(parts, node)
+		}
+	}
+	return parts
+}
+
+// NearCriticalParts returns the parts with some float, but less than maxFloat days
+func (analysis *CriticalPathAnalysis) NearCriticalParts(maxFloat int) []CriticalPathNode {
+	var parts []CriticalPathNode
+	for _, node := range analysis.PartFloat {
+		if node.TotalFloat > 0 && node.TotalFloat < maxFloat {
+			parts = append(parts, node)
+		}
+	}
+	return parts
+}
+
+// Duration returns the days from the start of the build until the top level part finishes, on
+// the schedule the float was computed from
+func (analysis *CriticalPathAnalysis) Duration() int {
+	duration := 0
+	for _, node := range analysis.PartFloat {
+		if node.EarliestFinish > duration {
+			duration = node.EarliestFinish
+		}
+	}
+	return duration
+}
+
+// DemandCriticalPath is the critical path analysis of one demand, and when the demand completes
+// if its build starts on the as-of date of the program analysis
+type DemandCriticalPath struct {
+	Demand              *DemandRequirement
+	Analysis            *CriticalPathAnalysis
+	ProjectedCompletion time.Time
+	DaysLate            int // Days the projected completion is past the need date (negative when early)
+}
+
+// BottleneckPart is a part with no float in the build of one or more demands
+type BottleneckPart struct {
+	PartNumber   PartNumber
+	Description  string
+	LeadTimeDays int                  // Longest time the part takes in any gated demand's schedule
+	Demands      []*DemandRequirement // Demands the part gates, in demand order
+	LatenessDays int                  // Days of lateness of the gated demands the part accounts for
+}
+
+// SharedComponent is a part in the build of more than one demand, which the demands compete for
+type SharedComponent struct {
+	PartNumber   PartNumber
+	Description  string
+	Demands      []*DemandRequirement // Demands that use the part, in demand order
+	CriticalFor  int                  // Demands the part has no float in
+	AllocatedQty Quantity             // Units covered by inventory across the plan
+	PlannedQty   Quantity             // Units on planned orders across the plan
+	ShortQty     Quantity             // Units neither covered nor planned
+}
+
+// ProgramCriticalPath combines the critical paths of every demand in a plan
+type ProgramCriticalPath struct {
+	AsOf             time.Time            // Date the build of every demand starts from
+	Demands          []DemandCriticalPath // In demand order
+	Bottlenecks      []BottleneckPart     // Most demands gated first, then most lateness
+	SharedComponents []SharedComponent    // Most demands first, then most critical
+}
+
+// LateDemands returns the demands projected to complete after their need date, latest first
+func (program *ProgramCriticalPath) LateDemands() []DemandCriticalPath {
+	var late []DemandCriticalPath
+	for _, demand := range program.Demands {
+		if demand.DaysLate > 0 {
+			late = append(late, demand)
+		}
+	}
+	sort.SliceStable(late, func(i, j int) bool {
+		return late[i].DaysLate > late[j].DaysLate
+	})
+	return late
+}
+
+// MostCritical returns the demand with the least slack to its need date, the longest build
+// breaking ties, or nil when there are no demands
+func (program *ProgramCriticalPath) MostCritical() *DemandCriticalPath {
+	var most *DemandCriticalPath
+	for i := range program.Demands {
+		demand := &program.Demands[i]
+		if most == nil || demand.DaysLate > most.DaysLate ||
+			(demand.DaysLate == most.DaysLate && demand.Analysis.Duration() > most.Analysis.Duration()) {
+			most = demand
+		}
+	}
+	return most
+}
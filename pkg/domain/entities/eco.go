@@ -0,0 +1,151 @@
+package entities
+
+import "fmt"
+
+// ECOStatus represents the lifecycle state of an engineering change order
+type ECOStatus int
+
+const (
+	ECODraft ECOStatus = iota
+	ECOReleased
+)
+
+// String method for ECOStatus enum
+func (s ECOStatus) String() string {
+	switch s {
+	case ECODraft:
+		return "Draft"
+	case ECOReleased:
+		return "Released"
+	default:
+		return "Unknown"
+	}
+}
+
+// ECOAction represents the kind of BOM change an ECO line makes
+type ECOAction int
+
+const (
+	ECOAddLine ECOAction = iota
+	ECORemoveLine
+	ECOChangeEffectivity
+)
+
+// String method for ECOAction enum
+func (a ECOAction) String() string {
+	switch a {
+	case ECOAddLine:
+		return "Add"
+	case ECORemoveLine:
+		return "Remove"
+	case ECOChangeEffectivity:
+		return "Effectivity"
+	default:
+		return "Unknown"
+	}
+}
+
+// ECOChange is a single BOM line change within an ECO
+type ECOChange struct {
+	Action ECOAction
+
+	// Line is the full line to add (ECOAddLine), or identifies the existing line by
+	// ParentPN, ChildPN and FindNumber (ECORemoveLine, ECOChangeEffectivity)
+	Line BOMLine
+
+	// New effectivity for ECOChangeEffectivity
+	Effectivity     SerialEffectivity
+	DateEffectivity DateEffectivity
+}
+
+// Matches reports whether an existing BOM line is the target of a remove or effectivity change
+func (c ECOChange) Matches(line *BOMLine) bool {
+	return line.ParentPN == c.Line.ParentPN &&
+		line.ChildPN == c.Line.ChildPN &&
+		line.FindNumber == c.Line.FindNumber
+}
+
+// ECO represents an engineering change order bundling BOM changes that cut in at a serial
+type ECO struct {
+	ID          string
+	Description string
+	Status      ECOStatus
+	CutInSerial string // first serial the changes apply to; empty = all serials
+	Changes     []ECOChange
+}
+
+// NewECO creates a validated ECO
+func NewECO(
+	id, description string,
+	status ECOStatus,
+	cutInSerial string,
+	changes []ECOChange,
+) (*ECO, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ECO ID cannot be empty")
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("ECO %s must contain at least one change", id)
+	}
+
+	for i, change := range changes {
+		if change.Line.ParentPN == "" || change.Line.ChildPN == "" {
+			return nil, fmt.Errorf("ECO %s change %d: parent and child part numbers are required", id, i+1)
+		}
+		if change.Action == ECOAddLine && change.Line.QtyPer <= 0 {
+			return nil, fmt.Errorf(
+				"ECO %s change %d: qty per must be positive, got %d",
+				id,
+				i+1,
+				change.Line.QtyPer,
+			)
+		}
+	}
+
+	return &ECO{
+		ID:          id,
+		Description: description,
+		Status:      status,
+		CutInSerial: cutInSerial,
+		Changes:     changes,
+	}, nil
+}
+
+// IsReleased checks if the ECO has been released
+func (e *ECO) IsReleased() bool {
+	return e.Status == ECOReleased
+}
+
+// Apply returns the lines of a BOM with this ECO's changes applied. The input slice is not modified.
+func (e *ECO) Apply(lines []*BOMLine) []*BOMLine {
+	result := make([]*BOMLine, 0, len(lines))
+	for _, line := range lines {
+		kept := line
+		for _, change := range e.Changes {
+			if kept == nil || !change.Matches(kept) {
+				continue
+			}
+			switch change.Action {
+			case ECORemoveLine:
+				kept = nil
+			case ECOChangeEffectivity:
+				changed := *kept
+				changed.Effectivity = change.Effectivity
+				changed.DateEffectivity = change.DateEffectivity
+				kept = &changed
+			}
+		}
+		if kept != nil {
+			result = append(result, kept)
+		}
+	}
+
+	for _, change := range e.Changes {
+		if change.Action == ECOAddLine {
+			added := change.Line
+			result = append(result, &added)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,83 @@
+package entities
+
+import "testing"
+
+func TestNewECO_Validation(t *testing.T) {
+	add := ECOChange{
+		Action: ECOAddLine,
+		Line: BOMLine{
+			ParentPN:   "F1_ENGINE",
+			ChildPN:    "F1_TURBOPUMP_V2",
+			QtyPer:     1,
+			FindNumber: 100,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		changes []ECOChange
+		wantErr bool
+	}{
+		{"valid ECO", "ECO-001", []ECOChange{add}, false},
+		{"empty ID", "", []ECOChange{add}, true},
+		{"no changes", "ECO-001", nil, true},
+		{
+			"add with zero qty",
+			"ECO-001",
+			[]ECOChange{{Action: ECOAddLine, Line: BOMLine{ParentPN: "A", ChildPN: "B"}}},
+			true,
+		},
+		{
+			"remove without quantity",
+			"ECO-001",
+			[]ECOChange{{Action: ECORemoveLine, Line: BOMLine{ParentPN: "A", ChildPN: "B"}}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewECO(tt.id, "", ECODraft, "AS507", tt.changes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewECO() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestECO_Apply(t *testing.T) {
+	allSerials := SerialEffectivity{FromSerial: "AS501", ToSerial: ""}
+	v1 := &BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V1", QtyPer: 1, FindNumber: 100, Effectivity: allSerials}
+	injector := &BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_INJECTOR", QtyPer: 1, FindNumber: 200, Effectivity: allSerials}
+	base := []*BOMLine{v1, injector}
+
+	eco, err := NewECO("ECO-001", "Turbopump V2 cut-in", ECOReleased, "AS507", []ECOChange{
+		{Action: ECORemoveLine, Line: BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V1", FindNumber: 100}},
+		{Action: ECOAddLine, Line: BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_TURBOPUMP_V2", QtyPer: 1, FindNumber: 100, Effectivity: allSerials}},
+		{
+			Action:      ECOChangeEffectivity,
+			Line:        BOMLine{ParentPN: "F1_ENGINE", ChildPN: "F1_INJECTOR", FindNumber: 200},
+			Effectivity: SerialEffectivity{FromSerial: "AS501", ToSerial: "AS510"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ECO: %v", err)
+	}
+
+	applied := eco.Apply(base)
+	if len(applied) != 2 {
+		t.Fatalf("Expected 2 lines after ECO, got %d", len(applied))
+	}
+	if applied[0].ChildPN != "F1_INJECTOR" || applied[0].Effectivity.ToSerial != "AS510" {
+		t.Errorf("Expected injector with effectivity to AS510, got %+v", applied[0])
+	}
+	if applied[1].ChildPN != "F1_TURBOPUMP_V2" {
+		t.Errorf("Expected added V2 turbopump, got %s", applied[1].ChildPN)
+	}
+
+	// The base BOM must be left untouched
+	if injector.Effectivity.ToSerial != "" {
+		t.Errorf("Expected base injector line to be unchanged, got %+v", injector.Effectivity)
+	}
+}
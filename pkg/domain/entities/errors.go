@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemNotFoundError reports a part number missing from the item master
+type ItemNotFoundError struct {
+	PartNumber PartNumber
+}
+
+// Error implements the error interface
+func (e *ItemNotFoundError) Error() string {
+	return fmt.Sprintf("item not found: %s", e.PartNumber)
+}
+
+// NotFoundError reports a record other than an item missing from its repository, e.g. an
+// inventory lot, a serial or an ECO
+type NotFoundError struct {
+	Kind       string     // e.g. "lot", "serial", "ECO"
+	ID         string     // Lot number, serial number or ECO ID
+	PartNumber PartNumber // Empty for records not tied to a part
+	Location   string     // Empty when the record was not looked up by location
+}
+
+// Error implements the error interface
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("%s not found: %s", e.Kind, e.ID)
+	if e.PartNumber != "" {
+		msg += fmt.Sprintf(" for part %s", e.PartNumber)
+	}
+	if e.Location != "" {
+		msg += fmt.Sprintf(" at %s", e.Location)
+	}
+	return msg
+}
+
+// CyclicBOMError reports a part that is, directly or indirectly, a component of itself
+type CyclicBOMError struct {
+	// Cycle is the path around the cycle; the first and last part are the same,
+	// e.g. [A B C A] for A -> B -> C -> A
+	Cycle []PartNumber
+}
+
+// Error implements the error interface
+func (e *CyclicBOMError) Error() string {
+	parts := make([]string, len(e.Cycle))
+	for i, pn := range e.Cycle {
+		parts[i] = string(pn)
+	}
+	return "cyclic BOM: " + strings.Join(parts, " -> ")
+}
+
+// Parts returns each part on the cycle once
+func (e *CyclicBOMError) Parts() []PartNumber {
+	if len(e.Cycle) == 0 {
+		return nil
+	}
+	return e.Cycle[:len(e.Cycle)-1]
+}
+
+// NoEffectiveAlternateError reports an assembly with BOM lines of which none is effective
+// for the requested serial
+type NoEffectiveAlternateError struct {
+	ParentPN PartNumber
+	Serial   string
+}
+
+// Error implements the error interface
+func (e *NoEffectiveAlternateError) Error() string {
+	return fmt.Sprintf("no BOM line of %s is effective for serial %s", e.ParentPN, e.Serial)
+}
+
+// InvalidSerialError reports a serial that fits none of its part's serial schemes, or that
+// cannot be compared with another serial of the part
+type InvalidSerialError struct {
+	PartNumber PartNumber
+	Serial     string
+	Reason     string // e.g. "fits no serial scheme (tried: default)"
+}
+
+// Error implements the error interface
+func (e *InvalidSerialError) Error() string {
+	return fmt.Sprintf("serial %s for %s %s", e.Serial, e.PartNumber, e.Reason)
+}
+
+// AllocationConflictError reports inventory that cannot be allocated because of its status,
+// e.g. a lot that is already allocated or in quarantine, or stock a plan counted on that has
+// since been allocated elsewhere
+type AllocationConflictError struct {
+	PartNumber PartNumber
+	LotNumber  string // Empty when the conflict is over a quantity rather than one lot
+	Location   string
+	Status     InventoryStatus
+	Quantity   Quantity // Units that could not be allocated, when LotNumber is empty
+}
+
+// Error implements the error interface
+func (e *AllocationConflictError) Error() string {
+	if e.LotNumber == "" {
+		return fmt.Sprintf("cannot allocate %d of %s at %s: stock is %s",
+			e.Quantity, e.PartNumber, e.Location, e.Status)
+	}
+	return fmt.Sprintf("cannot allocate lot %s of %s at %s: lot is %s",
+		e.LotNumber, e.PartNumber, e.Location, e.Status)
+}
+
+// LateDemandError reports demands the plan fulfills after their need date, or not at all
+type LateDemandError struct {
+	Late  int // Demands projected late or unfulfilled
+	Total int // Demands planned
+}
+
+// Error implements the error interface
+func (e *LateDemandError) Error() string {
+	return fmt.Sprintf("%d of %d demand(s) projected late", e.Late, e.Total)
+}
@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// DemandFulfillment projects when the stock and planned orders pegged to a demand fulfill it
+type DemandFulfillment struct {
+	PartNumber   PartNumber `json:"part_number"`
+	Quantity     Quantity   `json:"quantity"`
+	NeedDate     time.Time  `json:"need_date"`
+	DemandSource string     `json:"demand_source"`
+	Location     string     `json:"location"`
+	TargetSerial string     `json:"target_serial"`
+
+	ProjectedDate time.Time `json:"projected_date"` // Zero when a shortage leaves the demand unfulfilled
+	DaysLate      int       `json:"days_late"`      // Negative when the demand is fulfilled early
+	StockQty      Quantity  `json:"stock_qty"`      // Units covered by on-hand stock
+	OrderQty      Quantity  `json:"order_qty"`      // Units covered by scheduled receipts and planned orders
+	ShortQty      Quantity  `json:"short_qty"`      // Units neither stock nor planned orders cover
+
+	// What the projected date waits on: the shortage leaving the demand unfulfilled or, failing
+	// that, the planned order or scheduled receipt at the bottom of the chain of orders holding
+	// up its build
+	GatingShortage *Shortage         `json:"gating_shortage,omitempty"`
+	GatingOrder    *PlannedOrder     `json:"gating_order,omitempty"`
+	GatingReceipt  *ScheduledReceipt `json:"gating_receipt,omitempty"`
+}
+
+// CoveredByStock reports whether on-hand stock fulfills the whole demand
+func (f *DemandFulfillment) CoveredByStock() bool {
+	return f.StockQty >= f.Quantity
+}
+
+// Fulfilled reports whether the demand is fulfilled at all, late or not
+func (f *DemandFulfillment) Fulfilled() bool {
+	return f.GatingShortage == nil
+}
+
+// IsLate reports whether the demand is fulfilled after its need date, or not at all
+func (f *DemandFulfillment) IsLate() bool {
+	return !f.Fulfilled() || f.DaysLate > 0
+}
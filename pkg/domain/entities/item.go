@@ -1,6 +1,11 @@
 package entities
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
 
 // PartNumber represents a unique part identifier
 type PartNumber string
@@ -51,6 +56,111 @@ func (m MakeBuyCode) String() string {
 	}
 }
 
+// DistributionShape is the shape of a lead time distribution between its minimum and maximum
+type DistributionShape int
+
+const (
+	Triangular DistributionShape = iota
+	PERT                         // Beta distribution weighted towards the most likely value
+)
+
+// String method for DistributionShape enum
+func (d DistributionShape) String() string {
+	switch d {
+	case Triangular:
+		return "triangular"
+	case PERT:
+		return "pert"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseDistributionShape parses a distribution shape name, ignoring case
+func ParseDistributionShape(name string) (DistributionShape, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "triangular":
+		return Triangular, nil
+	case "pert":
+		return PERT, nil
+	default:
+		return 0, fmt.Errorf("unknown lead time distribution %q (want triangular or pert)", name)
+	}
+}
+
+// LeadTimeDistribution describes the lead times an item may take, in days
+type LeadTimeDistribution struct {
+	Shape      DistributionShape
+	MinDays    int
+	LikelyDays int
+	MaxDays    int
+}
+
+// NewLeadTimeDistribution creates a validated LeadTimeDistribution
+func NewLeadTimeDistribution(
+	shape DistributionShape,
+	minDays, likelyDays, maxDays int,
+) (*LeadTimeDistribution, error) {
+	if minDays < 0 {
+		return nil, fmt.Errorf("minimum lead time cannot be negative, got %d", minDays)
+	}
+	if likelyDays < minDays || likelyDays > maxDays {
+		return nil, fmt.Errorf("likely lead time %d must be between the minimum %d and maximum %d",
+			likelyDays, minDays, maxDays)
+	}
+
+	return &LeadTimeDistribution{
+		Shape:      shape,
+		MinDays:    minDays,
+		LikelyDays: likelyDays,
+		MaxDays:    maxDays,
+	}, nil
+}
+
+// Sample draws a lead time in days from the distribution
+func (d *LeadTimeDistribution) Sample(rng *rand.Rand) float64 {
+	low, mode, high := float64(d.MinDays), float64(d.LikelyDays), float64(d.MaxDays)
+	if high == low {
+		return low
+	}
+
+	switch d.Shape {
+	case PERT:
+		alpha := 1 + 4*(mode-low)/(high-low)
+		beta := 1 + 4*(high-mode)/(high-low)
+		x := sampleGamma(rng, alpha)
+		y := sampleGamma(rng, beta)
+		return low + (high-low)*x/(x+y)
+	default:
+		// Inverse of the triangular distribution function
+		u := rng.Float64()
+		split := (mode - low) / (high - low)
+		if u < split {
+			return low + math.Sqrt(u*(high-low)*(mode-low))
+		}
+		return high - math.Sqrt((1-u)*(high-low)*(high-mode))
+	}
+}
+
+// sampleGamma draws from a gamma distribution with unit scale and the given shape (at least 1),
+// by Marsaglia and Tsang's method
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
 // Item represents a manufacturing item with its properties
 type Item struct {
 	PartNumber    PartNumber
@@ -62,6 +172,21 @@ type Item struct {
 	SafetyStock   Quantity
 	UnitOfMeasure string
 	MakeBuyCode   MakeBuyCode
+
+	// Phantom marks a kit or pseudo-assembly that is never built as a stock item.
+	// MRP blows through it: on-hand phantom stock is consumed, but no planned order
+	// is created for it and it contributes zero lead time to schedules and critical paths.
+	Phantom bool
+
+	// LeadTimeRange describes how the lead time varies, for schedule risk analysis; nil when
+	// the lead time is a single point
+	LeadTimeRange *LeadTimeDistribution
+
+	// Time fences, in days from the planning date, inside which MRP leaves the plan to the
+	// planner. Requirements needed inside the demand time fence get no new orders, and no new
+	// order falls due inside the planning time fence; MRP suggests firming one instead.
+	DemandTimeFenceDays   int
+	PlanningTimeFenceDays int
 }
 
 // NewItem creates a validated Item
@@ -120,3 +245,45 @@ func NewItem(
 		MakeBuyCode:   makeBuyCode,
 	}, nil
 }
+
+// LotSize applies the item's lot sizing rule to a net quantity to get the quantity to order
+func (i *Item) LotSize(netQty Quantity) Quantity {
+	switch i.LotSizeRule {
+	case LotForLot:
+		return netQty
+	case MinimumQty:
+		if netQty < i.MinOrderQty {
+			return i.MinOrderQty
+		}
+		return netQty
+	case StandardPack:
+		// Round up to nearest standard pack size (using MinOrderQty as pack size)
+		if i.MinOrderQty > 0 {
+			packs := (netQty + i.MinOrderQty - 1) / i.MinOrderQty
+			return packs * i.MinOrderQty
+		}
+		return netQty
+	default:
+		return netQty
+	}
+}
+
+// PlanningLeadTimeDays returns the lead time used for scheduling (zero for phantoms)
+func (i *Item) PlanningLeadTimeDays() int {
+	if i.Phantom {
+		return 0
+	}
+	return i.LeadTimeDays
+}
+
+// SampleLeadTimeDays draws a planning lead time from the item's lead time range, rounded to whole
+// days; items without a range always take their lead time, and phantoms none
+func (i *Item) SampleLeadTimeDays(rng *rand.Rand) int {
+	if i.Phantom {
+		return 0
+	}
+	if i.LeadTimeRange == nil {
+		return i.LeadTimeDays
+	}
+	return int(math.Round(i.LeadTimeRange.Sample(rng)))
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "testing"
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
 
 func TestItem_Validation(t *testing.T) {
 	validItem, err := NewItem("PART123", "Test Part", 10, LotForLot, 1, 100, 0, "EA", MakeBuyMake)
@@ -158,3 +162,74 @@ func TestItem_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestItem_PlanningLeadTimeDays(t *testing.T) {
+	item, err := NewItem("AVIONICS_PACKAGE", "Avionics Kit", 90, LotForLot, 1, 10, 0, "EA", MakeBuyMake)
+	if err != nil {
+		t.Fatalf("Expected valid item creation to succeed: %v", err)
+	}
+
+	if item.PlanningLeadTimeDays() != 90 {
+		t.Errorf("Expected planning lead time 90 for stocked item, got %d", item.PlanningLeadTimeDays())
+	}
+
+	item.Phantom = true
+	if item.PlanningLeadTimeDays() != 0 {
+		t.Errorf("Expected planning lead time 0 for phantom item, got %d", item.PlanningLeadTimeDays())
+	}
+	if item.LeadTimeDays != 90 {
+		t.Errorf("Expected phantom flag to leave LeadTimeDays untouched, got %d", item.LeadTimeDays)
+	}
+}
+
+func TestLeadTimeDistribution_Sample(t *testing.T) {
+	if _, err := NewLeadTimeDistribution(Triangular, 30, 20, 60); err == nil {
+		t.Error("Expected error for a likely lead time below the minimum")
+	}
+
+	// Triangular mean is (min + likely + max) / 3, PERT mean (min + 4 likely + max) / 6
+	for shape, mean := range map[DistributionShape]float64{Triangular: 30, PERT: 25} {
+		dist, err := NewLeadTimeDistribution(shape, 10, 20, 60)
+		if err != nil {
+			t.Fatalf("Expected valid distribution creation to succeed: %v", err)
+		}
+
+		rng := rand.New(rand.NewSource(1))
+		const samples = 20000
+		total := 0.0
+		for i := 0; i < samples; i++ {
+			days := dist.Sample(rng)
+			if days < 10 || days > 60 {
+				t.Fatalf("%s: sample %.1f outside [10, 60]", shape, days)
+			}
+			total += days
+		}
+		if got := total / samples; math.Abs(got-mean) > 0.5 {
+			t.Errorf("%s: expected mean near %.0f days, got %.2f", shape, mean, got)
+		}
+	}
+}
+
+func TestItem_SampleLeadTimeDays(t *testing.T) {
+	item, err := NewItem("TURBOPUMP", "Turbopump", 90, LotForLot, 1, 10, 0, "EA", MakeBuyBuy)
+	if err != nil {
+		t.Fatalf("Expected valid item creation to succeed: %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	if days := item.SampleLeadTimeDays(rng); days != 90 {
+		t.Errorf("Expected an item without a range to take its lead time of 90, got %d", days)
+	}
+
+	item.LeadTimeRange, _ = NewLeadTimeDistribution(PERT, 80, 90, 150)
+	for i := 0; i < 100; i++ {
+		if days := item.SampleLeadTimeDays(rng); days < 80 || days > 150 {
+			t.Fatalf("Expected a sampled lead time in [80, 150], got %d", days)
+		}
+	}
+
+	item.Phantom = true
+	if days := item.SampleLeadTimeDays(rng); days != 0 {
+		t.Errorf("Expected a phantom to take no time, got %d", days)
+	}
+}
@@ -0,0 +1,141 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// Forecast is the independent demand expected for an end item in the period starting on a date
+type Forecast struct {
+	PartNumber   PartNumber `json:"part_number"`
+	Quantity     Quantity   `json:"quantity"`
+	PeriodStart  time.Time  `json:"period_start"`
+	Location     string     `json:"location"`
+	TargetSerial string     `json:"target_serial"` // Serial the forecast units are built to
+}
+
+// NewForecast creates a validated Forecast
+func NewForecast(
+	partNumber PartNumber,
+	quantity Quantity,
+	periodStart time.Time,
+	location, targetSerial string,
+) (*Forecast, error) {
+	if string(partNumber) == "" {
+		return nil, fmt.Errorf("part number cannot be empty")
+	}
+	if quantity < 0 {
+		return nil, fmt.Errorf("quantity cannot be negative, got %d", quantity)
+	}
+	if location == "" {
+		return nil, fmt.Errorf("location cannot be empty")
+	}
+	if targetSerial == "" {
+		return nil, fmt.Errorf("target serial cannot be empty")
+	}
+
+	return &Forecast{
+		PartNumber:   partNumber,
+		Quantity:     quantity,
+		PeriodStart:  periodStart,
+		Location:     location,
+		TargetSerial: targetSerial,
+	}, nil
+}
+
+// TimeFenceZone is the part of the master schedule horizon a period falls in
+type TimeFenceZone int
+
+const (
+	FrozenZone TimeFenceZone = iota // Inside the demand time fence: only customer orders count
+	SlushyZone                      // Inside the planning time fence: the MPS is not changed automatically
+	LiquidZone                      // Beyond both fences: the MPS follows forecast and orders freely
+)
+
+// String method for TimeFenceZone enum
+func (z TimeFenceZone) String() string {
+	switch z {
+	case FrozenZone:
+		return "frozen"
+	case SlushyZone:
+		return "slushy"
+	case LiquidZone:
+		return "liquid"
+	default:
+		return "unknown"
+	}
+}
+
+// MPSPeriod is one time bucket of an end item's master schedule
+type MPSPeriod struct {
+	Start              time.Time     `json:"start"`
+	End                time.Time     `json:"end"` // Exclusive
+	Zone               TimeFenceZone `json:"zone"`
+	Forecast           Quantity      `json:"forecast"`
+	CustomerOrders     Quantity      `json:"customer_orders"`
+	ProjectedDemand    Quantity      `json:"projected_demand"` // Customer orders when frozen, else the larger of forecast and orders
+	ScheduledReceipts  Quantity      `json:"scheduled_receipts"`
+	FirmMPS            Quantity      `json:"firm_mps"`             // Firm planned orders due in the period
+	PlannedMPS         Quantity      `json:"planned_mps"`          // Quantity the MPS plans, lot sized
+	ProjectedAvailable Quantity      `json:"projected_available"`  // Projected available balance at the end of the period
+	AvailableToPromise Quantity      `json:"available_to_promise"` // Uncommitted supply arriving in the period
+	CumulativeATP      Quantity      `json:"cumulative_atp"`
+	TargetSerial       string        `json:"target_serial"` // Serial the period's MPS quantity is built to
+}
+
+// MPSQuantity returns the master scheduled quantity due in the period, firm and planned
+func (p *MPSPeriod) MPSQuantity() Quantity {
+	return p.FirmMPS + p.PlannedMPS
+}
+
+// MPSRecord is the master schedule of one end item at one location
+type MPSRecord struct {
+	PartNumber        PartNumber  `json:"part_number"`
+	Location          string      `json:"location"`
+	OnHand            Quantity    `json:"on_hand"`
+	SafetyStock       Quantity    `json:"safety_stock"`
+	DemandTimeFence   time.Time   `json:"demand_time_fence"`   // Zero when the item has none
+	PlanningTimeFence time.Time   `json:"planning_time_fence"` // Zero when the item has none
+	Periods           []MPSPeriod `json:"periods"`
+}
+
+// MasterSchedule is the master production schedule of every end item over a horizon of
+// equal periods
+type MasterSchedule struct {
+	Start      time.Time   `json:"start"`
+	PeriodDays int         `json:"period_days"`
+	Records    []MPSRecord `json:"records"`
+}
+
+// Includes reports whether the schedule plans a part at a location
+func (m *MasterSchedule) Includes(partNumber PartNumber, location string) bool {
+	for i := range m.Records {
+		if m.Records[i].PartNumber == partNumber && m.Records[i].Location == location {
+			return true
+		}
+	}
+	return false
+}
+
+// Demands returns the master scheduled quantities as the independent demand MRP explodes,
+// one per period with an MPS quantity, needed at the start of the period
+func (m *MasterSchedule) Demands() []*DemandRequirement {
+	var demands []*DemandRequirement
+	for _, record := range m.Records {
+		for _, period := range record.Periods {
+			qty := period.MPSQuantity()
+			if qty <= 0 {
+				continue
+			}
+			demands = append(demands, &DemandRequirement{
+				PartNumber:   record.PartNumber,
+				Quantity:     qty,
+				NeedDate:     period.Start,
+				DemandSource: fmt.Sprintf("MPS %s %s", record.PartNumber, period.Start.Format("2006-01-02")),
+				Location:     record.Location,
+				TargetSerial: period.TargetSerial,
+			})
+		}
+	}
+	return demands
+}
@@ -38,6 +38,11 @@ type PlannedOrder struct {
 	Location     string     `json:"location"`
 	OrderType    OrderType  `json:"order_type"`
 	TargetSerial string     `json:"target_serial"`
+
+	// A firm planned order is fixed by the planner: MRP nets it like a scheduled receipt but
+	// never changes its quantity or dates
+	Firm        bool   `json:"firm,omitempty"`
+	OrderNumber string `json:"order_number,omitempty"` // Set on firm planned orders
 }
 
 // NewPlannedOrder creates a validated PlannedOrder
@@ -73,3 +78,68 @@ func NewPlannedOrder(
 		TargetSerial: targetSerial,
 	}, nil
 }
+
+// NewFirmPlannedOrder creates a validated firm PlannedOrder
+func NewFirmPlannedOrder(
+	orderNumber string,
+	partNumber PartNumber,
+	quantity Quantity,
+	startDate, dueDate time.Time,
+	location string,
+	orderType OrderType,
+) (*PlannedOrder, error) {
+	if orderNumber == "" {
+		return nil, fmt.Errorf("order number cannot be empty")
+	}
+
+	order, err := NewPlannedOrder(partNumber, quantity, startDate, dueDate, orderNumber, location, orderType, "")
+	if err != nil {
+		return nil, err
+	}
+	order.Firm = true
+	order.OrderNumber = orderNumber
+	return order, nil
+}
+
+// ScheduledReceipt is an open order, already released to the shop floor or a supplier, due to
+// deliver a quantity of a part. MRP nets it against requirements before planning new orders.
+type ScheduledReceipt struct {
+	OrderNumber string     `json:"order_number"`
+	PartNumber  PartNumber `json:"part_number"`
+	Quantity    Quantity   `json:"quantity"`
+	DueDate     time.Time  `json:"due_date"`
+	Location    string     `json:"location"`
+	OrderType   OrderType  `json:"order_type"`
+}
+
+// NewScheduledReceipt creates a validated ScheduledReceipt
+func NewScheduledReceipt(
+	orderNumber string,
+	partNumber PartNumber,
+	quantity Quantity,
+	dueDate time.Time,
+	location string,
+	orderType OrderType,
+) (*ScheduledReceipt, error) {
+	if orderNumber == "" {
+		return nil, fmt.Errorf("order number cannot be empty")
+	}
+	if string(partNumber) == "" {
+		return nil, fmt.Errorf("part number cannot be empty")
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive, got %d", quantity)
+	}
+	if location == "" {
+		return nil, fmt.Errorf("location cannot be empty")
+	}
+
+	return &ScheduledReceipt{
+		OrderNumber: orderNumber,
+		PartNumber:  partNumber,
+		Quantity:    quantity,
+		DueDate:     dueDate,
+		Location:    location,
+		OrderType:   orderType,
+	}, nil
+}
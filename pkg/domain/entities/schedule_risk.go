@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// DemandRisk is the spread of completion dates of one demand over a schedule risk simulation
+type DemandRisk struct {
+	Demand            *DemandRequirement
+	PlannedCompletion time.Time // With every part taking its likely lead time
+	P50               time.Time // Completion date met in half of the iterations
+	P80               time.Time
+	P95               time.Time
+	OnTimeProbability float64 // Fraction of iterations completing by the need date
+}
+
+// PartCriticality is how often a part was critical over a schedule risk simulation
+type PartCriticality struct {
+	PartNumber       PartNumber
+	Description      string
+	Demands          int     // Demands whose build uses the part
+	CriticalityIndex float64 // Fraction of iterations, over the demands using it, the part had no float
+	HasLeadTimeRange bool    // Whether the part's lead time varies in the simulation
+}
+
+// ScheduleRisk is the result of a Monte Carlo simulation of lead times
+type ScheduleRisk struct {
+	AsOf       time.Time // Date the build of every demand starts from
+	Iterations int
+	Seed       int64
+	Demands    []DemandRisk      // In demand order
+	Parts      []PartCriticality // Most critical first
+}
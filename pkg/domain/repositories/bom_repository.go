@@ -1,25 +1,43 @@
 package repositories
 
-import "github.com/vsinha/mrp/pkg/domain/entities"
+import (
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
 
 // BOMRepository provides access to Bill of Materials data
 type BOMRepository interface {
 	GetBOMLines(partNumber entities.PartNumber) ([]*entities.BOMLine, error)
-	GetEffectiveLines(partNumber entities.PartNumber, serial string) ([]*entities.BOMLine, error)
+	// GetEffectiveLines returns a part's BOM lines effective for serial, a serial of endItem.
+	// Effectivity serials are compared with endItem's serial schemes.
+	GetEffectiveLines(
+		partNumber entities.PartNumber,
+		endItem entities.PartNumber,
+		serial string,
+	) ([]*entities.BOMLine, error)
 	GetAllBOMLines() ([]*entities.BOMLine, error)
 	LoadBOMLines(lines []*entities.BOMLine) error
 
+	// GetWhereUsed returns every BOM line that uses a part as its child (the where-used index).
+	// Lines are returned regardless of effectivity.
+	GetWhereUsed(childPN entities.PartNumber) ([]*entities.BOMLine, error)
+
 	// Alternate-aware methods
 
 	// GetAlternateGroups returns BOM lines grouped by FindNumber for a parent part.
 	// Returns map[FindNumber][]*BOMLine where each slice contains alternates for that position.
 	GetAlternateGroups(parentPN entities.PartNumber) (map[int][]*entities.BOMLine, error)
 
-	// GetEffectiveAlternates returns alternate BOM lines for a specific FindNumber and serial.
-	// Filters by serial effectivity and groups alternates together.
+	// GetEffectiveAlternates returns alternate BOM lines for a specific FindNumber, serial and need date.
+	// Filters by serial and date effectivity and groups alternates together. targetSerial is
+	// a serial of endItem, the demanded part, whose serial schemes compare it.
+	// A zero needDate skips date filtering.
 	GetEffectiveAlternates(
 		parentPN entities.PartNumber,
 		findNumber int,
+		endItem entities.PartNumber,
 		targetSerial string,
+		needDate time.Time,
 	) ([]*entities.BOMLine, error)
 }
@@ -0,0 +1,11 @@
+package repositories
+
+import "github.com/vsinha/mrp/pkg/domain/entities"
+
+// ECORepository provides access to engineering change orders
+type ECORepository interface {
+	GetECO(id string) (*entities.ECO, error)
+	// GetAllECOs returns ECOs in load order, which is the order they are applied
+	GetAllECOs() ([]*entities.ECO, error)
+	LoadECOs(ecos []*entities.ECO) error
+}
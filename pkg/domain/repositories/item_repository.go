@@ -4,6 +4,7 @@ import "github.com/vsinha/mrp/pkg/domain/entities"
 
 // ItemRepository provides access to item master data
 type ItemRepository interface {
+	// GetItem returns an *entities.ItemNotFoundError for a part not in the item master
 	GetItem(partNumber entities.PartNumber) (*entities.Item, error)
 	GetAllItems() ([]*entities.Item, error)
 	LoadItems(items []*entities.Item) error
@@ -142,14 +142,19 @@ func dfsDetectCycle(
 	recursionStack[current] = false
 }
 
-// detectDuplicateLines finds duplicate BOM lines (same parent, child, find number)
+// detectDuplicateLines finds duplicate BOM lines (same parent, child, find number and
+// effectivity). Lines that differ only in effectivity are a cut-over, not a duplicate.
 func detectDuplicateLines(bomLines []entities.BOMLine) []entities.BOMLine {
 	seen := make(map[string]entities.BOMLine)
 	duplicates := make([]entities.BOMLine, 0)
 
 	for _, line := range bomLines {
-		// Create a unique key for parent, child, and find number
-		key := fmt.Sprintf("%s|%s|%d", line.ParentPN, line.ChildPN, line.FindNumber)
+		// Create a unique key for parent, child, find number and effectivity
+		key := fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s",
+			line.ParentPN, line.ChildPN, line.FindNumber,
+			line.Effectivity.FromSerial, line.Effectivity.ToSerial,
+			line.DateEffectivity.FromDate.Format("2006-01-02"),
+			line.DateEffectivity.ToDate.Format("2006-01-02"))
 
 		if existingLine, exists := seen[key]; exists {
 			// This is a duplicate
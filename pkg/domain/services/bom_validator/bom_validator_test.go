@@ -198,6 +198,32 @@ func TestBOMValidator_ValidAlternates(t *testing.T) {
 	}
 }
 
+func TestBOMValidator_EffectivityCutOver(t *testing.T) {
+	// The same child at the same find number, split across serial ranges
+	bomLines := []entities.BOMLine{
+		{
+			ParentPN:    "A",
+			ChildPN:     "B",
+			QtyPer:      1,
+			FindNumber:  100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001", ToSerial: "SN005"},
+		},
+		{
+			ParentPN:    "A",
+			ChildPN:     "B",
+			QtyPer:      2,
+			FindNumber:  100,
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN006", ToSerial: ""},
+		},
+	}
+
+	result := ValidateBOM(bomLines)
+
+	if len(result.DuplicateLines) > 0 {
+		t.Errorf("A serial cut-over should not be flagged as duplicates, got %+v", result.DuplicateLines)
+	}
+}
+
 func TestBOMValidator_PartNumberUniqueness(t *testing.T) {
 
 	// Create items with duplicate part numbers
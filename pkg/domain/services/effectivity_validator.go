@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// EffectivityIssueType classifies a serial effectivity problem within a find number group
+type EffectivityIssueType int
+
+const (
+	EffectivityGap EffectivityIssueType = iota
+	EffectivityOverlap
+	EffectivityInverted
+)
+
+// String method for EffectivityIssueType enum
+func (t EffectivityIssueType) String() string {
+	switch t {
+	case EffectivityGap:
+		return "Gap"
+	case EffectivityOverlap:
+		return "Overlap"
+	case EffectivityInverted:
+		return "Inverted"
+	default:
+		return "Unknown"
+	}
+}
+
+// EffectivityIssue is a serial effectivity problem at one find number of a parent
+type EffectivityIssue struct {
+	Type       EffectivityIssueType
+	ParentPN   entities.PartNumber
+	FindNumber int
+	Message    string
+}
+
+// findNumberGroup identifies the alternates at one position of a parent
+type findNumberGroup struct {
+	parentPN   entities.PartNumber
+	findNumber int
+	endItem    entities.PartNumber // Whose serial schemes compare the group's effectivity
+}
+
+// ValidateEffectivityCoverage checks each parent find number group for inverted ranges
+// (from after to), gaps where no line is effective between two ranges, and alternates with
+// the same priority whose serial (and date) ranges overlap, so neither is preferred.
+// Serials are compared with the schemes of the first end item above the parent.
+func (sc *SerialComparator) ValidateEffectivityCoverage(bomLines []*entities.BOMLine) []EffectivityIssue {
+	above := EndItemsAbove(bomLines)
+	groups := make(map[findNumberGroup][]*entities.BOMLine)
+	for _, line := range bomLines {
+		key := findNumberGroup{
+			parentPN:   line.ParentPN,
+			findNumber: line.FindNumber,
+			endItem:    endItemsOf(above, line.ParentPN)[0],
+		}
+		groups[key] = append(groups[key], line)
+	}
+
+	var issues []EffectivityIssue
+	for key, lines := range groups {
+		var valid []*entities.BOMLine
+		for _, line := range lines {
+			if sc.isInverted(key.endItem, line.Effectivity) {
+				issues = append(issues, EffectivityIssue{
+					Type:       EffectivityInverted,
+					ParentPN:   key.parentPN,
+					FindNumber: key.findNumber,
+					Message: fmt.Sprintf("%s range %s-%s ends before it starts",
+						line.ChildPN, line.Effectivity.FromSerial, line.Effectivity.ToSerial),
+				})
+				continue
+			}
+			valid = append(valid, line)
+		}
+
+		issues = append(issues, sc.checkSamePriorityOverlaps(key, valid)...)
+		issues = append(issues, sc.checkGaps(key, valid)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].ParentPN != issues[j].ParentPN {
+			return issues[i].ParentPN < issues[j].ParentPN
+		}
+		if issues[i].FindNumber != issues[j].FindNumber {
+			return issues[i].FindNumber < issues[j].FindNumber
+		}
+		return issues[i].Type < issues[j].Type
+	})
+
+	return issues
+}
+
+// isInverted reports whether a closed range ends before it starts
+func (sc *SerialComparator) isInverted(
+	partNumber entities.PartNumber,
+	effectivity entities.SerialEffectivity,
+) bool {
+	return effectivity.ToSerial != "" &&
+		sc.compareForPart(partNumber, effectivity.FromSerial, effectivity.ToSerial) > 0
+}
+
+// checkSamePriorityOverlaps reports pairs of alternates with equal priority that are both
+// effective for some serial on some date
+func (sc *SerialComparator) checkSamePriorityOverlaps(
+	key findNumberGroup,
+	lines []*entities.BOMLine,
+) []EffectivityIssue {
+	var issues []EffectivityIssue
+	for i := 0; i < len(lines); i++ {
+		for j := i + 1; j < len(lines); j++ {
+			a, b := lines[i], lines[j]
+			if a.Priority != b.Priority ||
+				!a.DateEffectivity.Overlaps(b.DateEffectivity) ||
+				!sc.rangesOverlap(key.endItem, a.Effectivity, b.Effectivity) {
+				continue
+			}
+			issues = append(issues, EffectivityIssue{
+				Type:       EffectivityOverlap,
+				ParentPN:   key.parentPN,
+				FindNumber: key.findNumber,
+				Message: fmt.Sprintf("%s [%s] and %s [%s] overlap with the same priority %d",
+					a.ChildPN, formatRange(a.Effectivity),
+					b.ChildPN, formatRange(b.Effectivity),
+					a.Priority),
+			})
+		}
+	}
+	return issues
+}
+
+// checkGaps walks the ranges in start order and reports serials between the end of the
+// coverage so far and the start of the next range. Coverage before the first range and
+// after the last closed range is not a gap: the position starts or ends there.
+func (sc *SerialComparator) checkGaps(key findNumberGroup, lines []*entities.BOMLine) []EffectivityIssue {
+	if len(lines) < 2 {
+		return nil
+	}
+
+	sorted := append([]*entities.BOMLine{}, lines...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sc.compareForPart(key.endItem, sorted[i].Effectivity.FromSerial, sorted[j].Effectivity.FromSerial) < 0
+	})
+
+	var issues []EffectivityIssue
+	covering := sorted[0]
+	for _, next := range sorted[1:] {
+		if covering.Effectivity.ToSerial == "" {
+			break // Open ended: everything after is covered
+		}
+
+		if !sc.isContiguous(key.endItem, covering.Effectivity.ToSerial, next.Effectivity.FromSerial) {
+			issues = append(issues, EffectivityIssue{
+				Type:       EffectivityGap,
+				ParentPN:   key.parentPN,
+				FindNumber: key.findNumber,
+				Message: fmt.Sprintf("no line effective between %s and %s (%s ends at %s, %s starts at %s)",
+					covering.Effectivity.ToSerial, next.Effectivity.FromSerial,
+					covering.ChildPN, covering.Effectivity.ToSerial,
+					next.ChildPN, next.Effectivity.FromSerial),
+			})
+		}
+
+		if next.Effectivity.ToSerial == "" ||
+			sc.compareForPart(key.endItem, next.Effectivity.ToSerial, covering.Effectivity.ToSerial) > 0 {
+			covering = next
+		}
+	}
+	return issues
+}
+
+// isContiguous reports whether no serial lies strictly between end and start: start is at
+// or before end, or it is the next number with the same prefix. Serials with different
+// prefixes are never contiguous.
+func (sc *SerialComparator) isContiguous(partNumber entities.PartNumber, end, start string) bool {
+	if sc.compareForPart(partNumber, start, end) <= 0 {
+		return true
+	}
+
+	parsedEnd, err := sc.ParseSerial(partNumber, end)
+	if err != nil {
+		return true // Unparseable serials are reported by ValidateBOMSerials
+	}
+	parsedStart, err := sc.ParseSerial(partNumber, start)
+	if err != nil {
+		return true
+	}
+
+	return parsedStart.Prefix == parsedEnd.Prefix && parsedStart.Number <= parsedEnd.Number+1
+}
+
+// formatRange formats serial effectivity as "from-to", with "+" for an open end
+func formatRange(effectivity entities.SerialEffectivity) string {
+	if effectivity.ToSerial == "" {
+		return effectivity.FromSerial + "+"
+	}
+	return effectivity.FromSerial + "-" + effectivity.ToSerial
+}
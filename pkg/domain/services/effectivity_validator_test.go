@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// turbopumpLine creates an F1_ENGINE turbopump line at find number 100
+func turbopumpLine(childPN entities.PartNumber, from, to string, priority int) *entities.BOMLine {
+	return &entities.BOMLine{
+		ParentPN:    "F1_ENGINE",
+		ChildPN:     childPN,
+		QtyPer:      1,
+		FindNumber:  100,
+		Effectivity: entities.SerialEffectivity{FromSerial: from, ToSerial: to},
+		Priority:    priority,
+	}
+}
+
+func TestSerialComparator_ValidateEffectivityCoverage(t *testing.T) {
+	sc := NewSerialComparator()
+
+	tests := []struct {
+		name     string
+		lines    []*entities.BOMLine
+		expected []EffectivityIssueType
+	}{
+		{
+			name: "contiguous_ranges",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA501", "SA507", 0),
+				turbopumpLine("TURBOPUMP_V2", "SA508", "", 0),
+			},
+		},
+		{
+			name: "gap_between_ranges",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA501", "SA507", 0),
+				turbopumpLine("TURBOPUMP_V2", "SA509", "", 0),
+			},
+			expected: []EffectivityIssueType{EffectivityGap},
+		},
+		{
+			name: "gap_after_nested_range_is_measured_from_widest_coverage",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA501", "SA510", 0),
+				turbopumpLine("TURBOPUMP_V1B", "SA503", "SA504", 1),
+				turbopumpLine("TURBOPUMP_V2", "SA511", "", 0),
+			},
+		},
+		{
+			name: "same_priority_overlap",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA501", "SA508", 0),
+				turbopumpLine("TURBOPUMP_V2", "SA507", "", 0),
+			},
+			expected: []EffectivityIssueType{EffectivityOverlap},
+		},
+		{
+			name: "alternates_with_different_priority_may_overlap",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA501", "", 1),
+				turbopumpLine("TURBOPUMP_V2", "SA501", "", 2),
+			},
+		},
+		{
+			name: "inverted_range",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA507", "SA501", 0),
+				turbopumpLine("TURBOPUMP_V2", "SA508", "", 0),
+			},
+			expected: []EffectivityIssueType{EffectivityInverted},
+		},
+		{
+			name: "numeric_not_string_ordering",
+			lines: []*entities.BOMLine{
+				turbopumpLine("TURBOPUMP_V1", "SA9", "SA99", 0),
+				turbopumpLine("TURBOPUMP_V2", "SA100", "", 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := sc.ValidateEffectivityCoverage(tt.lines)
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %+v", tt.expected, issues)
+			}
+			for i, issueType := range tt.expected {
+				if issues[i].Type != issueType {
+					t.Errorf("Expected %s, got %+v", issueType, issues[i])
+				}
+				if issues[i].ParentPN != "F1_ENGINE" || issues[i].FindNumber != 100 {
+					t.Errorf("Expected issue at F1_ENGINE find 100, got %+v", issues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSerialComparator_ValidateEffectivityCoverage_DateWindows(t *testing.T) {
+	sc := NewSerialComparator()
+	cutover := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// Same serials and priority, but a date changeover: not an overlap
+	v1 := turbopumpLine("TURBOPUMP_V1", "SA501", "", 0)
+	v1.DateEffectivity = entities.DateEffectivity{ToDate: cutover.AddDate(0, 0, -1)}
+	v2 := turbopumpLine("TURBOPUMP_V2", "SA501", "", 0)
+	v2.DateEffectivity = entities.DateEffectivity{FromDate: cutover}
+
+	if issues := sc.ValidateEffectivityCoverage([]*entities.BOMLine{v1, v2}); len(issues) != 0 {
+		t.Errorf("Expected a date changeover not to be reported, got %+v", issues)
+	}
+
+	v2.DateEffectivity = entities.DateEffectivity{FromDate: cutover.AddDate(0, 0, -10)}
+	if issues := sc.ValidateEffectivityCoverage([]*entities.BOMLine{v1, v2}); len(issues) != 1 {
+		t.Errorf("Expected overlapping date windows to be reported, got %+v", issues)
+	}
+}
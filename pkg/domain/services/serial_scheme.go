@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// Default scheme patterns match serials like SN001 or AS506: uppercase letters followed by digits
+const (
+	DefaultPrefixPattern = `[A-Z]+`
+	DefaultSuffixPattern = ``
+)
+
+// SerialScheme describes the grammar of a family of serial numbers: a prefix, a number that
+// orders serials within the prefix, and an optional suffix (e.g. a revision letter)
+type SerialScheme struct {
+	Name          string
+	PrefixPattern string // regexp for the leading part, e.g. `SA-` or `[A-Z0-9]+_`
+	SuffixPattern string // regexp for the trailing part, e.g. `[A-Z]?`; empty = no suffix
+
+	// AppliesTo lists the parts using this scheme: an exact part number, a family written as
+	// a part number prefix ending in "*" (e.g. "F1_*"), or "*" for every part
+	AppliesTo []string
+
+	pattern *regexp.Regexp
+}
+
+// ParsedSerial is a serial number split according to its scheme
+type ParsedSerial struct {
+	Scheme string
+	Prefix string
+	Number int
+	Suffix string
+}
+
+// NewSerialScheme creates a validated SerialScheme
+func NewSerialScheme(
+	name, prefixPattern, suffixPattern string,
+	appliesTo []string,
+) (*SerialScheme, error) {
+	if name == "" {
+		return nil, fmt.Errorf("serial scheme name cannot be empty")
+	}
+	if prefixPattern == "" {
+		return nil, fmt.Errorf("serial scheme %s: prefix pattern cannot be empty", name)
+	}
+	if len(appliesTo) == 0 {
+		return nil, fmt.Errorf("serial scheme %s must apply to at least one part or family", name)
+	}
+
+	pattern, err := regexp.Compile(fmt.Sprintf(`^(%s)(\d+)(%s)$`, prefixPattern, suffixPattern))
+	if err != nil {
+		return nil, fmt.Errorf("serial scheme %s: invalid pattern: %w", name, err)
+	}
+
+	return &SerialScheme{
+		Name:          name,
+		PrefixPattern: prefixPattern,
+		SuffixPattern: suffixPattern,
+		AppliesTo:     appliesTo,
+		pattern:       pattern,
+	}, nil
+}
+
+// Parse splits a serial into prefix, number and suffix
+func (s *SerialScheme) Parse(serial string) (ParsedSerial, error) {
+	matches := s.pattern.FindStringSubmatch(serial)
+	if matches == nil {
+		return ParsedSerial{}, fmt.Errorf("serial %s does not match scheme %s", serial, s.Name)
+	}
+
+	// Nested groups in the prefix pattern shift the number and suffix to the last two groups
+	numStr := matches[len(matches)-2]
+	num, err := strconv.Atoi(numStr)
+	if err != nil {
+		return ParsedSerial{}, fmt.Errorf("invalid numeric portion in serial %s: %v", serial, err)
+	}
+
+	return ParsedSerial{
+		Scheme: s.Name,
+		Prefix: matches[1],
+		Number: num,
+		Suffix: matches[len(matches)-1],
+	}, nil
+}
+
+// specificity ranks how closely the scheme targets a part: 0 = not applicable,
+// then default < family (longer prefixes rank higher) < exact part
+func (s *SerialScheme) specificity(partNumber entities.PartNumber) int {
+	best := 0
+	for _, target := range s.AppliesTo {
+		var rank int
+		switch {
+		case target == "*":
+			rank = 1
+		case strings.HasSuffix(target, "*"):
+			family := strings.TrimSuffix(target, "*")
+			if strings.HasPrefix(string(partNumber), family) {
+				rank = 2 + len(family)
+			}
+		case entities.PartNumber(target) == partNumber:
+			rank = 1 << 20
+		}
+		if rank > best {
+			best = rank
+		}
+	}
+	return best
+}
+
+// compareParsed orders two serials of the same scheme by prefix, number, then suffix
+func compareParsed(a, b ParsedSerial) int {
+	if a.Prefix != b.Prefix {
+		return strings.Compare(a.Prefix, b.Prefix)
+	}
+	if a.Number != b.Number {
+		if a.Number < b.Number {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.Suffix, b.Suffix)
+}
@@ -1,9 +1,9 @@
 package services
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
@@ -11,80 +11,152 @@ import (
 
 // SerialComparator handles serial number comparison and effectivity resolution
 type SerialComparator struct {
-	serialPattern *regexp.Regexp
+	schemes []*SerialScheme
 }
 
-// NewSerialComparator creates a new serial comparator with the default pattern
+// NewSerialComparator creates a new serial comparator with the default scheme
 func NewSerialComparator() *SerialComparator {
-	// Pattern matches serials like SN001, SN123, etc.
-	pattern := regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+	scheme, err := NewSerialScheme("default", DefaultPrefixPattern, DefaultSuffixPattern, []string{"*"})
+	if err != nil {
+		panic(fmt.Sprintf("invalid default serial scheme: %v", err))
+	}
 	return &SerialComparator{
-		serialPattern: pattern,
+		schemes: []*SerialScheme{scheme},
+	}
+}
+
+// NewSerialComparatorWithSchemes creates a serial comparator using the given schemes.
+// Parts not covered by any scheme fall back to the default scheme.
+func NewSerialComparatorWithSchemes(schemes []*SerialScheme) (*SerialComparator, error) {
+	sc := NewSerialComparator()
+	names := make(map[string]bool)
+	for _, scheme := range schemes {
+		if names[scheme.Name] {
+			return nil, fmt.Errorf("duplicate serial scheme: %s", scheme.Name)
+		}
+		names[scheme.Name] = true
+	}
+	sc.schemes = append(append([]*SerialScheme{}, schemes...), sc.schemes...)
+	return sc, nil
+}
+
+// schemesFor returns the schemes applicable to a part, most specific first
+func (sc *SerialComparator) schemesFor(partNumber entities.PartNumber) []*SerialScheme {
+	var applicable []*SerialScheme
+	for _, scheme := range sc.schemes {
+		if scheme.specificity(partNumber) > 0 {
+			applicable = append(applicable, scheme)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return applicable[i].specificity(partNumber) > applicable[j].specificity(partNumber)
+	})
+	return applicable
+}
+
+// ParseSerial parses a serial with the most specific scheme for the part that matches it
+func (sc *SerialComparator) ParseSerial(
+	partNumber entities.PartNumber,
+	serial string,
+) (ParsedSerial, error) {
+	applicable := sc.schemesFor(partNumber)
+	for _, scheme := range applicable {
+		if parsed, err := scheme.Parse(serial); err == nil {
+			return parsed, nil
+		}
+	}
+
+	names := make([]string, len(applicable))
+	for i, scheme := range applicable {
+		names[i] = scheme.Name
+	}
+	return ParsedSerial{}, &entities.InvalidSerialError{
+		PartNumber: partNumber,
+		Serial:     serial,
+		Reason:     fmt.Sprintf("fits no serial scheme (tried: %s)", strings.Join(names, ", ")),
 	}
 }
 
+// ValidateSerial checks that a serial fits one of the part's schemes
+func (sc *SerialComparator) ValidateSerial(partNumber entities.PartNumber, serial string) error {
+	_, err := sc.ParseSerial(partNumber, serial)
+	return err
+}
+
+// Compare orders two serials of a part according to its schemes.
+// Returns -1, 0 or 1, or an error if either serial fits no scheme or they use different schemes.
+func (sc *SerialComparator) Compare(partNumber entities.PartNumber, serial1, serial2 string) (int, error) {
+	if serial1 == serial2 {
+		return 0, nil
+	}
+
+	parsed1, err := sc.ParseSerial(partNumber, serial1)
+	if err != nil {
+		return 0, err
+	}
+	parsed2, err := sc.ParseSerial(partNumber, serial2)
+	if err != nil {
+		return 0, err
+	}
+	if parsed1.Scheme != parsed2.Scheme {
+		return 0, &entities.InvalidSerialError{
+			PartNumber: partNumber,
+			Serial:     serial2,
+			Reason: fmt.Sprintf(
+				"uses scheme %s and cannot be compared with %s (%s)",
+				parsed2.Scheme,
+				serial1,
+				parsed1.Scheme,
+			),
+		}
+	}
+
+	return compareParsed(parsed1, parsed2), nil
+}
+
 // IsSerialInRange checks if a target serial falls within the effectivity range
 func (sc *SerialComparator) IsSerialInRange(
 	targetSerial string,
 	effectivity entities.SerialEffectivity,
+) bool {
+	return sc.IsSerialInRangeForPart("", targetSerial, effectivity)
+}
+
+// IsSerialInRangeForPart checks if a target serial falls within the effectivity range of a
+// BOM line. Effectivity serials are serials of the end item being built, so they are compared
+// with the serial schemes of endItem (the demanded part), not of the line's parent.
+func (sc *SerialComparator) IsSerialInRangeForPart(
+	endItem entities.PartNumber,
+	targetSerial string,
+	effectivity entities.SerialEffectivity,
 ) bool {
 	// Handle open-ended ranges
 	if effectivity.ToSerial == "" {
-		return sc.CompareSerials(targetSerial, effectivity.FromSerial) >= 0
+		return sc.compareForPart(endItem, targetSerial, effectivity.FromSerial) >= 0
 	}
 
 	// Check if target falls within range
-	return sc.CompareSerials(targetSerial, effectivity.FromSerial) >= 0 &&
-		sc.CompareSerials(targetSerial, effectivity.ToSerial) <= 0
+	return sc.compareForPart(endItem, targetSerial, effectivity.FromSerial) >= 0 &&
+		sc.compareForPart(endItem, targetSerial, effectivity.ToSerial) <= 0
 }
 
 // CompareSerials compares two serial numbers with numeric sorting
 // Returns: -1 if serial1 < serial2, 0 if equal, 1 if serial1 > serial2
 func (sc *SerialComparator) CompareSerials(serial1, serial2 string) int {
-	// First try direct string comparison for identical prefixes
-	if serial1 == serial2 {
-		return 0
-	}
-
-	// Extract prefix and numeric parts
-	prefix1, num1, err1 := sc.parseSerial(serial1)
-	prefix2, num2, err2 := sc.parseSerial(serial2)
-
-	// If either parsing fails, fall back to string comparison
-	if err1 != nil || err2 != nil {
-		return strings.Compare(serial1, serial2)
-	}
-
-	// Compare prefixes first
-	if prefix1 != prefix2 {
-		return strings.Compare(prefix1, prefix2)
-	}
-
-	// Compare numeric parts
-	if num1 < num2 {
-		return -1
-	} else if num1 > num2 {
-		return 1
-	}
-	return 0
+	return sc.compareForPart("", serial1, serial2)
 }
 
-// parseSerial extracts the prefix and numeric portion from a serial number
-func (sc *SerialComparator) parseSerial(serial string) (string, int, error) {
-	matches := sc.serialPattern.FindStringSubmatch(serial)
-	if len(matches) != 3 {
-		return "", 0, fmt.Errorf("invalid serial format: %s", serial)
-	}
-
-	prefix := matches[1]
-	numStr := matches[2]
-
-	num, err := strconv.Atoi(numStr)
+// compareForPart compares serials by the part's schemes, falling back to string comparison
+// for serials that fit no scheme (ValidateSerial reports those up front)
+func (sc *SerialComparator) compareForPart(
+	partNumber entities.PartNumber,
+	serial1, serial2 string,
+) int {
+	result, err := sc.Compare(partNumber, serial1, serial2)
 	if err != nil {
-		return "", 0, fmt.Errorf("invalid numeric portion in serial %s: %v", serial, err)
+		return strings.Compare(serial1, serial2)
 	}
-
-	return prefix, num, nil
+	return result
 }
 
 // ResolveSerialEffectivity filters BOM lines to only those effective for the target serial
@@ -103,19 +175,28 @@ func (sc *SerialComparator) ResolveSerialEffectivity(
 	return effective
 }
 
-// ValidateSerialEffectivity checks for overlapping effectivity ranges for the same parent/child combination
+// ValidateSerialEffectivity checks that every effectivity serial fits a scheme of an end item
+// above its line and that effectivity ranges don't overlap for the same parent/child
+// combination
 func (sc *SerialComparator) ValidateSerialEffectivity(bomLines []*entities.BOMLine) error {
+	if err := sc.ValidateBOMSerials(bomLines); err != nil {
+		return err
+	}
+
 	// Group by parent/child combination
 	effectivityMap := make(map[string][]entities.SerialEffectivity)
+	endItems := make(map[string]entities.PartNumber)
+	above := EndItemsAbove(bomLines)
 
 	for _, line := range bomLines {
 		key := fmt.Sprintf("%s->%s", line.ParentPN, line.ChildPN)
 		effectivityMap[key] = append(effectivityMap[key], line.Effectivity)
+		endItems[key] = endItemsOf(above, line.ParentPN)[0]
 	}
 
 	// Check for overlaps within each group
 	for key, effectivities := range effectivityMap {
-		if err := sc.checkOverlaps(effectivities); err != nil {
+		if err := sc.checkOverlaps(endItems[key], effectivities); err != nil {
 			return fmt.Errorf("effectivity overlap for %s: %v", key, err)
 		}
 	}
@@ -123,11 +204,160 @@ func (sc *SerialComparator) ValidateSerialEffectivity(bomLines []*entities.BOMLi
 	return nil
 }
 
-// checkOverlaps verifies that effectivity ranges don't overlap
-func (sc *SerialComparator) checkOverlaps(effectivities []entities.SerialEffectivity) error {
+// ValidateBOMSerials checks that every effectivity serial fits a scheme of one of the end items
+// whose BOMs include the line (see EndItemsAbove), as the serials of demands for those end
+// items are what it is compared with. The error wraps an *entities.InvalidSerialError per bad
+// serial, one per line.
+func (sc *SerialComparator) ValidateBOMSerials(bomLines []*entities.BOMLine) error {
+	above := EndItemsAbove(bomLines)
+	var errs []error
+	for _, line := range bomLines {
+		for _, serial := range []string{line.Effectivity.FromSerial, line.Effectivity.ToSerial} {
+			if serial == "" {
+				continue
+			}
+			if err := sc.ValidateEffectivitySerial(above, line.ParentPN, serial); err != nil {
+				errs = append(errs, fmt.Errorf("%s->%s: %w", line.ParentPN, line.ChildPN, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid effectivity serials:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ValidateECOSerials checks that the cut-in serial of every ECO fits a scheme of an end item
+// above each line it changes. The error wraps an *entities.InvalidSerialError per bad change.
+func (sc *SerialComparator) ValidateECOSerials(ecos []*entities.ECO, bomLines []*entities.BOMLine) error {
+	above := EndItemsAbove(bomLines)
+	var errs []error
+	for _, eco := range ecos {
+		if eco.CutInSerial == "" {
+			continue
+		}
+		for _, change := range eco.Changes {
+			if err := sc.ValidateEffectivitySerial(above, change.Line.ParentPN, eco.CutInSerial); err != nil {
+				errs = append(errs, fmt.Errorf("ECO %s: %w", eco.ID, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid ECO cut-in serials:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ValidateEffectivitySerial checks that an effectivity serial on a BOM line of parentPN fits a
+// scheme of at least one end item above it (above comes from EndItemsAbove), returning the
+// error for the first end item when it fits none
+func (sc *SerialComparator) ValidateEffectivitySerial(
+	above map[entities.PartNumber][]entities.PartNumber,
+	parentPN entities.PartNumber,
+	serial string,
+) error {
+	var first error
+	for _, endItem := range endItemsOf(above, parentPN) {
+		err := sc.ValidateSerial(endItem, serial)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// EndItemsAbove returns, for every parent part of the BOM lines, the end items whose BOMs
+// include it: the parts above it that no line uses as a child, sorted by part number. An end
+// item is its own end item. Effectivity serials are serials of these end items.
+func EndItemsAbove(bomLines []*entities.BOMLine) map[entities.PartNumber][]entities.PartNumber {
+	parents := make(map[entities.PartNumber][]entities.PartNumber)
+	for _, line := range bomLines {
+		parents[line.ChildPN] = append(parents[line.ChildPN], line.ParentPN)
+	}
+
+	above := make(map[entities.PartNumber][]entities.PartNumber)
+	path := make(map[entities.PartNumber]bool)
+	var resolve func(part entities.PartNumber) []entities.PartNumber
+	resolve = func(part entities.PartNumber) []entities.PartNumber {
+		if endItems, done := above[part]; done {
+			return endItems
+		}
+		if len(parents[part]) == 0 {
+			above[part] = []entities.PartNumber{part}
+			return above[part]
+		}
+
+		path[part] = true
+		seen := make(map[entities.PartNumber]bool)
+		var endItems []entities.PartNumber
+		for _, parent := range parents[part] {
+			if path[parent] {
+				continue // A cycle, which BOM validation reports
+			}
+			for _, endItem := range resolve(parent) {
+				if !seen[endItem] {
+					seen[endItem] = true
+					endItems = append(endItems, endItem)
+				}
+			}
+		}
+		delete(path, part)
+
+		sort.Slice(endItems, func(i, j int) bool { return endItems[i] < endItems[j] })
+		above[part] = endItems
+		return endItems
+	}
+
+	for _, line := range bomLines {
+		resolve(line.ParentPN)
+	}
+	return above
+}
+
+// endItemsOf returns the end items above a part, or the part itself when none are known
+func endItemsOf(
+	above map[entities.PartNumber][]entities.PartNumber,
+	partNumber entities.PartNumber,
+) []entities.PartNumber {
+	if endItems := above[partNumber]; len(endItems) > 0 {
+		return endItems
+	}
+	return []entities.PartNumber{partNumber}
+}
+
+// ValidateDemandSerials checks that every demand's target serial fits a scheme of its part.
+// The error wraps an *entities.InvalidSerialError per bad serial, one per line.
+func (sc *SerialComparator) ValidateDemandSerials(demands []*entities.DemandRequirement) error {
+	var errs []error
+	for _, demand := range demands {
+		if demand.TargetSerial == "" {
+			continue
+		}
+		if err := sc.ValidateSerial(demand.PartNumber, demand.TargetSerial); err != nil {
+			errs = append(errs, fmt.Errorf("demand %s: %w", demand.DemandSource, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid demand serials:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// checkOverlaps verifies that effectivity ranges don't overlap, comparing serials with the
+// schemes of endItem
+func (sc *SerialComparator) checkOverlaps(
+	endItem entities.PartNumber,
+	effectivities []entities.SerialEffectivity,
+) error {
 	for i := 0; i < len(effectivities); i++ {
 		for j := i + 1; j < len(effectivities); j++ {
-			if sc.rangesOverlap(effectivities[i], effectivities[j]) {
+			if sc.rangesOverlap(endItem, effectivities[i], effectivities[j]) {
 				return fmt.Errorf("ranges overlap: [%s-%s] and [%s-%s]",
 					effectivities[i].FromSerial, effectivities[i].ToSerial,
 					effectivities[j].FromSerial, effectivities[j].ToSerial)
@@ -137,21 +367,15 @@ func (sc *SerialComparator) checkOverlaps(effectivities []entities.SerialEffecti
 	return nil
 }
 
-// rangesOverlap checks if two effectivity ranges overlap
-func (sc *SerialComparator) rangesOverlap(range1, range2 entities.SerialEffectivity) bool {
-	// Convert open-ended ranges to comparable form
-	end1 := range1.ToSerial
-	end2 := range2.ToSerial
-
-	// Handle open-ended ranges - use a very high value for comparison
-	if end1 == "" {
-		end1 = "ZZ999999" // Assumes this is higher than any real serial
-	}
-	if end2 == "" {
-		end2 = "ZZ999999"
-	}
-
-	// Check for overlap: range1.start <= range2.end && range2.start <= range1.end
-	return sc.CompareSerials(range1.FromSerial, end2) <= 0 &&
-		sc.CompareSerials(range2.FromSerial, end1) <= 0
+// rangesOverlap checks if two effectivity ranges overlap; an empty ToSerial is open ended
+func (sc *SerialComparator) rangesOverlap(
+	partNumber entities.PartNumber,
+	range1, range2 entities.SerialEffectivity,
+) bool {
+	// Overlap: range1.start <= range2.end && range2.start <= range1.end
+	startsBeforeEnd2 := range2.ToSerial == "" ||
+		sc.compareForPart(partNumber, range1.FromSerial, range2.ToSerial) <= 0
+	startsBeforeEnd1 := range1.ToSerial == "" ||
+		sc.compareForPart(partNumber, range2.FromSerial, range1.ToSerial) <= 0
+	return startsBeforeEnd2 && startsBeforeEnd1
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
@@ -236,3 +237,157 @@ func TestSerialComparator_ValidateSerialEffectivity(t *testing.T) {
 		})
 	}
 }
+
+func TestSerialComparator_Schemes(t *testing.T) {
+	saturn, err := NewSerialScheme("saturn", `SA-`, `[A-Z]?`, []string{"SATURN_V", "S_IC_*"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+	engine, err := NewSerialScheme("engine", `[A-Z0-9]+_`, ``, []string{"F1_*"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+	sc, err := NewSerialComparatorWithSchemes([]*SerialScheme{saturn, engine})
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		part     entities.PartNumber
+		serial1  string
+		serial2  string
+		expected int
+		wantErr  bool
+	}{
+		{"dashed_numeric_ordering", "SATURN_V", "SA-99", "SA-506", -1, false},
+		{"suffix_after_number", "SATURN_V", "SA-506", "SA-506B", -1, false},
+		{"suffix_before_next_number", "SATURN_V", "SA-506B", "SA-507", -1, false},
+		{"family_scheme", "S_IC_STAGE", "SA-510", "SA-509", 1, false},
+		{"underscore_prefix", "F1_ENGINE", "F1_009", "F1_010", -1, false},
+		{"default_scheme_for_other_parts", "J2_ENGINE", "SN009", "SN010", -1, false},
+		{"fits_no_scheme", "SATURN_V", "SA-506", "506", 0, true},
+		{"other_part_scheme_not_used", "J2_ENGINE", "SA-506", "SA-507", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sc.Compare(tt.part, tt.serial1, tt.serial2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compare(%s, %s, %s) error = %v, wantErr %v",
+					tt.part, tt.serial1, tt.serial2, err, tt.wantErr)
+			}
+			if !tt.wantErr && result != tt.expected {
+				t.Errorf("Compare(%s, %s, %s) = %d, want %d",
+					tt.part, tt.serial1, tt.serial2, result, tt.expected)
+			}
+		})
+	}
+
+	effectivity := entities.SerialEffectivity{FromSerial: "SA-99", ToSerial: "SA-506"}
+	if !sc.IsSerialInRangeForPart("SATURN_V", "SA-100", effectivity) {
+		t.Error("Expected SA-100 to be within SA-99..SA-506")
+	}
+	if sc.IsSerialInRangeForPart("SATURN_V", "SA-506A", effectivity) {
+		t.Error("Expected SA-506A to be after SA-506")
+	}
+}
+
+func TestSerialComparator_EndItemScheme(t *testing.T) {
+	saturn, err := NewSerialScheme("saturn", `SA-`, `[A-Z]?`, []string{"SATURN_V"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+	sc, err := NewSerialComparatorWithSchemes([]*SerialScheme{saturn})
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+
+	// Lines two levels down carry the end item's serials, which fit no scheme of their parents
+	lines := []*entities.BOMLine{
+		{ParentPN: "SATURN_V", ChildPN: "S_IC", Effectivity: entities.SerialEffectivity{FromSerial: "SA-1"}},
+		{ParentPN: "S_IC", ChildPN: "F1_ENGINE", Effectivity: entities.SerialEffectivity{FromSerial: "SA-1"}},
+		{
+			ParentPN: "F1_ENGINE", ChildPN: "TURBOPUMP_V1",
+			Effectivity: entities.SerialEffectivity{FromSerial: "SA-1", ToSerial: "SA-99"},
+		},
+		{ParentPN: "F1_ENGINE", ChildPN: "TURBOPUMP_V2", Effectivity: entities.SerialEffectivity{FromSerial: "SA-100"}},
+	}
+	if err := sc.ValidateBOMSerials(lines); err != nil {
+		t.Errorf("Expected end item serials to be valid below it, got: %v", err)
+	}
+	if err := sc.ValidateSerialEffectivity(lines); err != nil {
+		t.Errorf("Expected SA-1..SA-99 and SA-100.. not to overlap, got: %v", err)
+	}
+
+	if got := EndItemsAbove(lines)["F1_ENGINE"]; len(got) != 1 || got[0] != "SATURN_V" {
+		t.Errorf("Expected SATURN_V above F1_ENGINE, got %v", got)
+	}
+
+	ecos := []*entities.ECO{
+		{
+			ID:          "ECO-1",
+			CutInSerial: "506",
+			Changes:     []entities.ECOChange{{Line: *lines[3]}},
+		},
+	}
+	var invalidSerial *entities.InvalidSerialError
+	if err := sc.ValidateECOSerials(ecos, lines); !errors.As(err, &invalidSerial) {
+		t.Errorf("Expected an InvalidSerialError for a cut-in fitting no end item scheme, got %v", err)
+	}
+}
+
+func TestSerialComparator_ValidateSerials(t *testing.T) {
+	sc := NewSerialComparator()
+
+	valid := []*entities.BOMLine{
+		{ParentPN: "ENGINE", ChildPN: "PART_A", Effectivity: entities.SerialEffectivity{FromSerial: "SN001"}},
+	}
+	if err := sc.ValidateBOMSerials(valid); err != nil {
+		t.Errorf("Expected valid BOM serials, got: %v", err)
+	}
+
+	invalid := []*entities.BOMLine{
+		{ParentPN: "ENGINE", ChildPN: "PART_A", Effectivity: entities.SerialEffectivity{FromSerial: "SN-001"}},
+	}
+	err := sc.ValidateBOMSerials(invalid)
+	var invalidSerial *entities.InvalidSerialError
+	if !errors.As(err, &invalidSerial) {
+		t.Fatalf("Expected an InvalidSerialError for serial fitting no scheme, got %v", err)
+	}
+	if invalidSerial.PartNumber != "ENGINE" || invalidSerial.Serial != "SN-001" {
+		t.Errorf("Expected ENGINE serial SN-001 to be reported, got %+v", invalidSerial)
+	}
+
+	demands := []*entities.DemandRequirement{
+		{PartNumber: "ENGINE", DemandSource: "APOLLO_11", TargetSerial: "F1_001"},
+	}
+	if err := sc.ValidateDemandSerials(demands); !errors.As(err, &invalidSerial) {
+		t.Errorf("Expected an InvalidSerialError for demand serial fitting no scheme, got %v", err)
+	}
+}
+
+func TestSerialComparator_OpenEndedOverlapWithoutSentinel(t *testing.T) {
+	sc := NewSerialComparator()
+
+	// Serials sorting after any fixed sentinel value must still fall inside an open range
+	lines := []*entities.BOMLine{
+		{
+			ParentPN: "ENGINE", ChildPN: "PART_A",
+			Effectivity: entities.SerialEffectivity{FromSerial: "SN001", ToSerial: ""},
+		},
+		{
+			ParentPN: "ENGINE", ChildPN: "PART_A",
+			Effectivity: entities.SerialEffectivity{FromSerial: "ZZZ1", ToSerial: "ZZZ5"},
+		},
+	}
+	if err := sc.ValidateSerialEffectivity(lines); err == nil {
+		t.Error("Expected ZZZ1-ZZZ5 to overlap the open range starting at SN001")
+	}
+
+	lines[0].Effectivity = entities.SerialEffectivity{FromSerial: "SN001", ToSerial: "SN050"}
+	lines[1].Effectivity = entities.SerialEffectivity{FromSerial: "SN051", ToSerial: ""}
+	if err := sc.ValidateSerialEffectivity(lines); err != nil {
+		t.Errorf("Expected adjacent ranges not to overlap, got: %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/services"
 )
 
 // Loader handles loading MRP data from CSV files
@@ -37,39 +38,26 @@ func (l *Loader) LoadItems(filename string) ([]*entities.Item, error) {
 		return nil, fmt.Errorf("items CSV must have header and at least one data row")
 	}
 
-	// Validate header
-	expectedHeader := []string{
-		"part_number",
-		"description",
-		"lead_time_days",
-		"lot_size_rule",
-		"min_order_qty",
-		"max_order_qty",
-		"safety_stock",
-		"unit_of_measure",
-		"make_buy_code",
-	}
+	// Validate header - the base columns are required, optional columns may follow in any order
 	header := records[0]
-	if !validateHeader(header, expectedHeader) {
-		return nil, fmt.Errorf(
-			"items CSV header mismatch. Expected: %v, Got: %v",
-			expectedHeader,
-			header,
-		)
+
+	columns, err := resolveOptionalColumns(header, itemBaseHeader, itemOptionalColumns)
+	if err != nil {
+		return nil, fmt.Errorf("items CSV header mismatch: %w", err)
 	}
 
 	var items []*entities.Item
 	for i, record := range records[1:] {
-		if len(record) != len(expectedHeader) {
+		if len(record) != len(header) {
 			return nil, fmt.Errorf(
 				"items CSV row %d: expected %d columns, got %d",
 				i+2,
-				len(expectedHeader),
+				len(header),
 				len(record),
 			)
 		}
 
-		item, err := parseItem(record)
+		item, err := parseItem(record, columns)
 		if err != nil {
 			return nil, fmt.Errorf("items CSV row %d: %w", i+2, err)
 		}
@@ -80,6 +68,47 @@ func (l *Loader) LoadItems(filename string) ([]*entities.Item, error) {
 	return items, nil
 }
 
+// itemBaseHeader lists the required items.csv columns
+var itemBaseHeader = []string{
+	"part_number",
+	"description",
+	"lead_time_days",
+	"lot_size_rule",
+	"min_order_qty",
+	"max_order_qty",
+	"safety_stock",
+	"unit_of_measure",
+	"make_buy_code",
+}
+
+// itemOptionalColumns lists the items.csv columns that may follow the base columns in any order
+var itemOptionalColumns = []string{
+	"phantom",
+	"lead_time_min",
+	"lead_time_max",
+	"lead_time_distribution",
+	"demand_time_fence",
+	"planning_time_fence",
+}
+
+// bomBaseHeader lists the required bom.csv columns
+var bomBaseHeader = []string{
+	"parent_pn",
+	"child_pn",
+	"qty_per",
+	"find_number",
+	"from_serial",
+	"to_serial",
+}
+
+// bomOptionalColumns lists the bom.csv columns that may follow the base columns in any order
+var bomOptionalColumns = []string{
+	"priority",
+	"lead_time_offset_days",
+	"from_date",
+	"to_date",
+}
+
 // LoadBOM loads BOM lines from a CSV file
 func (l *Loader) LoadBOM(filename string) ([]*entities.BOMLine, error) {
 	file, err := os.Open(filename)
@@ -98,52 +127,26 @@ func (l *Loader) LoadBOM(filename string) ([]*entities.BOMLine, error) {
 		return nil, fmt.Errorf("BOM CSV must have header and at least one data row")
 	}
 
-	// Validate header - support both old format (without priority) and new format (with priority)
-	expectedHeaderOld := []string{
-		"parent_pn",
-		"child_pn",
-		"qty_per",
-		"find_number",
-		"from_serial",
-		"to_serial",
-	}
-	expectedHeaderNew := []string{
-		"parent_pn",
-		"child_pn",
-		"qty_per",
-		"find_number",
-		"from_serial",
-		"to_serial",
-		"priority",
-	}
+	// Validate header - the base columns are required, optional columns may follow in any order
 	header := records[0]
 
-	var hasPriority bool
-	if validateHeader(header, expectedHeaderNew) {
-		hasPriority = true
-	} else if validateHeader(header, expectedHeaderOld) {
-		hasPriority = false
-	} else {
-		return nil, fmt.Errorf("BOM CSV header mismatch. Expected: %v or %v, Got: %v", expectedHeaderOld, expectedHeaderNew, header)
+	columns, err := resolveOptionalColumns(header, bomBaseHeader, bomOptionalColumns)
+	if err != nil {
+		return nil, fmt.Errorf("BOM CSV header mismatch: %w", err)
 	}
 
 	var bomLines []*entities.BOMLine
 	for i, record := range records[1:] {
-		expectedCols := len(expectedHeaderOld)
-		if hasPriority {
-			expectedCols = len(expectedHeaderNew)
-		}
-
-		if len(record) != expectedCols {
+		if len(record) != len(header) {
 			return nil, fmt.Errorf(
 				"BOM CSV row %d: expected %d columns, got %d",
 				i+2,
-				expectedCols,
+				len(header),
 				len(record),
 			)
 		}
 
-		bomLine, err := parseBOMLineWithPriority(record, hasPriority)
+		bomLine, err := parseBOMLineWithColumns(record, columns)
 		if err != nil {
 			return nil, fmt.Errorf("BOM CSV row %d: %w", i+2, err)
 		}
@@ -333,6 +336,364 @@ func (l *Loader) LoadDemands(filename string) ([]*entities.DemandRequirement, er
 	return demands, nil
 }
 
+// LoadForecasts loads the forecast independent demand of end items, one row per item, location
+// and period, from a CSV file
+func (l *Loader) LoadForecasts(filename string) ([]*entities.Forecast, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open forecasts file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forecasts CSV: %w", err)
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("forecasts CSV must have header and at least one data row")
+	}
+
+	expectedHeader := []string{
+		"part_number",
+		"quantity",
+		"period_start",
+		"location",
+		"target_serial",
+	}
+	header := records[0]
+	if !validateHeader(header, expectedHeader) {
+		return nil, fmt.Errorf(
+			"forecasts CSV header mismatch. Expected: %v, Got: %v",
+			expectedHeader,
+			header,
+		)
+	}
+
+	var forecasts []*entities.Forecast
+	for i, record := range records[1:] {
+		if len(record) != len(expectedHeader) {
+			return nil, fmt.Errorf(
+				"forecasts CSV row %d: expected %d columns, got %d",
+				i+2,
+				len(expectedHeader),
+				len(record),
+			)
+		}
+
+		forecast, err := parseForecast(record)
+		if err != nil {
+			return nil, fmt.Errorf("forecasts CSV row %d: %w", i+2, err)
+		}
+
+		forecasts = append(forecasts, forecast)
+	}
+
+	return forecasts, nil
+}
+
+// LoadECOs loads engineering change orders from a CSV file. Each row is one change; the
+// eco_id, description, status and cut_in_serial columns repeat for every change of an ECO,
+// and the remaining columns follow the bom.csv layout.
+func (l *Loader) LoadECOs(filename string) ([]*entities.ECO, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ECO file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ECO CSV: %w", err)
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("ECO CSV must have header and at least one data row")
+	}
+
+	ecoHeader := []string{
+		"eco_id",
+		"description",
+		"status",
+		"cut_in_serial",
+		"action",
+	}
+	header := records[0]
+	if len(header) < len(ecoHeader) || !validateHeader(header[:len(ecoHeader)], ecoHeader) {
+		return nil, fmt.Errorf(
+			"ECO CSV header mismatch: expected %v followed by the bom.csv columns, got %v",
+			ecoHeader,
+			header,
+		)
+	}
+
+	// The line columns are resolved relative to the start of the bom.csv layout
+	columns, err := resolveOptionalColumns(header[len(ecoHeader):], bomBaseHeader, bomOptionalColumns)
+	if err != nil {
+		return nil, fmt.Errorf("ECO CSV header mismatch: %w", err)
+	}
+
+	var order []string
+	firstRows := make(map[string][]string)
+	headers := make(map[string][]string)
+	changes := make(map[string][]entities.ECOChange)
+	for i, record := range records[1:] {
+		if len(record) != len(header) {
+			return nil, fmt.Errorf(
+				"ECO CSV row %d: expected %d columns, got %d",
+				i+2,
+				len(header),
+				len(record),
+			)
+		}
+
+		ecoID := strings.TrimSpace(record[0])
+		if previous, exists := headers[ecoID]; exists {
+			if !validateHeader(record[1:4], previous) {
+				return nil, fmt.Errorf(
+					"ECO CSV row %d: description, status and cut_in_serial must match earlier rows of %s",
+					i+2,
+					ecoID,
+				)
+			}
+		} else {
+			order = append(order, ecoID)
+			firstRows[ecoID] = record
+			headers[ecoID] = []string{
+				strings.ToLower(strings.TrimSpace(record[1])),
+				strings.ToLower(strings.TrimSpace(record[2])),
+				strings.ToLower(strings.TrimSpace(record[3])),
+			}
+		}
+
+		change, err := parseECOChange(record[4], record[len(ecoHeader):], columns)
+		if err != nil {
+			return nil, fmt.Errorf("ECO CSV row %d: %w", i+2, err)
+		}
+		changes[ecoID] = append(changes[ecoID], change)
+	}
+
+	var ecos []*entities.ECO
+	for _, ecoID := range order {
+		first := firstRows[ecoID]
+		status, err := parseECOStatus(first[2])
+		if err != nil {
+			return nil, fmt.Errorf("ECO %s: %w", ecoID, err)
+		}
+
+		eco, err := entities.NewECO(
+			ecoID,
+			strings.TrimSpace(first[1]),
+			status,
+			strings.TrimSpace(first[3]),
+			changes[ecoID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECO: %w", err)
+		}
+		ecos = append(ecos, eco)
+	}
+
+	return ecos, nil
+}
+
+// LoadScheduledReceipts loads open orders, already released to the shop floor or a supplier,
+// from a CSV file
+func (l *Loader) LoadScheduledReceipts(filename string) ([]entities.ScheduledReceipt, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scheduled receipts file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled receipts CSV: %w", err)
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("scheduled receipts CSV must have header and at least one data row")
+	}
+
+	expectedHeader := []string{
+		"order_number",
+		"part_number",
+		"quantity",
+		"due_date",
+		"location",
+		"order_type",
+	}
+	header := records[0]
+	if !validateHeader(header, expectedHeader) {
+		return nil, fmt.Errorf(
+			"scheduled receipts CSV header mismatch. Expected: %v, Got: %v",
+			expectedHeader,
+			header,
+		)
+	}
+
+	var receipts []entities.ScheduledReceipt
+	seen := make(map[string]bool)
+	for i, record := range records[1:] {
+		if len(record) != len(expectedHeader) {
+			return nil, fmt.Errorf(
+				"scheduled receipts CSV row %d: expected %d columns, got %d",
+				i+2,
+				len(expectedHeader),
+				len(record),
+			)
+		}
+
+		receipt, err := parseScheduledReceipt(record)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled receipts CSV row %d: %w", i+2, err)
+		}
+		if seen[receipt.OrderNumber] {
+			return nil, fmt.Errorf("scheduled receipts CSV row %d: duplicate order_number %s",
+				i+2, receipt.OrderNumber)
+		}
+		seen[receipt.OrderNumber] = true
+
+		receipts = append(receipts, *receipt)
+	}
+
+	return receipts, nil
+}
+
+// LoadFirmPlannedOrders loads the planned orders a planner has fixed from a CSV file
+func (l *Loader) LoadFirmPlannedOrders(filename string) ([]entities.PlannedOrder, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open firm planned orders file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read firm planned orders CSV: %w", err)
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("firm planned orders CSV must have header and at least one data row")
+	}
+
+	expectedHeader := []string{
+		"order_number",
+		"part_number",
+		"quantity",
+		"start_date",
+		"due_date",
+		"location",
+		"order_type",
+	}
+	header := records[0]
+	if !validateHeader(header, expectedHeader) {
+		return nil, fmt.Errorf(
+			"firm planned orders CSV header mismatch. Expected: %v, Got: %v",
+			expectedHeader,
+			header,
+		)
+	}
+
+	var orders []entities.PlannedOrder
+	seen := make(map[string]bool)
+	for i, record := range records[1:] {
+		if len(record) != len(expectedHeader) {
+			return nil, fmt.Errorf(
+				"firm planned orders CSV row %d: expected %d columns, got %d",
+				i+2,
+				len(expectedHeader),
+				len(record),
+			)
+		}
+
+		order, err := parseFirmPlannedOrder(record)
+		if err != nil {
+			return nil, fmt.Errorf("firm planned orders CSV row %d: %w", i+2, err)
+		}
+		if seen[order.OrderNumber] {
+			return nil, fmt.Errorf("firm planned orders CSV row %d: duplicate order_number %s",
+				i+2, order.OrderNumber)
+		}
+		seen[order.OrderNumber] = true
+
+		orders = append(orders, *order)
+	}
+
+	return orders, nil
+}
+
+// LoadSerialSchemes loads serial number schemes from a CSV file. applies_to holds one or more
+// part numbers, families ("F1_*") or "*", separated by semicolons.
+func (l *Loader) LoadSerialSchemes(filename string) ([]*services.SerialScheme, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial schemes file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read serial schemes CSV: %w", err)
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("serial schemes CSV must have header and at least one data row")
+	}
+
+	expectedHeader := []string{
+		"name",
+		"prefix_pattern",
+		"suffix_pattern",
+		"applies_to",
+	}
+	header := records[0]
+	if !validateHeader(header, expectedHeader) {
+		return nil, fmt.Errorf(
+			"serial schemes CSV header mismatch. Expected: %v, Got: %v",
+			expectedHeader,
+			header,
+		)
+	}
+
+	var schemes []*services.SerialScheme
+	for i, record := range records[1:] {
+		if len(record) != len(expectedHeader) {
+			return nil, fmt.Errorf(
+				"serial schemes CSV row %d: expected %d columns, got %d",
+				i+2,
+				len(expectedHeader),
+				len(record),
+			)
+		}
+
+		var appliesTo []string
+		for _, target := range strings.Split(record[3], ";") {
+			if target = strings.TrimSpace(target); target != "" {
+				appliesTo = append(appliesTo, target)
+			}
+		}
+
+		scheme, err := services.NewSerialScheme(
+			strings.TrimSpace(record[0]),
+			strings.TrimSpace(record[1]),
+			strings.TrimSpace(record[2]),
+			appliesTo,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("serial schemes CSV row %d: %w", i+2, err)
+		}
+		schemes = append(schemes, scheme)
+	}
+
+	return schemes, nil
+}
+
 // Helper functions for parsing CSV records
 
 func validateHeader(actual, expected []string) bool {
@@ -349,7 +710,44 @@ func validateHeader(actual, expected []string) bool {
 	return true
 }
 
-func parseItem(record []string) (entities.Item, error) {
+// resolveOptionalColumns checks that header starts with the base columns and that any remaining
+// columns are known optional columns, returning the index of each optional column present
+func resolveOptionalColumns(header, base, optional []string) (map[string]int, error) {
+	if len(header) < len(base) || !validateHeader(header[:len(base)], base) {
+		return nil, fmt.Errorf("expected %v followed by any of %v, got %v", base, optional, header)
+	}
+
+	known := make(map[string]bool, len(optional))
+	for _, col := range optional {
+		known[col] = true
+	}
+
+	columns := make(map[string]int)
+	for i := len(base); i < len(header); i++ {
+		col := strings.ToLower(strings.TrimSpace(header[i]))
+		if !known[col] {
+			return nil, fmt.Errorf("unknown column %q (optional columns: %v)", header[i], optional)
+		}
+		if _, duplicate := columns[col]; duplicate {
+			return nil, fmt.Errorf("duplicate column %q", header[i])
+		}
+		columns[col] = i
+	}
+
+	return columns, nil
+}
+
+// optionalValue returns the trimmed value of an optional column, or false if absent or blank
+func optionalValue(record []string, columns map[string]int, column string) (string, bool) {
+	index, exists := columns[column]
+	if !exists || index >= len(record) {
+		return "", false
+	}
+	value := strings.TrimSpace(record[index])
+	return value, value != ""
+}
+
+func parseItem(record []string, columns map[string]int) (entities.Item, error) {
 	partNumber := entities.PartNumber(record[0])
 	description := record[1]
 
@@ -399,9 +797,88 @@ func parseItem(record []string) (entities.Item, error) {
 	if err != nil {
 		return entities.Item{}, fmt.Errorf("invalid item: %w", err)
 	}
+
+	// Phantom column is optional; blank means a normal stocked item
+	if value, ok := optionalValue(record, columns, "phantom"); ok {
+		phantom, err := strconv.ParseBool(value)
+		if err != nil {
+			return entities.Item{}, fmt.Errorf("invalid phantom: %s", value)
+		}
+		item.Phantom = phantom
+	}
+
+	leadTimeRange, err := parseLeadTimeRange(record, columns, leadTimeDays)
+	if err != nil {
+		return entities.Item{}, err
+	}
+	item.LeadTimeRange = leadTimeRange
+
+	// Time fence columns are optional; blank means no fence
+	for _, fence := range []struct {
+		column string
+		days   *int
+	}{
+		{"demand_time_fence", &item.DemandTimeFenceDays},
+		{"planning_time_fence", &item.PlanningTimeFenceDays},
+	} {
+		value, ok := optionalValue(record, columns, fence.column)
+		if !ok {
+			continue
+		}
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return entities.Item{}, fmt.Errorf("invalid %s: %s (expected days, 0 or more)", fence.column, value)
+		}
+		*fence.days = days
+	}
+
 	return *item, nil
 }
 
+// parseLeadTimeRange reads the optional lead_time_min/lead_time_max/lead_time_distribution
+// columns around the likely lead_time_days. Blank bounds leave the lead time a single point;
+// the distribution defaults to triangular.
+func parseLeadTimeRange(
+	record []string,
+	columns map[string]int,
+	likelyDays int,
+) (*entities.LeadTimeDistribution, error) {
+	minValue, hasMin := optionalValue(record, columns, "lead_time_min")
+	maxValue, hasMax := optionalValue(record, columns, "lead_time_max")
+	shapeValue, hasShape := optionalValue(record, columns, "lead_time_distribution")
+	if !hasMin && !hasMax {
+		if hasShape {
+			return nil, fmt.Errorf("lead_time_distribution %s needs lead_time_min and lead_time_max", shapeValue)
+		}
+		return nil, nil
+	}
+	if !hasMin || !hasMax {
+		return nil, fmt.Errorf("lead_time_min and lead_time_max must be given together")
+	}
+
+	minDays, err := strconv.Atoi(minValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lead_time_min: %s", minValue)
+	}
+	maxDays, err := strconv.Atoi(maxValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lead_time_max: %s", maxValue)
+	}
+	shape := entities.Triangular
+	if hasShape {
+		shape, err = entities.ParseDistributionShape(shapeValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	leadTimeRange, err := entities.NewLeadTimeDistribution(shape, minDays, likelyDays, maxDays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lead time range: %w", err)
+	}
+	return leadTimeRange, nil
+}
+
 func parseBOMLine(record []string) (entities.BOMLine, error) {
 	parentPN := entities.PartNumber(record[0])
 	childPN := entities.PartNumber(record[1])
@@ -438,7 +915,7 @@ func parseBOMLine(record []string) (entities.BOMLine, error) {
 	return *bomLine, nil
 }
 
-func parseBOMLineWithPriority(record []string, hasPriority bool) (entities.BOMLine, error) {
+func parseBOMLineWithColumns(record []string, columns map[string]int) (entities.BOMLine, error) {
 	parentPN := entities.PartNumber(record[0])
 	childPN := entities.PartNumber(record[1])
 
@@ -462,10 +939,10 @@ func parseBOMLineWithPriority(record []string, hasPriority bool) (entities.BOMLi
 
 	// Default priority is 0 (standard/primary)
 	priority := 0
-	if hasPriority && len(record) > 6 {
-		priority, err = strconv.Atoi(record[6])
+	if value, ok := optionalValue(record, columns, "priority"); ok {
+		priority, err = strconv.Atoi(value)
 		if err != nil {
-			return entities.BOMLine{}, fmt.Errorf("invalid priority: %s", record[6])
+			return entities.BOMLine{}, fmt.Errorf("invalid priority: %s", value)
 		}
 	}
 
@@ -480,9 +957,56 @@ func parseBOMLineWithPriority(record []string, hasPriority bool) (entities.BOMLi
 	if err != nil {
 		return entities.BOMLine{}, fmt.Errorf("invalid BOM line: %w", err)
 	}
+
+	// Default offset is 0 (child must be complete before the parent starts)
+	if value, ok := optionalValue(record, columns, "lead_time_offset_days"); ok {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return entities.BOMLine{}, fmt.Errorf("invalid lead_time_offset_days: %s", value)
+		}
+		if offset < 0 {
+			return entities.BOMLine{}, fmt.Errorf("lead_time_offset_days cannot be negative, got %d", offset)
+		}
+		bomLine.LeadTimeOffsetDays = offset
+	}
+
+	dateEffectivity, err := parseDateEffectivity(record, columns)
+	if err != nil {
+		return entities.BOMLine{}, err
+	}
+	bomLine.DateEffectivity = dateEffectivity
+
 	return *bomLine, nil
 }
 
+// parseDateEffectivity reads the optional from_date/to_date columns.
+// Blank dates leave the date effectivity window open on that side.
+func parseDateEffectivity(
+	record []string,
+	columns map[string]int,
+) (entities.DateEffectivity, error) {
+	var fromDate, toDate time.Time
+	var err error
+	if value, ok := optionalValue(record, columns, "from_date"); ok {
+		fromDate, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return entities.DateEffectivity{}, fmt.Errorf("invalid from_date: %s", value)
+		}
+	}
+	if value, ok := optionalValue(record, columns, "to_date"); ok {
+		toDate, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return entities.DateEffectivity{}, fmt.Errorf("invalid to_date: %s", value)
+		}
+	}
+
+	dateEffectivity, err := entities.NewDateEffectivity(fromDate, toDate)
+	if err != nil {
+		return entities.DateEffectivity{}, fmt.Errorf("invalid date effectivity: %w", err)
+	}
+	return *dateEffectivity, nil
+}
+
 func parseDemand(record []string) (entities.DemandRequirement, error) {
 	partNumber := entities.PartNumber(record[0])
 
@@ -513,6 +1037,147 @@ func parseDemand(record []string) (entities.DemandRequirement, error) {
 	}, nil
 }
 
+func parseForecast(record []string) (*entities.Forecast, error) {
+	quantity, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %s", record[1])
+	}
+
+	periodStart, err := time.Parse("2006-01-02", strings.TrimSpace(record[2]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid period_start format: %s (expected YYYY-MM-DD)", record[2])
+	}
+
+	return entities.NewForecast(
+		entities.PartNumber(strings.TrimSpace(record[0])),
+		entities.Quantity(quantity),
+		periodStart,
+		strings.TrimSpace(record[3]),
+		strings.TrimSpace(record[4]),
+	)
+}
+
+func parseScheduledReceipt(record []string) (*entities.ScheduledReceipt, error) {
+	quantity, err := strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %s", record[2])
+	}
+
+	dueDate, err := time.Parse("2006-01-02", strings.TrimSpace(record[3]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid due_date format: %s (expected YYYY-MM-DD)", record[3])
+	}
+
+	orderType, err := parseOrderType(record[5])
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.NewScheduledReceipt(
+		strings.TrimSpace(record[0]),
+		entities.PartNumber(strings.TrimSpace(record[1])),
+		entities.Quantity(quantity),
+		dueDate,
+		strings.TrimSpace(record[4]),
+		orderType,
+	)
+}
+
+func parseFirmPlannedOrder(record []string) (*entities.PlannedOrder, error) {
+	quantity, err := strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %s", record[2])
+	}
+
+	startDate, err := time.Parse("2006-01-02", strings.TrimSpace(record[3]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date format: %s (expected YYYY-MM-DD)", record[3])
+	}
+
+	dueDate, err := time.Parse("2006-01-02", strings.TrimSpace(record[4]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid due_date format: %s (expected YYYY-MM-DD)", record[4])
+	}
+
+	orderType, err := parseOrderType(record[6])
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.NewFirmPlannedOrder(
+		strings.TrimSpace(record[0]),
+		entities.PartNumber(strings.TrimSpace(record[1])),
+		entities.Quantity(quantity),
+		startDate,
+		dueDate,
+		strings.TrimSpace(record[5]),
+		orderType,
+	)
+}
+
+// parseECOChange parses the action and bom.csv-layout columns of an ECO row. Removals and
+// effectivity changes only need parent_pn, child_pn and find_number to identify the line.
+func parseECOChange(
+	action string,
+	record []string,
+	columns map[string]int,
+) (entities.ECOChange, error) {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "add":
+		line, err := parseBOMLineWithColumns(record, columns)
+		if err != nil {
+			return entities.ECOChange{}, err
+		}
+		return entities.ECOChange{Action: entities.ECOAddLine, Line: line}, nil
+
+	case "remove", "effectivity":
+		findNumber, err := strconv.Atoi(strings.TrimSpace(record[3]))
+		if err != nil {
+			return entities.ECOChange{}, fmt.Errorf("invalid find_number: %s", record[3])
+		}
+		change := entities.ECOChange{
+			Action: entities.ECORemoveLine,
+			Line: entities.BOMLine{
+				ParentPN:   entities.PartNumber(strings.TrimSpace(record[0])),
+				ChildPN:    entities.PartNumber(strings.TrimSpace(record[1])),
+				FindNumber: findNumber,
+			},
+		}
+		if strings.EqualFold(strings.TrimSpace(action), "remove") {
+			return change, nil
+		}
+
+		change.Action = entities.ECOChangeEffectivity
+		effectivity, err := entities.NewSerialEffectivity(record[4], record[5])
+		if err != nil {
+			return entities.ECOChange{}, fmt.Errorf("invalid serial effectivity: %w", err)
+		}
+		change.Effectivity = *effectivity
+		change.DateEffectivity, err = parseDateEffectivity(record, columns)
+		if err != nil {
+			return entities.ECOChange{}, err
+		}
+		return change, nil
+
+	default:
+		return entities.ECOChange{}, fmt.Errorf(
+			"invalid action: %s (expected: add, remove, or effectivity)",
+			action,
+		)
+	}
+}
+
+func parseECOStatus(s string) (entities.ECOStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "draft":
+		return entities.ECODraft, nil
+	case "released":
+		return entities.ECOReleased, nil
+	default:
+		return entities.ECODraft, fmt.Errorf("invalid status: %s (expected: draft or released)", s)
+	}
+}
+
 func parseLotSizeRule(s string) (entities.LotSizeRule, error) {
 	switch strings.ToLower(s) {
 	case "lotforlot":
@@ -558,3 +1223,19 @@ func parseMakeBuyCode(s string) (entities.MakeBuyCode, error) {
 		)
 	}
 }
+
+func parseOrderType(s string) (entities.OrderType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "make":
+		return entities.Make, nil
+	case "buy":
+		return entities.Buy, nil
+	case "transfer":
+		return entities.Transfer, nil
+	default:
+		return entities.Make, fmt.Errorf(
+			"invalid order_type: %s (expected: Make, Buy, or Transfer)",
+			s,
+		)
+	}
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
 	"github.com/vsinha/mrp/pkg/domain/repositories"
@@ -14,6 +15,7 @@ import (
 type BOMRepository struct {
 	bomLines   []entities.BOMLine
 	bomIndexes map[entities.PartNumber][]int
+	usedIn     map[entities.PartNumber][]int // child -> indexes of lines using it
 	serialComp *services.SerialComparator
 }
 
@@ -22,6 +24,7 @@ func NewBOMRepository(expectedBOMLines int) *BOMRepository {
 	return &BOMRepository{
 		bomLines:   make([]entities.BOMLine, 0, expectedBOMLines),
 		bomIndexes: make(map[entities.PartNumber][]int, expectedBOMLines/10),
+		usedIn:     make(map[entities.PartNumber][]int, expectedBOMLines/10),
 		serialComp: services.NewSerialComparator(),
 	}
 }
@@ -29,6 +32,12 @@ func NewBOMRepository(expectedBOMLines int) *BOMRepository {
 // Verify interface compliance
 var _ repositories.BOMRepository = (*BOMRepository)(nil)
 
+// SetSerialComparator replaces the comparator used for serial effectivity, e.g. one
+// configured with the scenario's serial schemes
+func (r *BOMRepository) SetSerialComparator(serialComp *services.SerialComparator) {
+	r.serialComp = serialComp
+}
+
 // LoadBOMLines loads BOM lines into the repository
 func (r *BOMRepository) LoadBOMLines(lines []*entities.BOMLine) error {
 	for _, line := range lines {
@@ -36,7 +45,8 @@ func (r *BOMRepository) LoadBOMLines(lines []*entities.BOMLine) error {
 	}
 	validationResult := bom_validator.ValidateBOM(r.bomLines)
 	if validationResult.HasCycles {
-		return fmt.Errorf("BOM validation failed: %v", validationResult.Errors)
+		return fmt.Errorf("BOM validation failed: %w",
+			&entities.CyclicBOMError{Cycle: validationResult.CyclePaths[0]})
 	}
 	return nil
 }
@@ -46,6 +56,7 @@ func (r *BOMRepository) AddBOMLine(line entities.BOMLine) {
 	index := len(r.bomLines)
 	r.bomLines = append(r.bomLines, line)
 	r.bomIndexes[line.ParentPN] = append(r.bomIndexes[line.ParentPN], index)
+	r.usedIn[line.ChildPN] = append(r.usedIn[line.ChildPN], index)
 }
 
 // GetBOMLines returns all BOM lines for a part number
@@ -64,9 +75,10 @@ func (r *BOMRepository) GetBOMLines(partNumber entities.PartNumber) ([]*entities
 	return lines, nil
 }
 
-// GetEffectiveLines returns the effective BOM lines for a part and target serial
+// GetEffectiveLines returns the effective BOM lines for a part and a target serial of endItem
 func (r *BOMRepository) GetEffectiveLines(
 	partNumber entities.PartNumber,
+	endItem entities.PartNumber,
 	serial string,
 ) ([]*entities.BOMLine, error) {
 	indexes, exists := r.bomIndexes[partNumber]
@@ -77,7 +89,7 @@ func (r *BOMRepository) GetEffectiveLines(
 	var effectiveLines []*entities.BOMLine
 	for _, index := range indexes {
 		line := r.bomLines[index]
-		if r.serialComp.IsSerialInRange(serial, line.Effectivity) {
+		if r.serialComp.IsSerialInRangeForPart(endItem, serial, line.Effectivity) {
 			effectiveLines = append(effectiveLines, &line)
 		}
 	}
@@ -85,6 +97,22 @@ func (r *BOMRepository) GetEffectiveLines(
 	return effectiveLines, nil
 }
 
+// GetWhereUsed returns every BOM line that uses a part as its child
+func (r *BOMRepository) GetWhereUsed(childPN entities.PartNumber) ([]*entities.BOMLine, error) {
+	indexes, exists := r.usedIn[childPN]
+	if !exists {
+		return []*entities.BOMLine{}, nil
+	}
+
+	var lines []*entities.BOMLine
+	for _, index := range indexes {
+		line := r.bomLines[index]
+		lines = append(lines, &line)
+	}
+
+	return lines, nil
+}
+
 // GetAllBOMLines returns all BOM lines
 func (r *BOMRepository) GetAllBOMLines() ([]*entities.BOMLine, error) {
 	var lines []*entities.BOMLine
@@ -119,11 +147,13 @@ func (r *BOMRepository) GetAlternateGroups(
 	return groups, nil
 }
 
-// GetEffectiveAlternates returns alternate BOM lines for a specific FindNumber and serial
+// GetEffectiveAlternates returns alternate BOM lines for a specific FindNumber, serial and need date
 func (r *BOMRepository) GetEffectiveAlternates(
 	parentPN entities.PartNumber,
 	findNumber int,
+	endItem entities.PartNumber,
 	targetSerial string,
+	needDate time.Time,
 ) ([]*entities.BOMLine, error) {
 	indexes, exists := r.bomIndexes[parentPN]
 	if !exists {
@@ -133,9 +163,10 @@ func (r *BOMRepository) GetEffectiveAlternates(
 	var alternates []*entities.BOMLine
 	for _, index := range indexes {
 		line := r.bomLines[index]
-		// Filter by FindNumber and serial effectivity
+		// Filter by FindNumber, serial effectivity and date effectivity
 		if line.FindNumber == findNumber &&
-			r.serialComp.IsSerialInRange(targetSerial, line.Effectivity) {
+			r.serialComp.IsSerialInRangeForPart(endItem, targetSerial, line.Effectivity) &&
+			line.DateEffectivity.IsEffectiveOn(needDate) {
 			alternates = append(alternates, &line)
 		}
 	}
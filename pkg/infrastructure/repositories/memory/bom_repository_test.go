@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
 )
@@ -95,7 +97,7 @@ func TestBOMRepository_GetEffectiveLines(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			effectiveLines, err := repo.GetEffectiveLines("ENGINE", tt.targetSerial)
+			effectiveLines, err := repo.GetEffectiveLines("ENGINE", "ENGINE", tt.targetSerial)
 			if err != nil {
 				t.Fatalf("Failed to get effective lines: %v", err)
 			}
@@ -269,7 +271,7 @@ func TestBOMRepository_GetEffectiveAlternates(t *testing.T) {
 	repo.AddBOMLine(*differentFind)
 
 	// Test: Early serial should get V1 turbopump
-	earlyAlternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "AS503")
+	earlyAlternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "F1_ENGINE", "AS503", time.Time{})
 	if err != nil {
 		t.Fatalf("Failed to get early effective alternates: %v", err)
 	}
@@ -281,7 +283,7 @@ func TestBOMRepository_GetEffectiveAlternates(t *testing.T) {
 	}
 
 	// Test: Late serial should get V2 turbopump
-	lateAlternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "AS507")
+	lateAlternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "F1_ENGINE", "AS507", time.Time{})
 	if err != nil {
 		t.Fatalf("Failed to get late effective alternates: %v", err)
 	}
@@ -293,7 +295,7 @@ func TestBOMRepository_GetEffectiveAlternates(t *testing.T) {
 	}
 
 	// Test: Wrong FindNumber should return empty
-	wrongFind, err := repo.GetEffectiveAlternates("F1_ENGINE", 999, "AS503")
+	wrongFind, err := repo.GetEffectiveAlternates("F1_ENGINE", 999, "F1_ENGINE", "AS503", time.Time{})
 	if err != nil {
 		t.Fatalf("Failed to get alternates for wrong FindNumber: %v", err)
 	}
@@ -303,6 +305,66 @@ func TestBOMRepository_GetEffectiveAlternates(t *testing.T) {
 	}
 }
 
+func TestBOMRepository_GetEffectiveAlternates_DateEffectivity(t *testing.T) {
+	repo := NewBOMRepository(10)
+
+	cutIn := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	allSerials := entities.SerialEffectivity{FromSerial: "AS501", ToSerial: ""}
+
+	v1, err := entities.NewBOMLine("F1_ENGINE", "F1_TURBOPUMP_V1", 1, 300, allSerials, 0)
+	if err != nil {
+		t.Fatalf("Failed to create V1 BOM line: %v", err)
+	}
+	v1.DateEffectivity = entities.DateEffectivity{ToDate: cutIn.AddDate(0, 0, -1)}
+
+	v2, err := entities.NewBOMLine("F1_ENGINE", "F1_TURBOPUMP_V2", 1, 300, allSerials, 0)
+	if err != nil {
+		t.Fatalf("Failed to create V2 BOM line: %v", err)
+	}
+	v2.DateEffectivity = entities.DateEffectivity{FromDate: cutIn}
+
+	repo.AddBOMLine(*v1)
+	repo.AddBOMLine(*v2)
+
+	tests := []struct {
+		name     string
+		needDate time.Time
+		expected []entities.PartNumber
+	}{
+		{"before cut-in", cutIn.AddDate(0, 0, -10), []entities.PartNumber{"F1_TURBOPUMP_V1"}},
+		{"on cut-in", cutIn, []entities.PartNumber{"F1_TURBOPUMP_V2"}},
+		{"after cut-in", cutIn.AddDate(1, 0, 0), []entities.PartNumber{"F1_TURBOPUMP_V2"}},
+		{"no date", time.Time{}, []entities.PartNumber{"F1_TURBOPUMP_V1", "F1_TURBOPUMP_V2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "F1_ENGINE", "AS505", tt.needDate)
+			if err != nil {
+				t.Fatalf("Failed to get effective alternates: %v", err)
+			}
+
+			if len(alternates) != len(tt.expected) {
+				t.Fatalf("Expected %d alternates, got %d", len(tt.expected), len(alternates))
+			}
+			for i, line := range alternates {
+				if line.ChildPN != tt.expected[i] {
+					t.Errorf("Expected %s at position %d, got %s", tt.expected[i], i, line.ChildPN)
+				}
+			}
+		})
+	}
+
+	// Serial effectivity still applies alongside date effectivity
+	alternates, err := repo.GetEffectiveAlternates("F1_ENGINE", 300, "F1_ENGINE", "AS500", cutIn)
+	if err != nil {
+		t.Fatalf("Failed to get effective alternates: %v", err)
+	}
+	if len(alternates) != 0 {
+		t.Errorf("Expected no alternates for serial outside effectivity, got %d", len(alternates))
+	}
+}
+
 func TestBOMRepository_GetAlternateGroups_NonExistentPart(t *testing.T) {
 	repo := NewBOMRepository(20)
 
@@ -316,3 +378,50 @@ func TestBOMRepository_GetAlternateGroups_NonExistentPart(t *testing.T) {
 		t.Errorf("Expected empty groups for non-existent part, got %d", len(groups))
 	}
 }
+
+func TestBOMRepository_GetWhereUsed(t *testing.T) {
+	repo := NewBOMRepository(10)
+
+	lines := []*entities.BOMLine{
+		{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 2, FindNumber: 100},
+		{ParentPN: "TURBOPUMP", ChildPN: "VALVE", QtyPer: 1, FindNumber: 200},
+		{ParentPN: "ENGINE", ChildPN: "TURBOPUMP", QtyPer: 1, FindNumber: 300},
+	}
+	if err := repo.LoadBOMLines(lines); err != nil {
+		t.Fatalf("Failed to load BOM lines: %v", err)
+	}
+
+	usages, err := repo.GetWhereUsed("VALVE")
+	if err != nil {
+		t.Fatalf("Failed to get where-used: %v", err)
+	}
+	if len(usages) != 2 || usages[0].ParentPN != "ENGINE" || usages[1].ParentPN != "TURBOPUMP" {
+		t.Errorf("Expected VALVE to be used by ENGINE and TURBOPUMP, got %v", usages)
+	}
+
+	usages, err = repo.GetWhereUsed("ENGINE")
+	if err != nil {
+		t.Fatalf("Failed to get where-used: %v", err)
+	}
+	if len(usages) != 0 {
+		t.Errorf("Expected no where-used lines for a top-level part, got %v", usages)
+	}
+}
+
+func TestBOMRepository_LoadBOMLines_Cycle(t *testing.T) {
+	repo := NewBOMRepository(2)
+
+	lines := []*entities.BOMLine{
+		{ParentPN: "ENGINE", ChildPN: "TURBOPUMP", QtyPer: 1, FindNumber: 100},
+		{ParentPN: "TURBOPUMP", ChildPN: "ENGINE", QtyPer: 1, FindNumber: 100},
+	}
+
+	err := repo.LoadBOMLines(lines)
+	var cyclic *entities.CyclicBOMError
+	if !errors.As(err, &cyclic) {
+		t.Fatalf("Expected a CyclicBOMError, got %v", err)
+	}
+	if len(cyclic.Parts()) != 2 {
+		t.Errorf("Expected a two-part cycle, got %v", cyclic.Cycle)
+	}
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+)
+
+// ECORepository provides in-memory ECO storage
+type ECORepository struct {
+	ecos    []*entities.ECO
+	ecoByID map[string]*entities.ECO
+}
+
+// NewECORepository creates a new in-memory ECO repository
+func NewECORepository() *ECORepository {
+	return &ECORepository{
+		ecoByID: make(map[string]*entities.ECO),
+	}
+}
+
+// Verify interface compliance
+var _ repositories.ECORepository = (*ECORepository)(nil)
+
+// GetECO retrieves an ECO by ID
+func (r *ECORepository) GetECO(id string) (*entities.ECO, error) {
+	eco, exists := r.ecoByID[id]
+	if !exists {
+		return nil, &entities.NotFoundError{Kind: "ECO", ID: id}
+	}
+	return eco, nil
+}
+
+// GetAllECOs returns all ECOs in load order
+func (r *ECORepository) GetAllECOs() ([]*entities.ECO, error) {
+	ecos := make([]*entities.ECO, len(r.ecos))
+	copy(ecos, r.ecos)
+	return ecos, nil
+}
+
+// LoadECOs loads ECOs into the repository
+func (r *ECORepository) LoadECOs(ecos []*entities.ECO) error {
+	for _, eco := range ecos {
+		if _, exists := r.ecoByID[eco.ID]; exists {
+			return fmt.Errorf("duplicate ECO: %s", eco.ID)
+		}
+		r.ecos = append(r.ecos, eco)
+		r.ecoByID[eco.ID] = eco
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package memory
 
 import (
-	"fmt"
 	"sort"
 
 	"github.com/vsinha/mrp/pkg/domain/entities"
@@ -204,7 +203,7 @@ func (r *InventoryRepository) GetInventoryByLot(
 			return lot, nil
 		}
 	}
-	return nil, fmt.Errorf("lot not found: %s for part %s", lotNumber, partNumber)
+	return nil, &entities.NotFoundError{Kind: "lot", ID: lotNumber, PartNumber: partNumber}
 }
 
 // GetInventoryBySerial returns inventory for a specific serial number
@@ -218,7 +217,7 @@ func (r *InventoryRepository) GetInventoryBySerial(
 			return inv, nil
 		}
 	}
-	return nil, fmt.Errorf("serial not found: %s for part %s", serialNumber, partNumber)
+	return nil, &entities.NotFoundError{Kind: "serial", ID: serialNumber, PartNumber: partNumber}
 }
 
 // SaveInventoryLot saves an inventory lot to the repository
@@ -271,9 +270,17 @@ func (r *InventoryRepository) UpdateInventoryStatus(
 	for i := range r.lotInventory {
 		lot := &r.lotInventory[i]
 		if lot.PartNumber == partNumber && lot.LotNumber == lotNumber && lot.Location == location {
+			if status == entities.Allocated && lot.Status != entities.Available {
+				return &entities.AllocationConflictError{
+					PartNumber: partNumber,
+					LotNumber:  lotNumber,
+					Location:   location,
+					Status:     lot.Status,
+				}
+			}
 			lot.Status = status
 			return nil
 		}
 	}
-	return fmt.Errorf("lot not found: %s for part %s at %s", lotNumber, partNumber, location)
+	return &entities.NotFoundError{Kind: "lot", ID: lotNumber, PartNumber: partNumber, Location: location}
 }
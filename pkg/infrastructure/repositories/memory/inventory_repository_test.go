@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -307,3 +308,54 @@ func TestInventoryRepository_UpdateInventoryStatus(t *testing.T) {
 		t.Errorf("Expected available quantity 0 after allocating, got %d", availableQty)
 	}
 }
+
+func TestInventoryRepository_UpdateInventoryStatus_Conflict(t *testing.T) {
+	repo := NewInventoryRepository()
+
+	for _, lot := range []*entities.InventoryLot{
+		{PartNumber: "TEST_PART", LotNumber: "LOT001", Location: "WAREHOUSE_A", Quantity: 10, Status: entities.Available},
+		{PartNumber: "TEST_PART", LotNumber: "LOT002", Location: "WAREHOUSE_A", Quantity: 10, Status: entities.Quarantine},
+	} {
+		if err := repo.SaveInventoryLot(lot); err != nil {
+			t.Fatalf("Failed to save inventory lot: %v", err)
+		}
+	}
+
+	if err := repo.UpdateInventoryStatus("TEST_PART", "LOT001", "WAREHOUSE_A", entities.Allocated); err != nil {
+		t.Fatalf("Failed to allocate an available lot: %v", err)
+	}
+
+	// Allocating an allocated or quarantined lot again is a conflict
+	var conflict *entities.AllocationConflictError
+	err := repo.UpdateInventoryStatus("TEST_PART", "LOT001", "WAREHOUSE_A", entities.Allocated)
+	if !errors.As(err, &conflict) || conflict.Status != entities.Allocated {
+		t.Errorf("Expected an AllocationConflictError for an allocated lot, got %v", err)
+	}
+	err = repo.UpdateInventoryStatus("TEST_PART", "LOT002", "WAREHOUSE_A", entities.Allocated)
+	if !errors.As(err, &conflict) || conflict.Status != entities.Quarantine {
+		t.Errorf("Expected an AllocationConflictError for a quarantined lot, got %v", err)
+	}
+
+	// Releasing quarantine is not an allocation
+	if err := repo.UpdateInventoryStatus("TEST_PART", "LOT002", "WAREHOUSE_A", entities.Available); err != nil {
+		t.Errorf("Expected quarantine release to succeed, got %v", err)
+	}
+}
+
+func TestInventoryRepository_NotFound(t *testing.T) {
+	repo := NewInventoryRepository()
+
+	var notFound *entities.NotFoundError
+	_, err := repo.GetInventoryByLot("TEST_PART", "LOT404")
+	if !errors.As(err, &notFound) || notFound.Kind != "lot" || notFound.ID != "LOT404" {
+		t.Errorf("Expected a NotFoundError for lot LOT404, got %v", err)
+	}
+	_, err = repo.GetInventoryBySerial("TEST_PART", "SN404")
+	if !errors.As(err, &notFound) || notFound.Kind != "serial" || notFound.ID != "SN404" {
+		t.Errorf("Expected a NotFoundError for serial SN404, got %v", err)
+	}
+	err = repo.UpdateInventoryStatus("TEST_PART", "LOT404", "WAREHOUSE_A", entities.Allocated)
+	if !errors.As(err, &notFound) || notFound.Location != "WAREHOUSE_A" {
+		t.Errorf("Expected a NotFoundError for lot LOT404 at WAREHOUSE_A, got %v", err)
+	}
+}
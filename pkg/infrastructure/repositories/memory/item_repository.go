@@ -66,7 +66,7 @@ func (r *ItemRepository) AddItemWithValidation(item entities.Item) error {
 func (r *ItemRepository) GetItem(partNumber entities.PartNumber) (*entities.Item, error) {
 	index, exists := r.itemsMap[partNumber]
 	if !exists {
-		return nil, fmt.Errorf("item not found: %s", partNumber)
+		return nil, &entities.ItemNotFoundError{PartNumber: partNumber}
 	}
 	return &r.items[index], nil
 }
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -206,4 +207,9 @@ func TestItemRepository_GetItem_NotFound(t *testing.T) {
 	if !strings.Contains(err.Error(), "item not found") {
 		t.Errorf("Expected error message to contain 'item not found', got: %v", err)
 	}
+
+	var notFound *entities.ItemNotFoundError
+	if !errors.As(err, &notFound) || notFound.PartNumber != "NONEXISTENT" {
+		t.Errorf("Expected an ItemNotFoundError for NONEXISTENT, got: %v", err)
+	}
 }
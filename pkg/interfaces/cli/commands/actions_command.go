@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// ActionsConfig holds configuration for the actions command
+type ActionsConfig struct {
+	Config // Scenario inputs and output settings
+}
+
+// ActionsCommand plans a scenario against its scheduled receipts and firm planned orders, and
+// reports what to expedite, firm, release, increase, cancel or push out
+type ActionsCommand struct {
+	config ActionsConfig
+}
+
+// NewActionsCommand creates a new actions command with the given configuration
+func NewActionsCommand(config ActionsConfig) *ActionsCommand {
+	return &ActionsCommand{
+		config: config,
+	}
+}
+
+// Execute runs the actions command
+func (c *ActionsCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	mrpService := mrp.NewMRPService()
+	mrpService.SetScheduledReceipts(s.receipts)
+	mrpService.SetFirmPlannedOrders(s.firmOrders)
+	result, err := mrpService.ExplodeDemand(
+		ctx,
+		s.demands,
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.demandRepo,
+	)
+	if err != nil {
+		return fmt.Errorf("error running MRP explosion: %w", err)
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateActionMessages(dto.NewActionMessageReport(result.ActionMessages), outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *ActionsCommand) showHelp() {
+	fmt.Printf(`MRP Actions - Action messages for scheduled receipts and planned orders
+
+USAGE:
+    mrp actions -scenario <directory> [-receipts <file>] [-format <fmt>] [-output <dir>]
+
+Nets the open orders in scheduled_receipts.csv and the firm orders in firm_planned_orders.csv
+against requirements before planning new orders, then lists what to do about them, most urgent
+first (receipts include firm planned orders, which MRP never changes itself):
+    Expedite    A receipt is due after the requirement it covers
+    Firm        A time fence kept MRP from planning an order; firm one yourself
+    Release     A planned order must start today or earlier
+    Increase    A receipt falls short; raise it instead of releasing new orders
+    Cancel      A receipt, or part of one, covers no requirement
+    Push Out    A receipt is due before the requirement it covers
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -receipts <file>    Path to scheduled receipts CSV (default: scheduled_receipts.csv in the scenario, if present)
+    -firm-orders <file> Path to firm planned orders CSV (default: firm_planned_orders.csv in the scenario, if present)
+    -format <fmt>       Output format: text, csv, json (default: text)
+    -output <dir>       Output directory for results (required for csv)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+scheduled_receipts.csv format (order_type is make, buy or transfer):
+    order_number,part_number,quantity,due_date,location,order_type
+    PO-1001,F1_ENGINE,2,1968-09-01,Michoud,buy
+
+EXAMPLES:
+    # What to expedite, push out, cancel, increase or release
+    mrp actions -scenario examples/apollo_engine_refurb
+
+    # Save the action messages as CSV
+    mrp actions -scenario examples/apollo_engine_refurb -format csv -output ./results
+`)
+}
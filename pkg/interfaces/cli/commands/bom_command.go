@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/bomreport"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// BOMConfig holds configuration for the BOM report command
+type BOMConfig struct {
+	Config            // Scenario inputs and output settings
+	PartNumber string // Part to explode
+	Serial     string // Serial to resolve effectivity for
+	Quantity   int    // Units of the part to explode
+	Date       string // Need date (YYYY-MM-DD) for date effectivity (optional)
+}
+
+// BOMCommand prints the effectivity-resolved, indented BOM of a part without running MRP
+type BOMCommand struct {
+	config BOMConfig
+}
+
+// NewBOMCommand creates a new BOM report command with the given configuration
+func NewBOMCommand(config BOMConfig) *BOMCommand {
+	return &BOMCommand{
+		config: config,
+	}
+}
+
+// Execute runs the BOM report command
+func (c *BOMCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if c.config.PartNumber == "" || c.config.Serial == "" {
+		return fmt.Errorf("validation error: must specify -part and -serial")
+	}
+	if c.config.Quantity <= 0 {
+		return fmt.Errorf("validation error: quantity must be positive, got %d", c.config.Quantity)
+	}
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	var needDate time.Time
+	if c.config.Date != "" {
+		var err error
+		needDate, err = time.Parse("2006-01-02", c.config.Date)
+		if err != nil {
+			return fmt.Errorf("validation error: invalid date %s: %w", c.config.Date, err)
+		}
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	partNumber := entities.PartNumber(c.config.PartNumber)
+	if err := s.serialComp.ValidateSerial(partNumber, c.config.Serial); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	reportService := bomreport.NewBOMReportService(s.planningBOM, s.itemRepo, s.inventoryRepo)
+	report, err := reportService.ExplodeBOM(
+		ctx,
+		partNumber,
+		c.config.Serial,
+		entities.Quantity(c.config.Quantity),
+		needDate,
+	)
+	if err != nil {
+		return fmt.Errorf("error exploding BOM: %w", err)
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateBOMReport(report, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *BOMCommand) showHelp() {
+	fmt.Printf(`MRP BOM - Print the indented, effectivity-resolved BOM of a part
+
+USAGE:
+    mrp bom -scenario <directory> -part <part_number> -serial <serial>
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -part <pn>          Part number to explode (required)
+    -serial <serial>    Serial to resolve effectivity for (required)
+    -qty <n>            Units to explode, for extended quantities (default: 1)
+    -date <YYYY-MM-DD>  Need date for date effectivity (default: ignore date effectivity)
+    -format <fmt>       Output format: text, csv, json (default: text)
+    -output <dir>       Output directory for CSV/JSON results (default: stdout)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+Alternates are chosen as MRP would choose them (lowest priority number first); the other
+effective alternates at each find number are listed alongside.
+
+EXAMPLES:
+    # Indented BOM of one Saturn V
+    mrp bom -scenario examples/apollo_saturn_v -part SATURN_V -serial SA506
+
+    # As CSV, for 3 units
+    mrp bom -scenario examples/apollo_saturn_v -part SATURN_V -serial SA506 -qty 3 -format csv
+`)
+}
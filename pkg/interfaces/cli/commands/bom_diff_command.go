@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/bomreport"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// BOMDiffConfig holds configuration for the BOM comparison command
+type BOMDiffConfig struct {
+	Config            // Scenario inputs and output settings
+	PartNumber string // Part whose BOM to compare
+	FromSerial string // Baseline serial
+	ToSerial   string // Serial to compare against the baseline
+	Date       string // Need date (YYYY-MM-DD) for date effectivity (optional)
+}
+
+// BOMDiffCommand reports how the effective BOM of a part differs between two serials
+type BOMDiffCommand struct {
+	config BOMDiffConfig
+}
+
+// NewBOMDiffCommand creates a new BOM comparison command with the given configuration
+func NewBOMDiffCommand(config BOMDiffConfig) *BOMDiffCommand {
+	return &BOMDiffCommand{
+		config: config,
+	}
+}
+
+// Execute runs the BOM comparison command
+func (c *BOMDiffCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if c.config.PartNumber == "" || c.config.FromSerial == "" || c.config.ToSerial == "" {
+		return fmt.Errorf("validation error: must specify -part, -from and -to")
+	}
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	var needDate time.Time
+	if c.config.Date != "" {
+		var err error
+		needDate, err = time.Parse("2006-01-02", c.config.Date)
+		if err != nil {
+			return fmt.Errorf("validation error: invalid date %s: %w", c.config.Date, err)
+		}
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	comparisonService := bomreport.NewBOMComparisonService(
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.serialComp,
+	)
+	comparison, err := comparisonService.CompareSerials(
+		ctx,
+		entities.PartNumber(c.config.PartNumber),
+		c.config.FromSerial,
+		c.config.ToSerial,
+		needDate,
+	)
+	if err != nil {
+		return fmt.Errorf("error comparing BOMs: %w", err)
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateBOMComparison(comparison, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *BOMDiffCommand) showHelp() {
+	fmt.Printf(`MRP BOM Diff - Compare the effective BOMs of two serials
+
+USAGE:
+    mrp bom-diff -scenario <directory> -part <part_number> -from <serial> -to <serial>
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -part <pn>          Part number whose BOM to compare (required)
+    -from <serial>      Baseline serial (required)
+    -to <serial>        Serial to compare against the baseline (required)
+    -date <YYYY-MM-DD>  Need date for date effectivity (default: ignore date effectivity)
+    -format <fmt>       Output format: text, json (default: text)
+    -output <dir>       Output directory for JSON results (optional)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+Each find number is compared position by position and reported as Added, Removed,
+Substituted (a different component) or QuantityChanged. Positions below an added, removed
+or substituted component are not compared further.
+
+EXAMPLES:
+    # What's different between SA506 and SA508?
+    mrp bom-diff -scenario examples/apollo_saturn_v -part SATURN_V -from SA506 -to SA508
+`)
+}
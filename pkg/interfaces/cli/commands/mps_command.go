@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/mps"
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// MPSConfig holds configuration for the mps command
+type MPSConfig struct {
+	Config            // Scenario inputs and output settings
+	PeriodDays int    // Length of each MPS period in days
+	Periods    int    // Number of MPS periods (0 to reach the latest forecast or customer order)
+	AsOf       string // Date (YYYY-MM-DD) the first period starts (defaults to today)
+	MPSOnly    bool   // Report the master schedule without exploding it through MRP
+}
+
+// MPSCommand master schedules the end items with forecasts or customer orders, then explodes
+// the master schedule through MRP
+type MPSCommand struct {
+	config MPSConfig
+}
+
+// NewMPSCommand creates a new mps command with the given configuration
+func NewMPSCommand(config MPSConfig) *MPSCommand {
+	return &MPSCommand{
+		config: config,
+	}
+}
+
+// Execute runs the mps command
+func (c *MPSCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if c.config.PeriodDays < 1 {
+		return fmt.Errorf("validation error: -period-days must be positive, got %d", c.config.PeriodDays)
+	}
+	if c.config.Periods < 0 {
+		return fmt.Errorf("validation error: -periods cannot be negative, got %d", c.config.Periods)
+	}
+	asOf := time.Now()
+	if c.config.AsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", c.config.AsOf)
+		if err != nil {
+			return fmt.Errorf("validation error: invalid as-of date %s: %w", c.config.AsOf, err)
+		}
+	}
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	// The demands file holds the customer orders the forecasts are consumed by
+	mpsService := mps.NewMPSService(s.itemRepo, s.inventoryRepo)
+	schedule, err := mpsService.BuildSchedule(
+		ctx,
+		s.forecasts,
+		s.demands,
+		s.receipts,
+		s.firmOrders,
+		mps.Config{
+			Start:      asOf,
+			PeriodDays: c.config.PeriodDays,
+			Periods:    c.config.Periods,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error building master schedule: %w", err)
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+
+	var result *dto.MRPResult
+	if !c.config.MPSOnly {
+		// The end item stock the MPS counted on is not there for MRP to net again
+		if err := mpsService.ReserveStock(schedule); err != nil {
+			return fmt.Errorf("error reserving end item stock: %w", err)
+		}
+
+		// Receipts of master scheduled items are already netted by the MPS; firm planned
+		// orders are netted again, against the MPS quantities they make up
+		var receipts []entities.ScheduledReceipt
+		for _, receipt := range s.receipts {
+			if !schedule.Includes(receipt.PartNumber, receipt.Location) {
+				receipts = append(receipts, receipt)
+			}
+		}
+
+		mrpService := mrp.NewMRPService()
+		mrpService.SetScheduledReceipts(receipts)
+		mrpService.SetFirmPlannedOrders(s.firmOrders)
+		mrpService.SetRunDate(asOf) // Plan from the date the MPS periods start
+
+		startTime := time.Now()
+		result, err = mrpService.ExplodeDemand(
+			ctx,
+			schedule.Demands(),
+			s.planningBOM,
+			s.itemRepo,
+			s.inventoryRepo,
+			s.demandRepo,
+		)
+		if err != nil {
+			return fmt.Errorf("error running MRP explosion: %w", err)
+		}
+		outputConfig.ExplosionTime = time.Since(startTime)
+	}
+
+	if err := output.GenerateMasterSchedule(dto.NewMasterScheduleReport(schedule), result, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *MPSCommand) showHelp() {
+	fmt.Printf(`MRP MPS - Master production schedule of the end items, exploded through MRP
+
+USAGE:
+    mrp mps -scenario <directory> [-forecasts <file>] [-period-days <n>] [-periods <n>] [-as-of <date>]
+
+Buckets the forecasts in forecasts.csv and the customer orders in demands.csv of each end item
+into periods, then plans MPS quantities to keep the projected available balance at safety
+stock. Each period falls in one of three zones, set by the item's time fences:
+    frozen      Inside the demand time fence: only customer orders count, the forecast is ignored
+    slushy      Inside the planning time fence: demand is the larger of forecast and orders,
+                but only firm planned orders are scheduled
+    liquid      Beyond both fences: MPS quantities are planned, lot sized, as needed
+
+Available to promise (ATP) is the supply arriving in a period that customer orders have not
+claimed before the next supply arrives. MRP then explodes the firm and planned MPS quantities
+into the components they need, against the inventory the MPS left.
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands (customer orders) CSV file
+    -forecasts <file>   Path to forecasts CSV (default: forecasts.csv in the scenario, if present)
+    -receipts <file>    Path to scheduled receipts CSV (default: scheduled_receipts.csv in the scenario, if present)
+    -firm-orders <file> Path to firm planned orders CSV (default: firm_planned_orders.csv in the scenario, if present)
+    -period-days <n>    Length of each period in days (default: 7)
+    -periods <n>        Number of periods (default: enough to reach the latest forecast or order)
+    -as-of <date>       Date (YYYY-MM-DD) the first period starts; earlier orders are past due (default: today)
+    -mps-only           Report the master schedule without exploding it through MRP
+    -format <fmt>       Output format: text, csv, json (default: text)
+    -output <dir>       Output directory for results (required for csv)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+forecasts.csv format (period_start is the first day of the forecast period):
+    part_number,quantity,period_start,location,target_serial
+    F1_ENGINE,1,1969-07-01,MICHOUD,SA510
+
+EXAMPLES:
+    # Master schedule monthly and explode the MPS
+    mrp mps -scenario examples/apollo_engine_refurb -period-days 30 -as-of 1969-03-03
+
+    # The master schedule alone, as CSV
+    mrp mps -scenario examples/apollo_engine_refurb -mps-only -format csv -output ./results
+`)
+}
@@ -2,19 +2,16 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/vsinha/mrp/pkg/application/services/criticalpath"
+	"github.com/vsinha/mrp/pkg/application/services/eco"
 	"github.com/vsinha/mrp/pkg/application/services/mrp"
 	"github.com/vsinha/mrp/pkg/application/services/orchestration"
 	"github.com/vsinha/mrp/pkg/domain/entities"
-	"github.com/vsinha/mrp/pkg/domain/services/bom_validator"
-	"github.com/vsinha/mrp/pkg/infrastructure/repositories/csv"
-	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
 	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
 )
 
@@ -31,7 +28,27 @@ type Config struct {
 	Verbose       bool
 	CriticalPath  bool
 	TopPaths      int
+	PathMode      string // How critical paths time parts: "lead-time" (or empty) or "schedule"
+	NearCritical  int    // Parts with less float than this many days are reported as near-critical
+	AsOf          string // Date (YYYY-MM-DD) builds are projected to start from (defaults to today)
+	FailOnLate    bool   // Fail the run when any demand is projected late or unfulfilled
 	Help          bool
+	Timeout       time.Duration // Cancel the run after this long (0 = no limit)
+
+	// Engineering change orders
+	ECOsFile           string // Path to ECO CSV file (defaults to ecos.csv in the scenario, if present)
+	IncludePendingECOs bool   // Also apply draft ECOs, not just released ones
+	ECOImpact          string // ECO ID to report planned order changes for instead of running the plan
+
+	// Serial number grammar
+	SerialSchemesFile string // Path to serial schemes CSV (defaults to serial_schemes.csv in the scenario, if present)
+
+	// Open orders and firm planned orders
+	ScheduledReceiptsFile string // Path to scheduled receipts CSV (defaults to scheduled_receipts.csv in the scenario, if present)
+	FirmPlannedOrdersFile string // Path to firm planned orders CSV (defaults to firm_planned_orders.csv in the scenario, if present)
+
+	// Forecast independent demand, master scheduled by mrp mps
+	ForecastsFile string // Path to forecasts CSV (defaults to forecasts.csv in the scenario, if present)
 }
 
 // MRPCommand handles the main MRP execution logic
@@ -54,312 +71,108 @@ func (c *MRPCommand) Execute(ctx context.Context) error {
 	}
 
 	// Validate inputs
-	if err := c.validateInputs(); err != nil {
+	if err := c.config.validateInputs(); err != nil {
 		return fmt.Errorf("validation error: %w", err)
 	}
-
-	// Determine input files
-	files, err := c.resolveInputFiles()
-	if err != nil {
-		return fmt.Errorf("failed to resolve input files: %w", err)
-	}
-
-	if c.config.Verbose {
-		c.printHeader(files)
-	}
-
-	// Load data from CSV files
-	if c.config.Verbose {
-		fmt.Println("📂 Loading data from CSV files...")
-	}
-
-	csvLoader := csv.NewLoader()
-	
-	// Track individual loading times
-	var loadStart time.Time
-
-	// Load Items
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Printf("  🔄 Loading items from %s...", files["Items"])
-	}
-	items, err := csvLoader.LoadItems(files["Items"])
-	if err != nil {
-		return fmt.Errorf("error loading items: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ %d items loaded in %v\n", len(items), time.Since(loadStart))
-	}
-
-	// Load BOM
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Printf("  🔄 Loading BOM from %s...", files["BOM"])
-	}
-	bomLines, err := csvLoader.LoadBOM(files["BOM"])
-	if err != nil {
-		return fmt.Errorf("error loading BOM: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ %d BOM lines loaded in %v\n", len(bomLines), time.Since(loadStart))
-	}
-
-	// Load Inventory  
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Printf("  🔄 Loading inventory from %s...", files["Inventory"])
-	}
-	lotInventory, serialInventory, err := csvLoader.LoadInventory(files["Inventory"])
-	if err != nil {
-		return fmt.Errorf("error loading inventory: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ %d lot + %d serial inventory records loaded in %v\n", 
-			len(lotInventory), len(serialInventory), time.Since(loadStart))
+	switch c.config.PathMode {
+	case "", "lead-time", "schedule":
+	default:
+		return fmt.Errorf("validation error: unknown critical path mode %q (want lead-time or schedule)",
+			c.config.PathMode)
 	}
 
-	// Load Demands
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Printf("  🔄 Loading demands from %s...", files["Demands"])
-	}
-	demands, err := csvLoader.LoadDemands(files["Demands"])
-	if err != nil {
-		return fmt.Errorf("error loading demands: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ %d demands loaded in %v\n", len(demands), time.Since(loadStart))
-		fmt.Println()
-	}
-
-	// Create repositories
-	if c.config.Verbose {
-		fmt.Println("🏗️  Creating in-memory repositories...")
-		loadStart = time.Now()
-		fmt.Print("  🔄 Setting up BOM repository...")
-	}
-	bomRepo := memory.NewBOMRepository(len(bomLines))
-	err = bomRepo.LoadBOMLines(bomLines)
-	if err != nil {
-		return fmt.Errorf("failed to load BOM lines into repository: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	asOf := time.Now()
+	if c.config.AsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", c.config.AsOf)
+		if err != nil {
+			return fmt.Errorf("validation error: invalid as-of date %s: %w", c.config.AsOf, err)
+		}
 	}
 
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Print("  🔄 Setting up Item repository...")
-	}
-	itemRepo := memory.NewItemRepository(len(items))
-	err = itemRepo.LoadItems(items)
+	// Determine input files
+	files, err := c.config.resolveInputFiles()
 	if err != nil {
-		return fmt.Errorf("failed to load items into repository: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
-	}
-
-	// Validate BOM-Item consistency
-	if c.config.Verbose {
-		fmt.Println()
-		loadStart = time.Now()
-		fmt.Print("🔍 Validating BOM-Item consistency...")
-	}
-
-	itemSlice := make([]entities.Item, len(items))
-	for i, item := range items {
-		itemSlice[i] = *item
-	}
-
-	bomSlice := make([]entities.BOMLine, len(bomLines))
-	for i, line := range bomLines {
-		bomSlice[i] = *line
-	}
-
-	consistencyValidation := bom_validator.ValidateBOMItemConsistency(bomSlice, itemSlice)
-	if len(consistencyValidation.Errors) > 0 {
-		return fmt.Errorf("BOM-Item consistency validation failed: %s",
-			strings.Join(consistencyValidation.Errors, "; "))
+		return fmt.Errorf("failed to resolve input files: %w", err)
 	}
 
 	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v", time.Since(loadStart))
-		if len(consistencyValidation.OrphanedParts) > 0 {
-			fmt.Printf(" (Found %d orphaned parts)", len(consistencyValidation.OrphanedParts))
-		}
-		fmt.Println()
+		c.printHeader(files)
 	}
 
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Print("  🔄 Setting up Inventory repository...")
-	}
-	inventoryRepo := memory.NewInventoryRepository()
-	err = inventoryRepo.LoadInventoryLots(lotInventory)
-	if err != nil {
-		return fmt.Errorf("failed to load lot inventory into repository: %w", err)
-	}
-	err = inventoryRepo.LoadSerializedInventory(serialInventory)
+	s, err := loadScenario(c.config, files)
 	if err != nil {
-		return fmt.Errorf("failed to load serialized inventory into repository: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+		return err
 	}
 
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Print("  🔄 Setting up Demand repository...")
-	}
-	demandRepo := memory.NewDemandRepository()
-	err = demandRepo.LoadDemands(demands)
-	if err != nil {
-		return fmt.Errorf("failed to load demands into repository: %w", err)
-	}
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	if c.config.ECOImpact != "" {
+		return c.runECOImpact(ctx, s)
 	}
 
 	// Create services
-	if c.config.Verbose {
-		fmt.Println()
-		fmt.Println("🛠️  Initializing MRP services...")
-		loadStart = time.Now()
-		fmt.Print("  🔄 Creating MRP service...")
-	}
 	mrpService := mrp.NewMRPService()
+	mrpService.SetScheduledReceipts(s.receipts)
+	mrpService.SetFirmPlannedOrders(s.firmOrders)
 	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+		mrpService.SetProgressObserver(output.NewProgressBar(os.Stderr))
 	}
 
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Print("  🔄 Creating Critical Path service...")
-	}
-	criticalPathService := criticalpath.NewCriticalPathService(bomRepo, itemRepo, inventoryRepo, nil)
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
-	}
+	criticalPathService := criticalpath.NewCriticalPathService(
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.serialComp,
+	)
 
-	if c.config.Verbose {
-		loadStart = time.Now()
-		fmt.Print("  🔄 Creating Planning orchestrator...")
-	}
 	orchestrator := orchestration.NewPlanningOrchestrator(
 		mrpService,
 		criticalPathService,
-		bomRepo,
-		itemRepo,
-		inventoryRepo,
-		demandRepo,
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.demandRepo,
 	)
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
-		fmt.Println("⚡ MRP services initialized with clean architecture")
-		fmt.Println()
-	}
 
-	// Run MRP explosion
-	if c.config.Verbose {
-		fmt.Println("🚀 Starting MRP explosion process...")
-		fmt.Printf("  📊 Processing %d demand(s) across %d unique part(s)\n", len(demands), len(items))
-		fmt.Printf("  🔗 Using %d BOM relationships\n", len(bomLines))
-		fmt.Printf("  📦 Available inventory: %d lot + %d serial records\n", len(lotInventory), len(serialInventory))
-		fmt.Println()
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
 	}
 
 	startTime := time.Now()
-	if c.config.Verbose {
-		fmt.Print("  🔄 Exploding demand structure...")
-	}
 	result, err := mrpService.ExplodeDemand(
 		ctx,
-		demands,
-		bomRepo,
-		itemRepo,
-		inventoryRepo,
-		demandRepo,
+		s.demands,
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.demandRepo,
 	)
 	explosionTime := time.Since(startTime)
 
 	if err != nil {
-		return fmt.Errorf("error running MRP explosion: %w", err)
-	}
-
-	if c.config.Verbose {
-		fmt.Printf(" ✅ Done in %v\n", explosionTime)
-		fmt.Printf("📋 Generated %d planned orders\n", len(result.PlannedOrders))
-		fmt.Printf("📦 Created %d inventory allocations\n", len(result.Allocations))
-		if len(result.ShortageReport) > 0 {
-			fmt.Printf("⚠️  Found %d shortages\n", len(result.ShortageReport))
-		} else {
-			fmt.Printf("✅ No shortages detected\n")
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("MRP explosion did not finish within %v: %w", c.config.Timeout, err)
 		}
-		fmt.Println()
+		return fmt.Errorf("error running MRP explosion: %w", err)
 	}
 
-	// Perform critical path analysis if requested
+	// Perform critical path analysis if requested, combining the demands into bottleneck and
+	// shared component reports for the whole plan
 	var criticalPathResults []*entities.CriticalPathAnalysis
+	var program *entities.ProgramCriticalPath
 	if c.config.CriticalPath {
-		if c.config.Verbose {
-			fmt.Printf("🔍 Performing critical path analysis for %d demand(s)...\n", len(demands))
-			fmt.Printf("  📈 Analyzing top %d critical paths per demand\n", c.config.TopPaths)
-		}
-
-		criticalPathStartTime := time.Now()
-
-		for i, demand := range demands {
-			if c.config.Verbose {
-				fmt.Printf("  🔄 Analyzing critical path for %s (%d/%d)...", 
-					demand.PartNumber, i+1, len(demands))
-				loadStart = time.Now()
-			}
-
-			analysis, err := orchestrator.AnalyzeCriticalPathWithMRPResults(
-				ctx,
-				demand.PartNumber,
-				demand.TargetSerial,
-				demand.Location,
-				c.config.TopPaths,
-				result,
-			)
-			if err != nil {
-				if c.config.Verbose {
-					fmt.Printf(" ❌ Failed in %v\n", time.Since(loadStart))
-				}
-				fmt.Printf("Warning: Failed to analyze critical path for %s: %v\n",
-					demand.PartNumber, err)
-				continue
-			}
-			criticalPathResults = append(criticalPathResults, analysis)
-
-			if c.config.Verbose {
-				fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
-				fmt.Printf("    📊 %s\n", analysis.GetCriticalPathSummary())
-			}
+		mode := entities.LeadTimeMode
+		if c.config.PathMode == "schedule" {
+			mode = entities.ScheduleMode
 		}
-
-		criticalPathTime := time.Since(criticalPathStartTime)
-		if c.config.Verbose {
-			fmt.Printf("✅ Critical path analysis completed in %v\n", criticalPathTime)
-			fmt.Printf("📈 Generated %d critical path analyses\n\n", len(criticalPathResults))
-		}
-	}
-
-	// Generate output
-	if c.config.Verbose {
-		fmt.Printf("📄 Generating output in %s format...\n", c.config.Format)
-		if c.config.SVGOutput != "" {
-			if c.config.Format == "html" {
-				fmt.Printf("  🌐 Preparing interactive HTML visualization...\n")
-			}
-			fmt.Printf("  📊 Will also generate visualization at: %s\n", c.config.SVGOutput)
+		program, err = orchestrator.AnalyzeProgram(ctx, s.demands, c.config.TopPaths, mode, result, asOf)
+		if err != nil {
+			return fmt.Errorf("error running critical path analysis: %w", err)
 		}
-		if c.config.OutputDir != "" {
-			fmt.Printf("  📁 Output directory: %s\n", c.config.OutputDir)
+		for _, demand := range program.Demands {
+			criticalPathResults = append(criticalPathResults, demand.Analysis)
 		}
-		loadStart = time.Now()
 	}
 
 	outputConfig := output.Config{
@@ -368,7 +181,11 @@ func (c *MRPCommand) Execute(ctx context.Context) error {
 		SVGOutput:     c.config.SVGOutput,
 		Verbose:       c.config.Verbose,
 		ExplosionTime: explosionTime,
-		InputFiles:    files,
+		InputFiles:    s.files,
+
+		CriticalPaths:    criticalPathResults,
+		NearCriticalDays: c.config.NearCritical,
+		Program:          program,
 	}
 
 	err = output.Generate(result, outputConfig)
@@ -376,60 +193,60 @@ func (c *MRPCommand) Execute(ctx context.Context) error {
 		return fmt.Errorf("error generating output: %w", err)
 	}
 
-	if c.config.Verbose {
-		fmt.Printf("✅ Output generation completed in %v\n", time.Since(loadStart))
-	}
-
-	if c.config.Verbose {
-		fmt.Println("🏁 MRP analysis complete!")
+	if c.config.FailOnLate {
+		if late := len(result.LateDemands()); late > 0 {
+			return &entities.LateDemandError{Late: late, Total: len(result.DemandFulfillment)}
+		}
 	}
 
 	return nil
 }
 
-// validateInputs validates the command configuration
-func (c *MRPCommand) validateInputs() error {
-	if c.config.ScenarioDir == "" &&
-		(c.config.BOMFile == "" || c.config.ItemsFile == "" ||
-			c.config.InventoryFile == "" || c.config.DemandsFile == "") {
-		return fmt.Errorf("must specify either -scenario directory or individual CSV files")
+// runECOImpact plans with and without one ECO and reports the planned orders it changes
+func (c *MRPCommand) runECOImpact(ctx context.Context, s *scenario) error {
+	var target *entities.ECO
+	for _, candidate := range s.ecos {
+		if candidate.ID == c.config.ECOImpact {
+			target = candidate
+			break
+		}
+	}
+	if target == nil {
+		return &entities.NotFoundError{Kind: "ECO", ID: c.config.ECOImpact}
 	}
-	return nil
-}
 
-// resolveInputFiles determines the actual file paths to use
-func (c *MRPCommand) resolveInputFiles() (map[string]string, error) {
-	var bomPath, itemsPath, inventoryPath, demandsPath string
-
-	if c.config.ScenarioDir != "" {
-		// Use scenario directory
-		bomPath = filepath.Join(c.config.ScenarioDir, "bom.csv")
-		itemsPath = filepath.Join(c.config.ScenarioDir, "items.csv")
-		inventoryPath = filepath.Join(c.config.ScenarioDir, "inventory.csv")
-		demandsPath = filepath.Join(c.config.ScenarioDir, "demands.csv")
-	} else {
-		// Use individual files
-		bomPath = c.config.BOMFile
-		itemsPath = c.config.ItemsFile
-		inventoryPath = c.config.InventoryFile
-		demandsPath = c.config.DemandsFile
+	if c.config.Verbose {
+		fmt.Printf("🔍 Comparing plans with and without %s...\n\n", target.ID)
 	}
 
-	files := map[string]string{
-		"BOM":       bomPath,
-		"Items":     itemsPath,
-		"Inventory": inventoryPath,
-		"Demands":   demandsPath,
+	impactService := eco.NewImpactService(s.serialComp)
+	impactService.SetScheduledReceipts(s.receipts)
+	impactService.SetFirmPlannedOrders(s.firmOrders)
+	impact, err := impactService.AnalyzeImpact(
+		ctx,
+		target,
+		s.appliedECOs,
+		s.demands,
+		s.bomRepo,
+		s.itemRepo,
+		s.newInventoryRepository,
+		s.demandRepo,
+	)
+	if err != nil {
+		return fmt.Errorf("error analyzing ECO impact: %w", err)
 	}
 
-	// Validate files exist
-	for name, path := range files {
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			return nil, fmt.Errorf("%s file not found: %s", name, path)
-		}
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: s.files,
+	}
+	if err := output.GenerateECOImpact(impact, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
 	}
 
-	return files, nil
+	return nil
 }
 
 // printHeader prints the command header information
@@ -440,6 +257,18 @@ func (c *MRPCommand) printHeader(files map[string]string) {
 	fmt.Printf("  Items: %s\n", files["Items"])
 	fmt.Printf("  Inventory: %s\n", files["Inventory"])
 	fmt.Printf("  Demands: %s\n", files["Demands"])
+	if files["ECOs"] != "" {
+		fmt.Printf("  ECOs: %s\n", files["ECOs"])
+	}
+	if files["SerialSchemes"] != "" {
+		fmt.Printf("  Serial schemes: %s\n", files["SerialSchemes"])
+	}
+	if files["ScheduledReceipts"] != "" {
+		fmt.Printf("  Scheduled receipts: %s\n", files["ScheduledReceipts"])
+	}
+	if files["FirmPlannedOrders"] != "" {
+		fmt.Printf("  Firm planned orders: %s\n", files["FirmPlannedOrders"])
+	}
 	fmt.Printf("Output format: %s\n", c.config.Format)
 	if c.config.OutputDir != "" {
 		fmt.Printf("Output directory: %s\n", c.config.OutputDir)
@@ -462,11 +291,27 @@ OPTIONS:
     -inventory <file>   Path to inventory CSV file
     -demands <file>     Path to demands CSV file
     -output <dir>       Output directory for results (optional)
-    -format <fmt>       Output format: text, json, csv, html (default: text)
+    -format <fmt>       Output format: text, json, csv, html (default: text). HTML is written next
+                        to the -svg file, or to mrp_visualization.html in the output directory
     -svg <file>         Generate SVG Gantt chart to specified file
-    -verbose            Enable verbose output
+    -verbose            Enable verbose output, with a progress bar on stderr
+    -timeout <dur>      Cancel the MRP run after this long, e.g. 30s (optional)
+    -fail-on-late       Exit with status 8 when any demand is projected late or unfulfilled
     -critical-path      Perform critical path analysis on demands
     -top-paths <n>      Number of top critical paths to analyze (default: 3)
+    -path-mode <mode>   Time critical paths by item lead-time or by the planned order schedule,
+                        matching the Gantt chart (default: lead-time)
+    -near-critical <n>  Report parts with less than n days of float as near-critical (default: 5)
+    -as-of <date>       Date (YYYY-MM-DD) every build is projected to start from when ranking
+                        late demands and bottleneck parts (default: today)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also plan with draft ECOs (released ECOs are always applied)
+    -eco-impact <id>    Show which planned orders an ECO would change (text or json)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -receipts <file>    Path to scheduled receipts CSV (default: scheduled_receipts.csv in the scenario, if present)
+    -firm-orders <file> Path to firm planned orders CSV (default: firm_planned_orders.csv in the scenario, if present)
     -help               Show this help message
 
 SCENARIO DIRECTORY STRUCTURE:
@@ -479,11 +324,12 @@ SCENARIO DIRECTORY STRUCTURE:
 CSV FILE FORMATS:
 
 items.csv:
-    part_number,description,lead_time_days,lot_size_rule,min_order_qty,max_order_qty,safety_stock,unit_of_measure,make_buy_code
+    part_number,description,lead_time_days,lot_size_rule,min_order_qty,max_order_qty,safety_stock,unit_of_measure,make_buy_code[,phantom,lead_time_min,lead_time_max,lead_time_distribution,demand_time_fence,planning_time_fence]
     F1_ENGINE,F-1 Engine,120,LotForLot,1,10,2,EA,Make
+    AVIONICS_PACKAGE,Avionics Kit,1,LotForLot,1,10,0,EA,Make,true
 
 bom.csv:
-    parent_pn,child_pn,qty_per,find_number,from_serial,to_serial
+    parent_pn,child_pn,qty_per,find_number,from_serial,to_serial[,priority][,lead_time_offset_days][,from_date][,to_date]
     F1_ENGINE,F1_TURBOPUMP_V1,1,100,AS501,AS506
     F1_ENGINE,F1_TURBOPUMP_V2,1,100,AS507,
 
@@ -496,6 +342,23 @@ demands.csv:
     part_number,quantity,need_date,demand_source,location,target_serial
     F1_ENGINE,5,1969-07-04,APOLLO_11,KENNEDY,AS506
 
+serial_schemes.csv (optional; applies_to lists parts, families like F1_* or *, separated by ;):
+    name,prefix_pattern,suffix_pattern,applies_to
+    saturn,SA-?,[A-Z]?,SATURN_V;S_IC_*
+
+scheduled_receipts.csv (optional; open orders netted before planning, order_type is Make, Buy or Transfer):
+    order_number,part_number,quantity,due_date,location,order_type
+    PO-1001,BOLT_M12,500,1968-08-01,MICHOUD,Buy
+
+firm_planned_orders.csv (optional; orders fixed by the planner, never changed by MRP):
+    order_number,part_number,quantity,start_date,due_date,location,order_type
+    FPO-1001,F1_ENGINE,1,1968-03-01,1968-09-01,MICHOUD,Make
+
+ecos.csv (optional, one row per change; action is add, remove or effectivity):
+    eco_id,description,status,cut_in_serial,action,<bom.csv columns>
+    ECO-001,Turbopump V2,draft,AS507,remove,F1_ENGINE,F1_TURBOPUMP_V1,,100,,
+    ECO-001,Turbopump V2,draft,AS507,add,F1_ENGINE,F1_TURBOPUMP_V2,1,100,AS501,
+
 EXAMPLES:
     # Run aerospace scenario
     mrp -scenario examples/aerospace_basic -verbose
@@ -520,5 +383,8 @@ EXAMPLES:
 
     # Run with verbose output
     mrp -scenario examples/apollo_saturn_v_stack -verbose
+
+    # Show which planned orders a draft ECO would change
+    mrp -scenario examples/apollo_engine_refurb -eco-impact ECO-001
 `)
 }
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/application/services/criticalpath"
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// RiskConfig holds configuration for the risk command
+type RiskConfig struct {
+	Config            // Scenario inputs and output settings
+	Iterations int    // Lead time draws to simulate
+	Seed       int64  // Random seed, so runs can be repeated
+	AsOf       string // Date (YYYY-MM-DD) builds start from (defaults to today)
+}
+
+// RiskCommand simulates lead time variation to find the likely completion date of each demand
+type RiskCommand struct {
+	config RiskConfig
+}
+
+// NewRiskCommand creates a new risk command with the given configuration
+func NewRiskCommand(config RiskConfig) *RiskCommand {
+	return &RiskCommand{
+		config: config,
+	}
+}
+
+// Execute runs the risk command
+func (c *RiskCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if c.config.Iterations < 1 {
+		return fmt.Errorf("validation error: -iterations must be positive, got %d", c.config.Iterations)
+	}
+	asOf := time.Now()
+	if c.config.AsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", c.config.AsOf)
+		if err != nil {
+			return fmt.Errorf("validation error: invalid as-of date %s: %w", c.config.AsOf, err)
+		}
+	}
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	// Plan first, so that builds are shortened by the inventory MRP allocates to them
+	result, err := mrp.NewMRPService().ExplodeDemand(
+		ctx,
+		s.demands,
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.demandRepo,
+	)
+	if err != nil {
+		return fmt.Errorf("error running MRP explosion: %w", err)
+	}
+
+	if c.config.Verbose {
+		fmt.Printf("🎲 Simulating %d iterations for %d demand(s)...\n", c.config.Iterations, len(s.demands))
+	}
+	startTime := time.Now()
+
+	criticalPathService := criticalpath.NewCriticalPathService(
+		s.planningBOM,
+		s.itemRepo,
+		s.inventoryRepo,
+		s.serialComp,
+	)
+	risk, err := criticalPathService.AnalyzeScheduleRisk(
+		ctx,
+		s.demands,
+		result.Allocations,
+		c.config.Iterations,
+		c.config.Seed,
+		asOf,
+	)
+	if err != nil {
+		return fmt.Errorf("error simulating schedule risk: %w", err)
+	}
+
+	if c.config.Verbose {
+		fmt.Printf("  ✅ Simulation done in %v\n\n", time.Since(startTime))
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateScheduleRisk(dto.NewScheduleRiskReport(risk), outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *RiskCommand) showHelp() {
+	fmt.Printf(`MRP Risk - Monte Carlo schedule risk analysis of lead time variation
+
+USAGE:
+    mrp risk -scenario <directory> [-iterations <n>] [-seed <n>] [-as-of <date>]
+
+Each iteration draws a lead time for every item with a lead time range in items.csv
+(lead_time_min, lead_time_max and lead_time_distribution columns, around lead_time_days),
+reschedules every demand from the as-of date, and notes which parts were critical.
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -iterations <n>     Iterations to simulate (default: 1000)
+    -seed <n>           Random seed; the same seed gives the same results (default: 1)
+    -as-of <date>       Date (YYYY-MM-DD) every build starts from (default: today)
+    -format <fmt>       Output format: text, csv, json (default: text)
+    -output <dir>       Output directory for results (required for csv)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+items.csv lead time range columns (optional; distribution is triangular or pert, default triangular):
+    part_number,...,make_buy_code,lead_time_min,lead_time_max,lead_time_distribution
+    F1_ENGINE,F-1 Engine,180,LotForLot,1,8,2,EA,Buy,150,270,pert
+
+EXAMPLES:
+    # P50/P80/P95 completion dates over 10000 iterations
+    mrp risk -scenario examples/apollo_engine_refurb -iterations 10000 -as-of 1968-06-01
+`)
+}
@@ -0,0 +1,436 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/eco"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/domain/repositories"
+	"github.com/vsinha/mrp/pkg/domain/services"
+	"github.com/vsinha/mrp/pkg/domain/services/bom_validator"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/csv"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+// scenario holds the data and repositories loaded from a scenario's CSV files
+type scenario struct {
+	files           map[string]string
+	items           []*entities.Item
+	bomLines        []*entities.BOMLine
+	lotInventory    []*entities.InventoryLot
+	serialInventory []*entities.SerializedInventory
+	demands         []*entities.DemandRequirement
+	ecos            []*entities.ECO
+	receipts        []entities.ScheduledReceipt
+	firmOrders      []entities.PlannedOrder
+	forecasts       []*entities.Forecast
+	appliedECOs     []*entities.ECO
+	serialComp      *services.SerialComparator
+
+	bomRepo       *memory.BOMRepository
+	planningBOM   repositories.BOMRepository // bomRepo with the applied ECOs on top
+	itemRepo      *memory.ItemRepository
+	inventoryRepo *memory.InventoryRepository
+	demandRepo    *memory.DemandRepository
+}
+
+// loadScenarioData loads the input files without checking them against each other, so
+// validation can report every problem instead of stopping at the first
+func loadScenarioData(config Config, files map[string]string) (*scenario, error) {
+	// Load data from CSV files
+	if config.Verbose {
+		fmt.Println("📂 Loading data from CSV files...")
+	}
+
+	csvLoader := csv.NewLoader()
+
+	// Track individual loading times
+	var loadStart time.Time
+
+	// Load Items
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Printf("  🔄 Loading items from %s...", files["Items"])
+	}
+	items, err := csvLoader.LoadItems(files["Items"])
+	if err != nil {
+		return nil, fmt.Errorf("error loading items: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ %d items loaded in %v\n", len(items), time.Since(loadStart))
+	}
+
+	// Load BOM
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Printf("  🔄 Loading BOM from %s...", files["BOM"])
+	}
+	bomLines, err := csvLoader.LoadBOM(files["BOM"])
+	if err != nil {
+		return nil, fmt.Errorf("error loading BOM: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ %d BOM lines loaded in %v\n", len(bomLines), time.Since(loadStart))
+	}
+
+	// Load Inventory
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Printf("  🔄 Loading inventory from %s...", files["Inventory"])
+	}
+	lotInventory, serialInventory, err := csvLoader.LoadInventory(files["Inventory"])
+	if err != nil {
+		return nil, fmt.Errorf("error loading inventory: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ %d lot + %d serial inventory records loaded in %v\n",
+			len(lotInventory), len(serialInventory), time.Since(loadStart))
+	}
+
+	// Load Demands
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Printf("  🔄 Loading demands from %s...", files["Demands"])
+	}
+	demands, err := csvLoader.LoadDemands(files["Demands"])
+	if err != nil {
+		return nil, fmt.Errorf("error loading demands: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ %d demands loaded in %v\n", len(demands), time.Since(loadStart))
+	}
+
+	// Load ECOs (optional)
+	var ecos []*entities.ECO
+	if files["ECOs"] != "" {
+		if config.Verbose {
+			loadStart = time.Now()
+			fmt.Printf("  🔄 Loading ECOs from %s...", files["ECOs"])
+		}
+		ecos, err = csvLoader.LoadECOs(files["ECOs"])
+		if err != nil {
+			return nil, fmt.Errorf("error loading ECOs: %w", err)
+		}
+		if config.Verbose {
+			fmt.Printf(" ✅ %d ECOs loaded in %v\n", len(ecos), time.Since(loadStart))
+		}
+	}
+
+	// Load scheduled receipts (optional)
+	var receipts []entities.ScheduledReceipt
+	if files["ScheduledReceipts"] != "" {
+		if config.Verbose {
+			loadStart = time.Now()
+			fmt.Printf("  🔄 Loading scheduled receipts from %s...", files["ScheduledReceipts"])
+		}
+		receipts, err = csvLoader.LoadScheduledReceipts(files["ScheduledReceipts"])
+		if err != nil {
+			return nil, fmt.Errorf("error loading scheduled receipts: %w", err)
+		}
+		if config.Verbose {
+			fmt.Printf(" ✅ %d scheduled receipts loaded in %v\n", len(receipts), time.Since(loadStart))
+		}
+	}
+
+	// Load firm planned orders (optional)
+	var firmOrders []entities.PlannedOrder
+	if files["FirmPlannedOrders"] != "" {
+		if config.Verbose {
+			loadStart = time.Now()
+			fmt.Printf("  🔄 Loading firm planned orders from %s...", files["FirmPlannedOrders"])
+		}
+		firmOrders, err = csvLoader.LoadFirmPlannedOrders(files["FirmPlannedOrders"])
+		if err != nil {
+			return nil, fmt.Errorf("error loading firm planned orders: %w", err)
+		}
+		if config.Verbose {
+			fmt.Printf(" ✅ %d firm planned orders loaded in %v\n", len(firmOrders), time.Since(loadStart))
+		}
+	}
+
+	// Load forecasts (optional)
+	var forecasts []*entities.Forecast
+	if files["Forecasts"] != "" {
+		if config.Verbose {
+			loadStart = time.Now()
+			fmt.Printf("  🔄 Loading forecasts from %s...", files["Forecasts"])
+		}
+		forecasts, err = csvLoader.LoadForecasts(files["Forecasts"])
+		if err != nil {
+			return nil, fmt.Errorf("error loading forecasts: %w", err)
+		}
+		if config.Verbose {
+			fmt.Printf(" ✅ %d forecasts loaded in %v\n", len(forecasts), time.Since(loadStart))
+		}
+	}
+
+	// Load serial schemes (optional); serials fitting no scheme are rejected
+	serialComp := services.NewSerialComparator()
+	if files["SerialSchemes"] != "" {
+		schemes, err := csvLoader.LoadSerialSchemes(files["SerialSchemes"])
+		if err != nil {
+			return nil, fmt.Errorf("error loading serial schemes: %w", err)
+		}
+		serialComp, err = services.NewSerialComparatorWithSchemes(schemes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid serial schemes: %w", err)
+		}
+		if config.Verbose {
+			fmt.Printf("  ✅ %d serial schemes loaded from %s\n", len(schemes), files["SerialSchemes"])
+		}
+	}
+
+	return &scenario{
+		files:           files,
+		items:           items,
+		bomLines:        bomLines,
+		lotInventory:    lotInventory,
+		serialInventory: serialInventory,
+		demands:         demands,
+		ecos:            ecos,
+		receipts:        receipts,
+		firmOrders:      firmOrders,
+		forecasts:       forecasts,
+		serialComp:      serialComp,
+	}, nil
+}
+
+// loadScenario loads and validates the input files and builds the repositories
+func loadScenario(config Config, files map[string]string) (*scenario, error) {
+	s, err := loadScenarioData(config, files)
+	if err != nil {
+		return nil, err
+	}
+	items, bomLines, demands, ecos, serialComp := s.items, s.bomLines, s.demands, s.ecos, s.serialComp
+	lotInventory, serialInventory := s.lotInventory, s.serialInventory
+	var loadStart time.Time
+
+	if err := serialComp.ValidateBOMSerials(bomLines); err != nil {
+		return nil, fmt.Errorf("BOM serial validation failed: %w", err)
+	}
+	if err := serialComp.ValidateDemandSerials(demands); err != nil {
+		return nil, fmt.Errorf("demand serial validation failed: %w", err)
+	}
+	for _, forecast := range s.forecasts {
+		if err := serialComp.ValidateSerial(forecast.PartNumber, forecast.TargetSerial); err != nil {
+			return nil, fmt.Errorf("forecast serial validation failed: %w", err)
+		}
+	}
+	if err := serialComp.ValidateECOSerials(ecos, bomLines); err != nil {
+		return nil, fmt.Errorf("ECO cut-in serial validation failed: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Println()
+	}
+
+	// Create repositories
+	if config.Verbose {
+		fmt.Println("🏗️  Creating in-memory repositories...")
+		loadStart = time.Now()
+		fmt.Print("  🔄 Setting up BOM repository...")
+	}
+	bomRepo := memory.NewBOMRepository(len(bomLines))
+	bomRepo.SetSerialComparator(serialComp)
+	err = bomRepo.LoadBOMLines(bomLines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load BOM lines into repository: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	}
+
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Print("  🔄 Setting up Item repository...")
+	}
+	itemRepo := memory.NewItemRepository(len(items))
+	err = itemRepo.LoadItems(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load items into repository: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	}
+
+	// Validate BOM-Item consistency
+	if config.Verbose {
+		fmt.Println()
+		loadStart = time.Now()
+		fmt.Print("🔍 Validating BOM-Item consistency...")
+	}
+
+	itemSlice := make([]entities.Item, len(items))
+	for i, item := range items {
+		itemSlice[i] = *item
+	}
+
+	bomSlice := make([]entities.BOMLine, len(bomLines))
+	for i, line := range bomLines {
+		bomSlice[i] = *line
+	}
+
+	consistencyValidation := bom_validator.ValidateBOMItemConsistency(bomSlice, itemSlice)
+	if len(consistencyValidation.Errors) > 0 {
+		return nil, fmt.Errorf("BOM-Item consistency validation failed: %s",
+			strings.Join(consistencyValidation.Errors, "; "))
+	}
+
+	if config.Verbose {
+		fmt.Printf(" ✅ Done in %v", time.Since(loadStart))
+		if len(consistencyValidation.OrphanedParts) > 0 {
+			fmt.Printf(" (Found %d orphaned parts)", len(consistencyValidation.OrphanedParts))
+		}
+		fmt.Println()
+	}
+
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Print("  🔄 Setting up Inventory repository...")
+	}
+	inventoryRepo := memory.NewInventoryRepository()
+	err = inventoryRepo.LoadInventoryLots(lotInventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lot inventory into repository: %w", err)
+	}
+	err = inventoryRepo.LoadSerializedInventory(serialInventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load serialized inventory into repository: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	}
+
+	if config.Verbose {
+		loadStart = time.Now()
+		fmt.Print("  🔄 Setting up Demand repository...")
+	}
+	demandRepo := memory.NewDemandRepository()
+	err = demandRepo.LoadDemands(demands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load demands into repository: %w", err)
+	}
+	if config.Verbose {
+		fmt.Printf(" ✅ Done in %v\n", time.Since(loadStart))
+	}
+
+	// Apply released (and optionally pending) ECOs on top of the base BOM
+	var planningBOM repositories.BOMRepository = bomRepo
+	appliedECOs := eco.SelectECOs(ecos, config.IncludePendingECOs)
+	if len(appliedECOs) > 0 {
+		planningBOM = eco.NewBOMOverlay(bomRepo, appliedECOs, serialComp)
+		if config.Verbose {
+			fmt.Printf("  🛠️  Planning with %d of %d ECO(s)\n", len(appliedECOs), len(ecos))
+		}
+	}
+
+	s.appliedECOs = appliedECOs
+	s.bomRepo = bomRepo
+	s.planningBOM = planningBOM
+	s.itemRepo = itemRepo
+	s.inventoryRepo = inventoryRepo
+	s.demandRepo = demandRepo
+	return s, nil
+}
+
+// newInventoryRepository returns a fresh inventory repository; each plan allocates (and so
+// consumes) inventory
+func (s *scenario) newInventoryRepository() (repositories.InventoryRepository, error) {
+	inventoryRepo := memory.NewInventoryRepository()
+	if err := inventoryRepo.LoadInventoryLots(s.lotInventory); err != nil {
+		return nil, err
+	}
+	if err := inventoryRepo.LoadSerializedInventory(s.serialInventory); err != nil {
+		return nil, err
+	}
+	return inventoryRepo, nil
+}
+
+// validateInputs checks that the scenario inputs are specified
+func (c Config) validateInputs() error {
+	if c.ScenarioDir == "" &&
+		(c.BOMFile == "" || c.ItemsFile == "" ||
+			c.InventoryFile == "" || c.DemandsFile == "") {
+		return fmt.Errorf("must specify either -scenario directory or individual CSV files")
+	}
+	return nil
+}
+
+// resolveInputFiles determines the actual file paths to use
+func (c Config) resolveInputFiles() (map[string]string, error) {
+	var bomPath, itemsPath, inventoryPath, demandsPath string
+
+	if c.ScenarioDir != "" {
+		// Use scenario directory
+		bomPath = filepath.Join(c.ScenarioDir, "bom.csv")
+		itemsPath = filepath.Join(c.ScenarioDir, "items.csv")
+		inventoryPath = filepath.Join(c.ScenarioDir, "inventory.csv")
+		demandsPath = filepath.Join(c.ScenarioDir, "demands.csv")
+	} else {
+		// Use individual files
+		bomPath = c.BOMFile
+		itemsPath = c.ItemsFile
+		inventoryPath = c.InventoryFile
+		demandsPath = c.DemandsFile
+	}
+
+	files := map[string]string{
+		"BOM":       bomPath,
+		"Items":     itemsPath,
+		"Inventory": inventoryPath,
+		"Demands":   demandsPath,
+	}
+
+	// Validate files exist
+	for name, path := range files {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s file not found: %s", name, path)
+		}
+	}
+
+	// Optional files: an explicit path must exist, the scenario default may be absent
+	optionalFiles := []struct {
+		name        string
+		explicit    string
+		defaultName string
+	}{
+		{"ECOs", c.ECOsFile, "ecos.csv"},
+		{"SerialSchemes", c.SerialSchemesFile, "serial_schemes.csv"},
+		{"ScheduledReceipts", c.ScheduledReceiptsFile, "scheduled_receipts.csv"},
+		{"FirmPlannedOrders", c.FirmPlannedOrdersFile, "firm_planned_orders.csv"},
+		{"Forecasts", c.ForecastsFile, "forecasts.csv"},
+	}
+	for _, optional := range optionalFiles {
+		path, err := c.resolveOptionalFile(optional.explicit, optional.defaultName)
+		if err != nil {
+			return nil, fmt.Errorf("%s file not found: %w", optional.name, err)
+		}
+		if path != "" {
+			files[optional.name] = path
+		}
+	}
+
+	return files, nil
+}
+
+// resolveOptionalFile returns the explicit path if set, else the scenario default if it exists
+func (c Config) resolveOptionalFile(explicit, defaultName string) (string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); os.IsNotExist(err) {
+			return "", err
+		}
+		return explicit, nil
+	}
+
+	if c.ScenarioDir != "" {
+		path := filepath.Join(c.ScenarioDir, defaultName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vsinha/mrp/pkg/application/services/validation"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// ValidateCommand checks a scenario for data problems without running MRP
+type ValidateCommand struct {
+	config Config
+}
+
+// NewValidateCommand creates a new validate command with the given configuration
+func NewValidateCommand(config Config) *ValidateCommand {
+	return &ValidateCommand{
+		config: config,
+	}
+}
+
+// Execute runs the validate command; it fails when any error is found (warnings alone pass)
+func (c *ValidateCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	// Load without the checks that stop planning at the first problem, and report them all
+	s, err := loadScenarioData(c.config, files)
+	if err != nil {
+		return err
+	}
+
+	validationService := validation.NewValidationService(s.serialComp)
+	report := validationService.Validate(validation.ScenarioData{
+		Items:           s.items,
+		BOMLines:        s.bomLines,
+		LotInventory:    s.lotInventory,
+		SerialInventory: s.serialInventory,
+		Demands:         s.demands,
+		ECOs:            s.ecos,
+	})
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateValidationReport(report, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("validation found %d error(s)", report.ErrorCount)
+	}
+	return nil
+}
+
+// showHelp displays the help message
+func (c *ValidateCommand) showHelp() {
+	fmt.Printf(`MRP Validate - Check a scenario for data problems without running MRP
+
+USAGE:
+    mrp validate -scenario <directory>
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -format <fmt>       Output format: text, json (default: text)
+    -output <dir>       Output directory for JSON results (optional)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+CHECKS:
+    EffectivityGap       No line is effective for some serials between two ranges at a find number
+    EffectivityOverlap   Alternates with the same priority are effective for the same serials
+    EffectivityInverted  A serial range ends before it starts
+
+Exits with a non-zero status when any issue is found.
+
+EXAMPLES:
+    mrp validate -scenario examples/apollo_saturn_v
+`)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vsinha/mrp/pkg/application/services/implosion"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/interfaces/cli/output"
+)
+
+// WhereUsedConfig holds configuration for the where-used command
+type WhereUsedConfig struct {
+	Config            // Scenario inputs and output settings
+	PartNumber string // Part to implode
+	Serial     string // End item serial to filter effectivity by (optional)
+}
+
+// WhereUsedCommand reports every assembly, end item and open demand using a part
+type WhereUsedCommand struct {
+	config WhereUsedConfig
+}
+
+// NewWhereUsedCommand creates a new where-used command with the given configuration
+func NewWhereUsedCommand(config WhereUsedConfig) *WhereUsedCommand {
+	return &WhereUsedCommand{
+		config: config,
+	}
+}
+
+// Execute runs the where-used command
+func (c *WhereUsedCommand) Execute(ctx context.Context) error {
+	if c.config.Help {
+		c.showHelp()
+		return nil
+	}
+
+	if c.config.PartNumber == "" {
+		return fmt.Errorf("validation error: must specify -part")
+	}
+	if err := c.config.validateInputs(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	files, err := c.config.resolveInputFiles()
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	s, err := loadScenario(c.config.Config, files)
+	if err != nil {
+		return err
+	}
+
+	partNumber := entities.PartNumber(c.config.PartNumber)
+	if _, err := s.itemRepo.GetItem(partNumber); err != nil {
+		return fmt.Errorf("unknown part %s: %w", partNumber, err)
+	}
+
+	implosionService := implosion.NewImplosionService(s.planningBOM, s.demandRepo)
+	result, err := implosionService.WhereUsed(ctx, partNumber, c.config.Serial)
+	if err != nil {
+		return fmt.Errorf("error imploding %s: %w", partNumber, err)
+	}
+
+	outputConfig := output.Config{
+		Format:     c.config.Format,
+		OutputDir:  c.config.OutputDir,
+		Verbose:    c.config.Verbose,
+		InputFiles: files,
+	}
+	if err := output.GenerateWhereUsed(result, outputConfig); err != nil {
+		return fmt.Errorf("error generating output: %w", err)
+	}
+
+	return nil
+}
+
+// showHelp displays the help message
+func (c *WhereUsedCommand) showHelp() {
+	fmt.Printf(`MRP Where-Used - Find every assembly, end item and demand using a part
+
+USAGE:
+    mrp where-used -scenario <directory> -part <part_number> [-serial <serial>]
+
+OPTIONS:
+    -scenario <dir>     Path to scenario directory containing CSV files
+    -bom <file>         Path to BOM CSV file
+    -items <file>       Path to items CSV file
+    -inventory <file>   Path to inventory CSV file
+    -demands <file>     Path to demands CSV file
+    -part <pn>          Part number to look up (required)
+    -serial <serial>    Only follow BOM lines effective for this end item serial, and only
+                        report its demands (default: check each demand's own serial)
+    -format <fmt>       Output format: text, json (default: text)
+    -output <dir>       Output directory for JSON results (optional)
+    -ecos <file>        Path to ECO CSV file (default: ecos.csv in the scenario, if present)
+    -include-pending-ecos
+                        Also apply draft ECOs (released ECOs are always applied)
+    -serial-schemes <file>
+                        Path to serial schemes CSV (default: serial_schemes.csv in the scenario, if present)
+    -verbose            Enable verbose output
+    -help               Show this help message
+
+EXAMPLES:
+    # Which assemblies and demands use the RCS valve?
+    mrp where-used -scenario examples/apollo_csm -part RCS_VALVE
+
+    # Only for CSM107
+    mrp where-used -scenario examples/apollo_csm -part RCS_VALVE -serial CSM107
+`)
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateActionMessages writes the action messages of an MRP run, most urgent first
+func GenerateActionMessages(report dto.ActionMessageReport, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateActionMessagesText(report)
+	case "csv":
+		return generateActionMessagesCSV(report, config)
+	case "json":
+		return generateActionMessagesJSON(report, config)
+	default:
+		return fmt.Errorf("unsupported output format for action messages: %s", config.Format)
+	}
+}
+
+// generateActionMessagesText prints the action messages as a table
+func generateActionMessagesText(report dto.ActionMessageReport) error {
+	fmt.Printf("🛎️  Action Messages: %d\n", len(report.Messages))
+	fmt.Printf("======================\n\n")
+
+	if len(report.Messages) == 0 {
+		fmt.Printf("Nothing to do: open and planned orders match the plan\n\n")
+		return nil
+	}
+
+	fmt.Printf("%-4s %-9s %-15s %-10s %-12s %-8s %-12s %-12s %-5s %s\n",
+		"Rank", "Action", "Part Number", "Location", "Order", "Qty", "Due Date", "Need Date", "Days", "Message")
+	fmt.Printf("%-4s %-9s %-15s %-10s %-12s %-8s %-12s %-12s %-5s %s\n",
+		"----", "---------", "---------------", "----------", "------------", "--------", "------------",
+		"------------", "-----", "-------")
+	for _, message := range report.Messages {
+		fmt.Printf("%-4d %-9s %-15s %-10s %-12s %-8d %-12s %-12s %-5d %s\n",
+			message.Rank,
+			message.Action,
+			message.PartNumber,
+			message.Location,
+			message.OrderNumber,
+			message.Quantity,
+			message.DueDate.Format("2006-01-02"),
+			actionNeedDate(message),
+			message.Days,
+			message.Message)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// actionNeedDate formats the need date of an action message, or "-" when nothing needs it
+func actionNeedDate(message dto.ActionMessageSummary) string {
+	if message.NeedDate.IsZero() {
+		return "-"
+	}
+	return message.NeedDate.Format("2006-01-02")
+}
+
+// generateActionMessagesCSV writes one row per action message to action_messages.csv in the
+// output directory
+func generateActionMessagesCSV(report dto.ActionMessageReport, config Config) error {
+	if config.OutputDir == "" {
+		return fmt.Errorf("output directory required for CSV format")
+	}
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "action_messages.csv")
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	header := []string{
+		"rank", "action", "part_number", "location", "order_number", "quantity", "due_date",
+		"need_date", "days", "message",
+	}
+	var records [][]string
+	for _, message := range report.Messages {
+		needDate := ""
+		if !message.NeedDate.IsZero() {
+			needDate = message.NeedDate.Format("2006-01-02")
+		}
+		records = append(records, []string{
+			strconv.Itoa(message.Rank),
+			message.Action,
+			string(message.PartNumber),
+			message.Location,
+			message.OrderNumber,
+			strconv.FormatInt(int64(message.Quantity), 10),
+			message.DueDate.Format("2006-01-02"),
+			needDate,
+			strconv.Itoa(message.Days),
+			message.Message,
+		})
+	}
+	if err := writeCSVRecords(file, header, records); err != nil {
+		return err
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Action messages saved to: %s\n", filename)
+	}
+	return nil
+}
+
+// generateActionMessagesJSON writes the action messages as JSON to stdout or the output directory
+func generateActionMessagesJSON(report dto.ActionMessageReport, config Config) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "action_messages.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Action messages saved to: %s\n", filename)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// GenerateBOMComparison writes the differences between the BOMs of two serials
+func GenerateBOMComparison(comparison *dto.BOMComparison, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateBOMComparisonText(comparison)
+	case "json":
+		return generateBOMComparisonJSON(comparison, config)
+	default:
+		return fmt.Errorf("unsupported output format for BOM comparison: %s", config.Format)
+	}
+}
+
+// generateBOMComparisonText prints the differences as a table
+func generateBOMComparisonText(comparison *dto.BOMComparison) error {
+	fmt.Printf("🔀 BOM Comparison: %s %s → %s\n",
+		comparison.PartNumber, comparison.FromSerial, comparison.ToSerial)
+	fmt.Printf("======================\n\n")
+
+	if len(comparison.Differences) == 0 {
+		fmt.Printf("✅ No differences\n")
+		return nil
+	}
+
+	fmt.Printf("Differences: %d\n\n", len(comparison.Differences))
+	fmt.Printf("%-5s %-20s %-6s %-16s %-24s %-24s\n",
+		"Level", "Parent", "Find", "Change", comparison.FromSerial, comparison.ToSerial)
+	fmt.Printf("%-5s %-20s %-6s %-16s %-24s %-24s\n",
+		"-----", "--------------------", "------", "----------------",
+		"------------------------", "------------------------")
+
+	for _, diff := range comparison.Differences {
+		fmt.Printf("%-5d %-20s %-6d %-16s %-24s %-24s\n",
+			diff.Level,
+			diff.ParentPN,
+			diff.FindNumber,
+			diff.ChangeTypeName,
+			formatComponent(diff.FromPart, diff.FromQtyPer),
+			formatComponent(diff.ToPart, diff.ToQtyPer))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateBOMComparisonJSON writes the differences as JSON to stdout or the output directory
+func generateBOMComparisonJSON(comparison *dto.BOMComparison, config Config) error {
+	jsonData, err := json.MarshalIndent(comparison, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "bom_comparison.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 BOM comparison saved to: %s\n", filename)
+	}
+
+	return nil
+}
+
+// formatComponent formats a component as "PART x QTY", or "-" when absent
+func formatComponent(partNumber entities.PartNumber, qtyPer entities.Quantity) string {
+	if partNumber == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s x %d", partNumber, qtyPer)
+}
@@ -0,0 +1,183 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateBOMReport writes an indented BOM
+func GenerateBOMReport(report *dto.BOMReport, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateBOMReportText(report)
+	case "csv":
+		return generateBOMReportCSV(report, config)
+	case "json":
+		return generateBOMReportJSON(report, config)
+	default:
+		return fmt.Errorf("unsupported output format for BOM report: %s", config.Format)
+	}
+}
+
+// generateBOMReportText prints the BOM with part numbers indented by level
+func generateBOMReportText(report *dto.BOMReport) error {
+	fmt.Printf("📋 Indented BOM: %s (serial %s, qty %d)\n", report.PartNumber, report.Serial, report.Quantity)
+	if !report.NeedDate.IsZero() {
+		fmt.Printf("Date effectivity as of %s\n", report.NeedDate.Format("2006-01-02"))
+	}
+	fmt.Printf("======================\n\n")
+
+	fmt.Printf("%-5s %-6s %-36s %-8s %-8s %-4s %-18s %s\n",
+		"Level", "Find", "Part Number", "Qty Per", "Ext Qty", "UoM", "Effectivity", "Alternates")
+	fmt.Printf("%-5s %-6s %-36s %-8s %-8s %-4s %-18s %s\n",
+		"-----", "------", "------------------------------------", "--------", "--------",
+		"----", "------------------", "----------")
+
+	for _, line := range report.Lines {
+		findNumber := ""
+		if line.Level > 0 {
+			findNumber = strconv.Itoa(line.FindNumber)
+		}
+
+		partNumber := strings.Repeat("  ", line.Level) + string(line.PartNumber)
+		if line.Phantom {
+			partNumber += " (phantom)"
+		}
+
+		fmt.Printf("%-5d %-6s %-36s %-8d %-8d %-4s %-18s %s\n",
+			line.Level,
+			findNumber,
+			partNumber,
+			line.QtyPer,
+			line.ExtendedQty,
+			line.UnitOfMeasure,
+			formatSerialRange(line.FromSerial, line.ToSerial),
+			formatAlternates(line.Alternates))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateBOMReportCSV writes the BOM as CSV to stdout or the output directory
+func generateBOMReportCSV(report *dto.BOMReport, config Config) error {
+	if config.OutputDir == "" {
+		return writeBOMReportCSV(report, os.Stdout)
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "bom_report.csv")
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := writeBOMReportCSV(report, file); err != nil {
+		return err
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 BOM report saved to: %s\n", filename)
+	}
+
+	return nil
+}
+
+// writeBOMReportCSV writes one row per BOM line; alternates are joined with ";"
+func writeBOMReportCSV(report *dto.BOMReport, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"level", "find_number", "part_number", "description", "qty_per", "extended_qty",
+		"unit_of_measure", "phantom", "from_serial", "to_serial", "alternates",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, line := range report.Lines {
+		alternates := make([]string, len(line.Alternates))
+		for i, alternate := range line.Alternates {
+			alternates[i] = string(alternate.PartNumber)
+		}
+
+		record := []string{
+			strconv.Itoa(line.Level),
+			strconv.Itoa(line.FindNumber),
+			string(line.PartNumber),
+			line.Description,
+			strconv.FormatInt(int64(line.QtyPer), 10),
+			strconv.FormatInt(int64(line.ExtendedQty), 10),
+			line.UnitOfMeasure,
+			strconv.FormatBool(line.Phantom),
+			line.FromSerial,
+			line.ToSerial,
+			strings.Join(alternates, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// generateBOMReportJSON writes the BOM as JSON to stdout or the output directory
+func generateBOMReportJSON(report *dto.BOMReport, config Config) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "bom_report.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 BOM report saved to: %s\n", filename)
+	}
+
+	return nil
+}
+
+// formatSerialRange formats serial effectivity as "from-to", with "+" for an open end
+func formatSerialRange(fromSerial, toSerial string) string {
+	if fromSerial == "" && toSerial == "" {
+		return ""
+	}
+	if toSerial == "" {
+		return fromSerial + "+"
+	}
+	return fromSerial + "-" + toSerial
+}
+
+// formatAlternates lists alternates with their priority, e.g. "F1_TURBOPUMP_V1 (p1)"
+func formatAlternates(alternates []dto.AlternateOption) string {
+	formatted := make([]string, len(alternates))
+	for i, alternate := range alternates {
+		formatted[i] = fmt.Sprintf("%s (p%d)", alternate.PartNumber, alternate.Priority)
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,188 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// criticalPathReports returns the report of each critical path analysis in config
+func criticalPathReports(config Config) []dto.CriticalPathReport {
+	var reports []dto.CriticalPathReport
+	for _, analysis := range config.CriticalPaths {
+		reports = append(reports, dto.NewCriticalPathReport(analysis))
+	}
+	return reports
+}
+
+// floatReports returns the float report of each critical path analysis in config
+func floatReports(config Config) []dto.FloatReport {
+	var reports []dto.FloatReport
+	for _, analysis := range config.CriticalPaths {
+		reports = append(reports, dto.NewFloatReport(analysis, config.NearCriticalDays))
+	}
+	return reports
+}
+
+// criticalParts returns the parts on the critical path of any analysis in config
+func criticalParts(config Config) map[entities.PartNumber]bool {
+	parts := make(map[entities.PartNumber]bool)
+	for _, report := range criticalPathReports(config) {
+		for _, part := range report.CriticalParts() {
+			parts[part] = true
+		}
+	}
+	return parts
+}
+
+// printCriticalPaths prints the ranked paths of each analysis, one table per path
+func printCriticalPaths(reports []dto.CriticalPathReport) {
+	for _, report := range reports {
+		fmt.Printf("🛤️  Critical Paths: %s (serial %s, %s, by %s) - %d paths analyzed\n",
+			report.TopLevelPart, report.TargetSerial, report.Location, report.Mode, report.TotalPaths)
+		if len(report.Paths) == 0 {
+			fmt.Printf("  none\n\n")
+			continue
+		}
+
+		for _, path := range report.Paths {
+			fmt.Printf("  #%d: %d days (%d effective) - %d levels - bottleneck %s\n",
+				path.Rank, path.TotalLeadTime, path.EffectiveLeadTime, path.PathLength, path.BottleneckPart)
+			fmt.Printf("    %-6s %-15s %-9s %-9s %-6s %-10s %-9s %-11s\n",
+				"Level", "Part Number", "Lead Time", "Effective", "Offset", "Cumulative", "Inventory",
+				"Total Float")
+			fmt.Printf("    %-6s %-15s %-9s %-9s %-6s %-10s %-9s %-11s\n",
+				"------", "---------------", "---------", "---------", "------", "----------", "---------",
+				"-----------")
+			for _, step := range path.Steps {
+				fmt.Printf("    %-6d %-15s %-9d %-9d %-6d %-10d %-9d %-11d\n",
+					step.Level,
+					step.PartNumber,
+					step.LeadTimeDays,
+					step.EffectiveLeadTime,
+					step.LeadTimeOffsetDays,
+					step.CumulativeTime,
+					step.InventoryQty,
+					step.TotalFloat)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// printFloatTable prints the schedule and float of parts under a title
+func printFloatTable(title string, parts []dto.PartFloat) {
+	fmt.Printf("  %s:\n", title)
+	if len(parts) == 0 {
+		fmt.Printf("    none\n\n")
+		return
+	}
+
+	fmt.Printf("    %-15s %-6s %-9s %-6s %-6s %-6s %-6s %-11s %-10s\n",
+		"Part Number", "Level", "Lead Time", "ES", "EF", "LS", "LF", "Total Float", "Free Float")
+	fmt.Printf("    %-15s %-6s %-9s %-6s %-6s %-6s %-6s %-11s %-10s\n",
+		"---------------", "------", "---------", "------", "------", "------", "------",
+		"-----------", "----------")
+	for _, part := range parts {
+		fmt.Printf("    %-15s %-6d %-9d %-6d %-6d %-6d %-6d %-11d %-10d\n",
+			part.PartNumber,
+			part.Level,
+			part.LeadTimeDays,
+			part.EarliestStart,
+			part.EarliestFinish,
+			part.LatestStart,
+			part.LatestFinish,
+			part.TotalFloat,
+			part.FreeFloat)
+	}
+	fmt.Println()
+}
+
+// generateCriticalPathsCSV writes the critical paths in config to the output directory, if any
+// were analyzed, and returns the file written
+func generateCriticalPathsCSV(config Config) (string, error) {
+	reports := criticalPathReports(config)
+	if len(reports) == 0 {
+		return "", nil
+	}
+
+	filename := filepath.Join(config.OutputDir, "critical_paths.csv")
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := writeCriticalPathsCSV(reports, file); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// writeCriticalPathsCSV writes one row per part on each ranked path; scheduled dates are only
+// filled in schedule mode
+func writeCriticalPathsCSV(reports []dto.CriticalPathReport, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"top_level_part", "target_serial", "location", "mode", "path_rank", "path_total_lead_time",
+		"path_effective_lead_time", "step", "level", "part_number", "description", "lead_time_days",
+		"effective_lead_time", "lead_time_offset_days", "cumulative_time", "has_inventory",
+		"inventory_qty", "required_qty", "scheduled_start", "scheduled_due", "total_float",
+		"free_float",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, report := range reports {
+		for _, path := range report.Paths {
+			for i, step := range path.Steps {
+				record := []string{
+					string(report.TopLevelPart),
+					report.TargetSerial,
+					report.Location,
+					report.Mode,
+					strconv.Itoa(path.Rank),
+					strconv.Itoa(path.TotalLeadTime),
+					strconv.Itoa(path.EffectiveLeadTime),
+					strconv.Itoa(i + 1),
+					strconv.Itoa(step.Level),
+					string(step.PartNumber),
+					step.Description,
+					strconv.Itoa(step.LeadTimeDays),
+					strconv.Itoa(step.EffectiveLeadTime),
+					strconv.Itoa(step.LeadTimeOffsetDays),
+					strconv.Itoa(step.CumulativeTime),
+					strconv.FormatBool(step.HasInventory),
+					strconv.FormatInt(int64(step.InventoryQty), 10),
+					strconv.FormatInt(int64(step.RequiredQty), 10),
+					formatCSVDate(step.ScheduledStart),
+					formatCSVDate(step.ScheduledDue),
+					strconv.Itoa(step.TotalFloat),
+					strconv.Itoa(step.FreeFloat),
+				}
+				if err := writer.Write(record); err != nil {
+					return fmt.Errorf("failed to write CSV record: %w", err)
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatCSVDate formats a date for CSV, leaving the field empty when there is none
+func formatCSVDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
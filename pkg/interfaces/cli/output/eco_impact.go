@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateECOImpact writes the planned order changes an ECO would cause
+func GenerateECOImpact(impact *dto.ECOImpact, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateECOImpactText(impact)
+	case "json":
+		return generateECOImpactJSON(impact, config)
+	default:
+		return fmt.Errorf("unsupported output format for ECO impact: %s", config.Format)
+	}
+}
+
+// generateECOImpactText prints the ECO impact as a table
+func generateECOImpactText(impact *dto.ECOImpact) error {
+	fmt.Printf("🛠️  ECO Impact: %s (%s)\n", impact.ECOID, impact.Status)
+	fmt.Printf("======================\n\n")
+
+	if len(impact.Changes) == 0 {
+		fmt.Printf("✅ No planned orders change\n")
+		return nil
+	}
+
+	fmt.Printf("Changed planned orders: %d\n\n", len(impact.Changes))
+	fmt.Printf("%-15s %-16s %-8s %-8s %-12s %-12s\n",
+		"Part Number", "Change", "Qty Was", "Qty Now", "Due Was", "Due Now")
+	fmt.Printf("%-15s %-16s %-8s %-8s %-12s %-12s\n",
+		"---------------", "----------------", "--------", "--------",
+		"------------", "------------")
+
+	for _, change := range impact.Changes {
+		fmt.Printf("%-15s %-16s %-8d %-8d %-12s %-12s\n",
+			change.PartNumber,
+			change.ChangeTypeName,
+			change.QuantityBefore,
+			change.QuantityAfter,
+			formatOptionalDate(change.DueDateBefore),
+			formatOptionalDate(change.DueDateAfter))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateECOImpactJSON writes the ECO impact as JSON to stdout or the output directory
+func generateECOImpactJSON(impact *dto.ECOImpact, config Config) error {
+	jsonData, err := json.MarshalIndent(impact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "eco_impact.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 ECO impact saved to: %s\n", filename)
+	}
+
+	return nil
+}
+
+// formatOptionalDate formats a date, or "-" when unset
+func formatOptionalDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format("2006-01-02")
+}
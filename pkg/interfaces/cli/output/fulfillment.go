@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// printDemandFulfillment prints when each demand is projected to be fulfilled, and what gates it
+func printDemandFulfillment(report dto.DemandFulfillmentReport) {
+	if report.TotalDemands == 0 {
+		return
+	}
+
+	fmt.Printf("📅 Demand Fulfillment - %d of %d demands late\n", report.LateDemands, report.TotalDemands)
+	fmt.Printf("%-35s %-12s %-12s %-9s %-11s %-6s %-35s\n",
+		"Demand", "Need Date", "Projected", "Days Late", "Status", "Stock", "Gated By")
+	fmt.Printf("%-35s %-12s %-12s %-9s %-11s %-6s %-35s\n",
+		"-----------------------------------", "------------", "------------", "---------",
+		"-----------", "------", "-----------------------------------")
+	for _, demand := range report.Demands {
+		fmt.Printf("%-35s %-12s %-12s %-9d %-11s %-6d %-35s\n",
+			demand.Demand,
+			demand.NeedDate.Format("2006-01-02"),
+			fulfillmentDate(demand),
+			demand.DaysLate,
+			demand.Status,
+			demand.StockQty,
+			demand.GatedBy)
+	}
+	fmt.Println()
+}
+
+// fulfillmentDate formats the projected date of a demand, or "-" when it is unfulfilled
+func fulfillmentDate(demand dto.DemandFulfillmentSummary) string {
+	if demand.ProjectedDate.IsZero() {
+		return "-"
+	}
+	return demand.ProjectedDate.Format("2006-01-02")
+}
+
+// generateFulfillmentCSV writes the projected fulfillment of each demand to the output
+// directory and returns the file written
+func generateFulfillmentCSV(report dto.DemandFulfillmentReport, config Config) (string, error) {
+	filename := filepath.Join(config.OutputDir, "demand_fulfillment.csv")
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := writeDemandFulfillmentCSV(report, file); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// writeDemandFulfillmentCSV writes one row per demand with its projected fulfillment
+func writeDemandFulfillmentCSV(report dto.DemandFulfillmentReport, w io.Writer) error {
+	header := []string{
+		"demand", "part_number", "target_serial", "location", "quantity", "need_date",
+		"projected_date", "days_late", "status", "covered_by_stock", "stock_qty", "order_qty",
+		"short_qty", "gated_by",
+	}
+	var records [][]string
+	for _, demand := range report.Demands {
+		projected := ""
+		if !demand.ProjectedDate.IsZero() {
+			projected = demand.ProjectedDate.Format("2006-01-02")
+		}
+		records = append(records, []string{
+			demand.Demand,
+			string(demand.PartNumber),
+			demand.TargetSerial,
+			demand.Location,
+			strconv.FormatInt(int64(demand.Quantity), 10),
+			demand.NeedDate.Format("2006-01-02"),
+			projected,
+			strconv.Itoa(demand.DaysLate),
+			demand.Status,
+			strconv.FormatBool(demand.CoveredByStock),
+			strconv.FormatInt(int64(demand.StockQty), 10),
+			strconv.FormatInt(int64(demand.OrderQty), 10),
+			strconv.FormatInt(int64(demand.ShortQty), 10),
+			demand.GatedBy,
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
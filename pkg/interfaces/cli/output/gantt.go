@@ -23,6 +23,8 @@ type GanttChart struct {
 	TimeScale    time.Duration
 	StartTime    time.Time
 	EndTime      time.Time
+
+	CriticalParts map[entities.PartNumber]bool // Parts whose bars are outlined as critical
 }
 
 // GanttBar represents a single bar in the Gantt chart
@@ -94,6 +96,11 @@ func NewGanttChart(result *dto.MRPResult) *GanttChart {
 	}
 }
 
+// HighlightParts outlines the bars and labels of parts, such as those on a critical path
+func (gc *GanttChart) HighlightParts(parts map[entities.PartNumber]bool) {
+	gc.CriticalParts = parts
+}
+
 // GenerateSVG creates an SVG representation of the Gantt chart
 func (gc *GanttChart) GenerateSVG(result *dto.MRPResult) string {
 	if len(result.PlannedOrders) == 0 {
@@ -112,6 +119,8 @@ func (gc *GanttChart) GenerateSVG(result *dto.MRPResult) string {
 	svg.WriteString(`.grid-line { stroke: #e0e0e0; stroke-width: 1; }`)
 	svg.WriteString(`.order-bar { stroke: #333; stroke-width: 1; }`)
 	svg.WriteString(`.order-text { font-family: Arial, sans-serif; font-size: 9px; fill: white; }`)
+	svg.WriteString(`.critical-bar { stroke: #D32F2F; stroke-width: 3; }`)
+	svg.WriteString(`.critical-label { font-weight: bold; fill: #D32F2F; }`)
 	svg.WriteString(`</style>`)
 	svg.WriteString(`</defs>`)
 
@@ -318,8 +327,12 @@ func (gc *GanttChart) drawPartRows(svg *strings.Builder, partRows map[entities.P
 		bars := partRows[part]
 
 		// Part label - positioned further left to avoid overlap
-		svg.WriteString(fmt.Sprintf(`<text x="%d" y="%d" class="part-label" text-anchor="end">%s</text>`,
-			gc.MarginLeft-15, y+adjustedRowHeight/2+4, string(part)))
+		labelClass := "part-label"
+		if gc.CriticalParts[part] {
+			labelClass += " critical-label"
+		}
+		svg.WriteString(fmt.Sprintf(`<text x="%d" y="%d" class="%s" text-anchor="end">%s</text>`,
+			gc.MarginLeft-15, y+adjustedRowHeight/2+4, labelClass, string(part)))
 
 		// Horizontal row line - FIXED: position properly with adjusted height
 		svg.WriteString(fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" class="grid-line"/>`,
@@ -337,9 +350,13 @@ func (gc *GanttChart) drawBar(svg *strings.Builder, bar GanttBar, rowY int, rowH
 	barHeight := rowHeight - 4
 	barY := rowY + 2
 
-	// Draw bar rectangle
-	svg.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" class="order-bar"/>`,
-		bar.X, barY, bar.Width, barHeight, bar.Color))
+	// Draw bar rectangle, outlined when the part is critical
+	barClass := "order-bar"
+	if gc.CriticalParts[bar.PartNumber] {
+		barClass += " critical-bar"
+	}
+	svg.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" class="%s"/>`,
+		bar.X, barY, bar.Width, barHeight, bar.Color, barClass))
 
 	// Add text if bar is wide enough
 	if bar.Width > 40 {
@@ -371,9 +388,13 @@ func (gc *GanttChart) drawLegend(svg *strings.Builder) {
 	legendX := gc.Width - gc.MarginRight - 200
 	legendY := 50
 
-	// Legend background
-	svg.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="180" height="60" fill="white" stroke="#ccc" stroke-width="1"/>`,
-		legendX, legendY))
+	// Legend background, with a row for the critical path outline when parts are highlighted
+	legendHeight := 60
+	if len(gc.CriticalParts) > 0 {
+		legendHeight += 12
+	}
+	svg.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="180" height="%d" fill="white" stroke="#ccc" stroke-width="1"/>`,
+		legendX, legendY, legendHeight))
 
 	// Legend title
 	svg.WriteString(fmt.Sprintf(`<text x="%d" y="%d" class="part-label" font-weight="bold">Legend</text>`,
@@ -396,6 +417,14 @@ func (gc *GanttChart) drawLegend(svg *strings.Builder) {
 		svg.WriteString(fmt.Sprintf(`<text x="%d" y="%d" class="time-label">%s</text>`,
 			legendX+30, itemY+6, item.label))
 	}
+
+	if len(gc.CriticalParts) > 0 {
+		itemY := legendY + 25 + len(items)*12
+		svg.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="12" height="8" fill="white" class="critical-bar"/>`,
+			legendX+10, itemY))
+		svg.WriteString(fmt.Sprintf(`<text x="%d" y="%d" class="time-label">Critical Path</text>`,
+			legendX+30, itemY+6))
+	}
 }
 
 // getBarColor returns color based on order type and split status
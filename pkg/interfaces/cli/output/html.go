@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -33,7 +34,8 @@ type NetworkNode struct {
 	StartDate  time.Time           `json:"startDate"`
 	DueDate    time.Time           `json:"dueDate"`
 	Location   string              `json:"location"`
-	Level      int                 `json:"level"` // BOM level for positioning
+	Level      int                 `json:"level"`    // BOM level for positioning
+	Critical   bool                `json:"critical"` // On the critical path of a demand
 }
 
 // NetworkLink represents a dependency relationship
@@ -56,6 +58,7 @@ type TimelineBar struct {
 	Location   string              `json:"location"`
 	Split      int                 `json:"split"`
 	Color      string              `json:"color"`
+	Critical   bool                `json:"critical"`
 }
 
 // VisualizationData contains all data needed for the HTML visualization
@@ -65,6 +68,10 @@ type VisualizationData struct {
 	TimelineBars  []TimelineBar               `json:"timelineBars"`
 	Allocations   []entities.AllocationResult `json:"allocations"`
 	Shortages     []entities.Shortage         `json:"shortages"`
+	Fulfillment   dto.DemandFulfillmentReport `json:"fulfillment"`
+	CriticalPaths []dto.CriticalPathReport    `json:"criticalPaths"`
+	FloatAnalysis []dto.FloatReport           `json:"floatAnalysis"`
+	Program       *dto.ProgramReport          `json:"program,omitempty"`
 	ExplosionTime time.Duration               `json:"explosionTime"`
 	StartDate     time.Time                   `json:"startDate"`
 	EndDate       time.Time                   `json:"endDate"`
@@ -154,8 +161,13 @@ func (hv *HTMLVisualization) buildVisualizationData(result *dto.MRPResult, confi
 	vizData := &VisualizationData{
 		Allocations:   result.Allocations,
 		Shortages:     result.ShortageReport,
+		Fulfillment:   dto.NewDemandFulfillmentReport(result.DemandFulfillment),
+		CriticalPaths: criticalPathReports(config),
+		FloatAnalysis: floatReports(config),
+		Program:       programReport(config),
 		ExplosionTime: config.ExplosionTime,
 	}
+	critical := criticalParts(config)
 
 	fmt.Printf("Buiding visualization data...\n")
 
@@ -192,6 +204,7 @@ func (hv *HTMLVisualization) buildVisualizationData(result *dto.MRPResult, confi
 			DueDate:    order.DueDate,
 			Location:   order.Location,
 			Level:      0, // Will be calculated from BOM relationships
+			Critical:   critical[order.PartNumber],
 		}
 
 		partMap[order.PartNumber] = node
@@ -213,6 +226,7 @@ func (hv *HTMLVisualization) buildVisualizationData(result *dto.MRPResult, confi
 			Location:   order.Location,
 			Split:      split,
 			Color:      hv.getBarColor(order.OrderType, split),
+			Critical:   critical[order.PartNumber],
 		}
 
 		vizData.TimelineBars = append(vizData.TimelineBars, bar)
@@ -346,13 +360,20 @@ func generateHTMLOutput(result *dto.MRPResult, config Config) error {
 		fmt.Printf("  📝 Generated HTML document (%d bytes)\n", len(html))
 	}
 
-	// Write HTML to file
+	// Write HTML to file, next to the SVG when one was requested
 	filename := config.SVGOutput // Reuse SVGOutput path but change extension
-	if strings.HasSuffix(filename, ".svg") {
+	if filename == "" {
+		filename = filepath.Join(config.OutputDir, "mrp_visualization.html")
+	} else if strings.HasSuffix(filename, ".svg") {
 		filename = strings.TrimSuffix(filename, ".svg") + ".html"
 	} else if !strings.HasSuffix(filename, ".html") {
 		filename += ".html"
 	}
+	if config.OutputDir != "" {
+		if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
 
 	if config.Verbose {
 		fmt.Printf("  💾 Writing HTML file to: %s\n", filename)
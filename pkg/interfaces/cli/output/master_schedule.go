@@ -0,0 +1,197 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// GenerateMasterSchedule writes the master production schedule, followed by the MRP explosion
+// of it when result is not nil
+func GenerateMasterSchedule(report *dto.MasterScheduleReport, result *dto.MRPResult, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateMasterScheduleText(report, result, config)
+	case "csv":
+		return generateMasterScheduleCSV(report, result, config)
+	case "json":
+		return generateMasterScheduleJSON(report, result, config)
+	default:
+		return fmt.Errorf("unsupported output format for master schedule: %s", config.Format)
+	}
+}
+
+// generateMasterScheduleText prints a grid per end item, one column per period
+func generateMasterScheduleText(report *dto.MasterScheduleReport, result *dto.MRPResult, config Config) error {
+	fmt.Printf("🗓️  Master Production Schedule - %d end items, %d-day periods from %s\n",
+		len(report.Items), report.PeriodDays, report.Start.Format("2006-01-02"))
+	fmt.Printf("==========================================================\n\n")
+
+	if len(report.Items) == 0 {
+		fmt.Printf("No forecasts or customer orders to master schedule\n\n")
+	}
+
+	for _, item := range report.Items {
+		fmt.Printf("%s @ %s - on hand %d, safety stock %d, demand time fence %s, planning time fence %s\n",
+			item.PartNumber, item.Location, item.OnHand, item.SafetyStock,
+			fenceDate(item.DemandTimeFence), fenceDate(item.PlanningTimeFence))
+
+		rows := []struct {
+			label string
+			value func(period dto.MPSPeriodSummary) string
+		}{
+			{"Zone", func(p dto.MPSPeriodSummary) string { return p.Zone }},
+			{"Forecast", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.Forecast) }},
+			{"Customer Orders", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.CustomerOrders) }},
+			{"Projected Demand", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.ProjectedDemand) }},
+			{"Sched Receipts", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.ScheduledReceipts) }},
+			{"Firm MPS", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.FirmMPS) }},
+			{"Planned MPS", func(p dto.MPSPeriodSummary) string { return mpsQuantity(p.PlannedMPS) }},
+			{"Projected Avail", func(p dto.MPSPeriodSummary) string { return strconv.FormatInt(int64(p.ProjectedAvailable), 10) }},
+			{"ATP", func(p dto.MPSPeriodSummary) string { return strconv.FormatInt(int64(p.AvailableToPromise), 10) }},
+			{"Cumulative ATP", func(p dto.MPSPeriodSummary) string { return strconv.FormatInt(int64(p.CumulativeATP), 10) }},
+		}
+
+		fmt.Printf("%-17s", "Period")
+		for _, period := range item.Periods {
+			fmt.Printf(" %7s", period.Start.Format("01-02"))
+		}
+		fmt.Printf("\n%-17s", "-----------------")
+		for range item.Periods {
+			fmt.Printf(" %7s", "-------")
+		}
+		fmt.Println()
+		for _, row := range rows {
+			fmt.Printf("%-17s", row.label)
+			for _, period := range item.Periods {
+				fmt.Printf(" %7s", row.value(period))
+			}
+			fmt.Println()
+		}
+		fmt.Printf("Total demand %d, total MPS %d", item.TotalDemand, item.TotalMPS)
+		if item.ShortPeriods > 0 {
+			fmt.Printf(", projected short in %d periods", item.ShortPeriods)
+		}
+		fmt.Printf("\n\n")
+	}
+
+	if result == nil {
+		return nil
+	}
+	return generateTextOutput(result, config)
+}
+
+// fenceDate formats a time fence, or "none" when the item has none
+func fenceDate(fence time.Time) string {
+	if fence.IsZero() {
+		return "none"
+	}
+	return fence.Format("2006-01-02")
+}
+
+// mpsQuantity formats a quantity for the MPS grid, showing zero as a dot
+func mpsQuantity(qty entities.Quantity) string {
+	if qty == 0 {
+		return "."
+	}
+	return strconv.FormatInt(int64(qty), 10)
+}
+
+// generateMasterScheduleCSV writes one row per end item period to mps.csv in the output
+// directory, along with the MRP CSVs when result is not nil
+func generateMasterScheduleCSV(report *dto.MasterScheduleReport, result *dto.MRPResult, config Config) error {
+	if config.OutputDir == "" {
+		return fmt.Errorf("output directory required for CSV format")
+	}
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "mps.csv")
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	header := []string{
+		"part_number", "location", "period_start", "zone", "forecast", "customer_orders",
+		"projected_demand", "scheduled_receipts", "firm_mps", "planned_mps", "projected_available",
+		"available_to_promise", "cumulative_atp", "target_serial",
+	}
+	var records [][]string
+	for _, item := range report.Items {
+		for _, period := range item.Periods {
+			records = append(records, []string{
+				string(item.PartNumber),
+				item.Location,
+				period.Start.Format("2006-01-02"),
+				period.Zone,
+				strconv.FormatInt(int64(period.Forecast), 10),
+				strconv.FormatInt(int64(period.CustomerOrders), 10),
+				strconv.FormatInt(int64(period.ProjectedDemand), 10),
+				strconv.FormatInt(int64(period.ScheduledReceipts), 10),
+				strconv.FormatInt(int64(period.FirmMPS), 10),
+				strconv.FormatInt(int64(period.PlannedMPS), 10),
+				strconv.FormatInt(int64(period.ProjectedAvailable), 10),
+				strconv.FormatInt(int64(period.AvailableToPromise), 10),
+				strconv.FormatInt(int64(period.CumulativeATP), 10),
+				period.TargetSerial,
+			})
+		}
+	}
+	if err := writeCSVRecords(file, header, records); err != nil {
+		return err
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Master schedule saved to: %s\n", filename)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return generateCSVOutput(result, config)
+}
+
+// generateMasterScheduleJSON writes the master schedule and its MRP explosion as one JSON
+// object to stdout or the output directory
+func generateMasterScheduleJSON(report *dto.MasterScheduleReport, result *dto.MRPResult, config Config) error {
+	output := struct {
+		MasterSchedule *dto.MasterScheduleReport `json:"master_schedule"`
+		MRP            *dto.MRPResult            `json:"mrp,omitempty"`
+	}{
+		MasterSchedule: report,
+		MRP:            result,
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "mps.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Master schedule saved to: %s\n", filename)
+	}
+	return nil
+}
@@ -19,6 +19,10 @@ type Config struct {
 	Verbose       bool
 	ExplosionTime time.Duration
 	InputFiles    map[string]string
+
+	CriticalPaths    []*entities.CriticalPathAnalysis // Critical path analyses to report, if any
+	NearCriticalDays int                              // Parts with less float than this are near-critical
+	Program          *entities.ProgramCriticalPath    // Critical paths of every demand combined, if analyzed
 }
 
 // Generate creates output in the specified format
@@ -62,7 +66,16 @@ func generateTextOutput(result *dto.MRPResult, config Config) error {
 	fmt.Printf("Planned Orders: %d\n", len(result.PlannedOrders))
 	fmt.Printf("Allocations: %d\n", len(result.Allocations))
 	fmt.Printf("Shortages: %d\n", len(result.ShortageReport))
-	fmt.Printf("Explosion Time: %v\n\n", config.ExplosionTime)
+	if len(result.ActionMessages) > 0 {
+		fmt.Printf("Action Messages: %d (see mrp actions)\n", len(result.ActionMessages))
+	}
+	fmt.Printf("Explosion Time: %v\n", config.ExplosionTime)
+	if config.Verbose {
+		stats := result.CacheStats
+		fmt.Printf("Explosion Cache: %d hits, %d misses (%.0f%% hit rate), %d evictions, %d entries\n",
+			stats.Hits, stats.Misses, stats.HitRate()*100, stats.Evictions, stats.Entries)
+	}
+	fmt.Println()
 
 	if len(result.PlannedOrders) > 0 {
 		fmt.Printf("📋 Planned Orders:\n")
@@ -79,12 +92,16 @@ func generateTextOutput(result *dto.MRPResult, config Config) error {
 		)
 
 		for _, order := range result.PlannedOrders {
+			orderType := order.OrderType.String()
+			if order.Firm {
+				orderType += " (Firm)"
+			}
 			fmt.Printf("%-15s %-8d %-12s %-12s %-15s %-10s\n",
 				order.PartNumber,
 				order.Quantity,
 				order.StartDate.Format("2006-01-02"),
 				order.DueDate.Format("2006-01-02"),
-				order.OrderType.String(),
+				orderType,
 				order.Location)
 		}
 		fmt.Println()
@@ -125,6 +142,22 @@ func generateTextOutput(result *dto.MRPResult, config Config) error {
 		fmt.Println()
 	}
 
+	printDemandFulfillment(dto.NewDemandFulfillmentReport(result.DemandFulfillment))
+
+	printCriticalPaths(criticalPathReports(config))
+
+	for _, report := range floatReports(config) {
+		fmt.Printf("⏱️  Float Analysis: %s (serial %s, %s, by %s)\n",
+			report.TopLevelPart, report.TargetSerial, report.Location, report.Mode)
+		printFloatTable("Zero-float parts", report.ZeroFloat)
+		printFloatTable(
+			fmt.Sprintf("Near-critical parts (< %d days float)", report.NearCriticalDays),
+			report.NearCritical,
+		)
+	}
+
+	printProgram(programReport(config))
+
 	// Save to file if output directory specified
 	if config.OutputDir != "" {
 		// Create output directory if it doesn't exist
@@ -144,7 +177,20 @@ func generateTextOutput(result *dto.MRPResult, config Config) error {
 
 // generateJSONOutput creates JSON output
 func generateJSONOutput(result *dto.MRPResult, config Config) error {
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+	// Critical paths, their float and the program analysis are added next to the MRP result fields, when analyzed
+	output := struct {
+		*dto.MRPResult
+		CriticalPaths []dto.CriticalPathReport `json:"critical_paths,omitempty"`
+		FloatAnalysis []dto.FloatReport        `json:"float_analysis,omitempty"`
+		Program       *dto.ProgramReport       `json:"program,omitempty"`
+	}{
+		MRPResult:     result,
+		CriticalPaths: criticalPathReports(config),
+		FloatAnalysis: floatReports(config),
+		Program:       programReport(config),
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -204,11 +250,36 @@ func generateCSVOutput(result *dto.MRPResult, config Config) error {
 		return fmt.Errorf("failed to write shortages CSV: %w", err)
 	}
 
+	// Generate demand fulfillment CSV
+	fulfillmentFile, err := generateFulfillmentCSV(dto.NewDemandFulfillmentReport(result.DemandFulfillment), config)
+	if err != nil {
+		return fmt.Errorf("failed to write demand fulfillment CSV: %w", err)
+	}
+
+	// Generate critical paths CSV, when critical paths were analyzed
+	criticalPathsFile, err := generateCriticalPathsCSV(config)
+	if err != nil {
+		return fmt.Errorf("failed to write critical paths CSV: %w", err)
+	}
+
+	// Generate program CSVs, when the critical paths of every demand were combined
+	programFiles, err := generateProgramCSV(config)
+	if err != nil {
+		return fmt.Errorf("failed to write program CSV: %w", err)
+	}
+
 	if config.Verbose {
 		fmt.Printf("💾 CSV results saved to:\n")
 		fmt.Printf("  Planned Orders: %s\n", ordersFile)
 		fmt.Printf("  Allocations: %s\n", allocFile)
 		fmt.Printf("  Shortages: %s\n", shortageFile)
+		fmt.Printf("  Demand Fulfillment: %s\n", fulfillmentFile)
+		if criticalPathsFile != "" {
+			fmt.Printf("  Critical Paths: %s\n", criticalPathsFile)
+		}
+		for _, file := range programFiles {
+			fmt.Printf("  Program: %s\n", file)
+		}
 	}
 
 	return nil
@@ -234,6 +305,7 @@ func writeShortagesCSV(shortages []entities.Shortage, filename string) error {
 func generateSVGOutput(result *dto.MRPResult, config Config) error {
 	// Create Gantt chart
 	gantt := NewGanttChart(result)
+	gantt.HighlightParts(criticalParts(config))
 
 	// Generate SVG content
 	svgContent := gantt.GenerateSVG(result)
@@ -0,0 +1,201 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// programReport returns the report of the program critical path analysis in config, if any
+func programReport(config Config) *dto.ProgramReport {
+	if config.Program == nil {
+		return nil
+	}
+	report := dto.NewProgramReport(config.Program)
+	return &report
+}
+
+// printProgram prints the demands, bottleneck ranking and shared components of a program
+func printProgram(report *dto.ProgramReport) {
+	if report == nil {
+		return
+	}
+
+	fmt.Printf("🚦 Program Critical Path (as of %s, by %s) - %d of %d demands late\n",
+		report.AsOf.Format("2006-01-02"), report.Mode, report.LateDemands, report.TotalDemands)
+
+	fmt.Printf("  Demands:\n")
+	fmt.Printf("    %-35s %-12s %-12s %-9s %-10s %-15s\n",
+		"Demand", "Need Date", "Projected", "Days Late", "Build Days", "Bottleneck")
+	fmt.Printf("    %-35s %-12s %-12s %-9s %-10s %-15s\n",
+		"-----------------------------------", "------------", "------------", "---------",
+		"----------", "---------------")
+	for _, demand := range report.Demands {
+		fmt.Printf("    %-35s %-12s %-12s %-9d %-10d %-15s\n",
+			demand.Demand,
+			demand.NeedDate.Format("2006-01-02"),
+			demand.ProjectedCompletion.Format("2006-01-02"),
+			demand.DaysLate,
+			demand.BuildDays,
+			demand.BottleneckPart)
+	}
+	fmt.Println()
+
+	fmt.Printf("  Bottleneck parts:\n")
+	if len(report.Bottlenecks) == 0 {
+		fmt.Printf("    none\n\n")
+	} else {
+		fmt.Printf("    %-4s %-15s %-9s %-13s %-13s\n",
+			"Rank", "Part Number", "Lead Time", "Demands Gated", "Lateness Days")
+		fmt.Printf("    %-4s %-15s %-9s %-13s %-13s\n",
+			"----", "---------------", "---------", "-------------", "-------------")
+		for _, part := range report.Bottlenecks {
+			fmt.Printf("    %-4d %-15s %-9d %-13d %-13d\n",
+				part.Rank, part.PartNumber, part.LeadTimeDays, part.DemandsGated, part.LatenessDays)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("  Shared components:\n")
+	if len(report.SharedComponents) == 0 {
+		fmt.Printf("    none\n\n")
+		return
+	}
+	fmt.Printf("    %-15s %-7s %-12s %-9s %-8s %-9s\n",
+		"Part Number", "Demands", "Critical For", "Allocated", "Planned", "Short Qty")
+	fmt.Printf("    %-15s %-7s %-12s %-9s %-8s %-9s\n",
+		"---------------", "-------", "------------", "---------", "--------", "---------")
+	for _, component := range report.SharedComponents {
+		fmt.Printf("    %-15s %-7d %-12d %-9d %-8d %-9d\n",
+			component.PartNumber,
+			component.DemandCount,
+			component.CriticalFor,
+			component.AllocatedQty,
+			component.PlannedQty,
+			component.ShortQty)
+	}
+	fmt.Println()
+}
+
+// generateProgramCSV writes the demands, bottleneck ranking and shared components of the program
+// analysis in config to the output directory, if there is one, and returns the files written
+func generateProgramCSV(config Config) ([]string, error) {
+	report := programReport(config)
+	if report == nil {
+		return nil, nil
+	}
+
+	writers := []struct {
+		name  string
+		write func(*dto.ProgramReport, io.Writer) error
+	}{
+		{"program_demands.csv", writeProgramDemandsCSV},
+		{"program_bottlenecks.csv", writeProgramBottlenecksCSV},
+		{"shared_components.csv", writeSharedComponentsCSV},
+	}
+
+	var files []string
+	for _, w := range writers {
+		filename := filepath.Join(config.OutputDir, w.name)
+		file, err := os.Create(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CSV file: %w", err)
+		}
+		err = w.write(report, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// writeProgramDemandsCSV writes one row per demand with its projected completion
+func writeProgramDemandsCSV(report *dto.ProgramReport, w io.Writer) error {
+	header := []string{
+		"demand", "part_number", "target_serial", "location", "quantity", "need_date",
+		"projected_completion", "days_late", "build_days", "bottleneck_part", "critical_parts",
+	}
+	var records [][]string
+	for _, demand := range report.Demands {
+		records = append(records, []string{
+			demand.Demand,
+			string(demand.PartNumber),
+			demand.TargetSerial,
+			demand.Location,
+			strconv.FormatInt(int64(demand.Quantity), 10),
+			demand.NeedDate.Format("2006-01-02"),
+			demand.ProjectedCompletion.Format("2006-01-02"),
+			strconv.Itoa(demand.DaysLate),
+			strconv.Itoa(demand.BuildDays),
+			string(demand.BottleneckPart),
+			strconv.Itoa(demand.CriticalParts),
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
+
+// writeProgramBottlenecksCSV writes one row per bottleneck part, the gated demands separated by ;
+func writeProgramBottlenecksCSV(report *dto.ProgramReport, w io.Writer) error {
+	header := []string{
+		"rank", "part_number", "description", "lead_time_days", "demands_gated", "lateness_days",
+		"demands",
+	}
+	var records [][]string
+	for _, part := range report.Bottlenecks {
+		records = append(records, []string{
+			strconv.Itoa(part.Rank),
+			string(part.PartNumber),
+			part.Description,
+			strconv.Itoa(part.LeadTimeDays),
+			strconv.Itoa(part.DemandsGated),
+			strconv.Itoa(part.LatenessDays),
+			strings.Join(part.Demands, ";"),
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
+
+// writeSharedComponentsCSV writes one row per shared component, its demands separated by ;
+func writeSharedComponentsCSV(report *dto.ProgramReport, w io.Writer) error {
+	header := []string{
+		"part_number", "description", "demand_count", "critical_for", "allocated_qty",
+		"planned_qty", "short_qty", "demands",
+	}
+	var records [][]string
+	for _, component := range report.SharedComponents {
+		records = append(records, []string{
+			string(component.PartNumber),
+			component.Description,
+			strconv.Itoa(component.DemandCount),
+			strconv.Itoa(component.CriticalFor),
+			strconv.FormatInt(int64(component.AllocatedQty), 10),
+			strconv.FormatInt(int64(component.PlannedQty), 10),
+			strconv.FormatInt(int64(component.ShortQty), 10),
+			strings.Join(component.Demands, ";"),
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
+
+// writeCSVRecords writes a header and records as CSV
+func writeCSVRecords(w io.Writer, header []string, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+)
+
+// progressBarWidth is the number of cells in a progress bar
+const progressBarWidth = 30
+
+// ProgressBar draws MRP progress as one bar per stage, redrawn in place
+type ProgressBar struct {
+	writer  io.Writer
+	stage   mrp.ProgressStage
+	percent int
+	started bool
+}
+
+// NewProgressBar creates a progress bar writing to w (usually stderr, so stdout stays clean
+// for JSON output)
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{writer: w}
+}
+
+// OnProgress implements mrp.ProgressObserver; it redraws only when the percentage changes
+func (b *ProgressBar) OnProgress(progress mrp.Progress) {
+	percent := 100
+	if progress.Total > 0 {
+		percent = progress.Done * 100 / progress.Total
+	}
+
+	if b.started && progress.Stage == b.stage && percent == b.percent {
+		return
+	}
+	if b.started && progress.Stage != b.stage && b.percent < 100 {
+		fmt.Fprintln(b.writer) // Leave the unfinished bar of the previous stage behind
+	}
+	b.started = true
+	b.stage = progress.Stage
+	b.percent = percent
+
+	filled := percent * progressBarWidth / 100
+	fmt.Fprintf(b.writer, "\r  🔄 %-18s [%s%s] %3d%% (%d/%d)",
+		progress.Stage,
+		strings.Repeat("█", filled),
+		strings.Repeat("░", progressBarWidth-filled),
+		percent,
+		progress.Done,
+		progress.Total,
+	)
+	if percent == 100 {
+		fmt.Fprintln(b.writer)
+	}
+}
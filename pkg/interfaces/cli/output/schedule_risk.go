@@ -0,0 +1,175 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateScheduleRisk writes the completion date percentiles and part criticality of a
+// schedule risk simulation
+func GenerateScheduleRisk(report *dto.ScheduleRiskReport, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateScheduleRiskText(report)
+	case "csv":
+		return generateScheduleRiskCSV(report, config)
+	case "json":
+		return generateScheduleRiskJSON(report, config)
+	default:
+		return fmt.Errorf("unsupported output format for schedule risk: %s", config.Format)
+	}
+}
+
+// generateScheduleRiskText prints the demand completion dates and part criticality as tables
+func generateScheduleRiskText(report *dto.ScheduleRiskReport) error {
+	fmt.Printf("🎲 Schedule Risk: %d iterations (seed %d), as of %s\n",
+		report.Iterations, report.Seed, report.AsOf.Format("2006-01-02"))
+	fmt.Printf("======================\n\n")
+
+	fmt.Printf("Demand Completion:\n")
+	fmt.Printf("%-35s %-12s %-12s %-12s %-12s %-12s %-7s\n",
+		"Demand", "Need Date", "Planned", "P50", "P80", "P95", "On Time")
+	fmt.Printf("%-35s %-12s %-12s %-12s %-12s %-12s %-7s\n",
+		"-----------------------------------", "------------", "------------", "------------",
+		"------------", "------------", "-------")
+	for _, demand := range report.Demands {
+		fmt.Printf("%-35s %-12s %-12s %-12s %-12s %-12s %6.1f%%\n",
+			demand.Demand,
+			demand.NeedDate.Format("2006-01-02"),
+			demand.PlannedCompletion.Format("2006-01-02"),
+			demand.P50.Format("2006-01-02"),
+			demand.P80.Format("2006-01-02"),
+			demand.P95.Format("2006-01-02"),
+			demand.OnTimeProbability*100)
+	}
+	fmt.Println()
+
+	fmt.Printf("Part Criticality (share of iterations on the critical path):\n")
+	if len(report.Parts) == 0 {
+		fmt.Printf("  none\n\n")
+		return nil
+	}
+	fmt.Printf("%-20s %-7s %-11s %-10s\n", "Part Number", "Demands", "Criticality", "Varies")
+	fmt.Printf("%-20s %-7s %-11s %-10s\n",
+		"--------------------", "-------", "-----------", "----------")
+	for _, part := range report.Parts {
+		varies := "-"
+		if part.HasLeadTimeRange {
+			varies = "yes"
+		}
+		fmt.Printf("%-20s %-7d %10.1f%% %-10s\n",
+			part.PartNumber, part.Demands, part.CriticalityIndex*100, varies)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateScheduleRiskCSV writes the demand completion dates and part criticality as two CSV
+// files in the output directory
+func generateScheduleRiskCSV(report *dto.ScheduleRiskReport, config Config) error {
+	if config.OutputDir == "" {
+		return fmt.Errorf("output directory required for CSV format")
+	}
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	writers := []struct {
+		name  string
+		write func(*dto.ScheduleRiskReport, io.Writer) error
+	}{
+		{"demand_risk.csv", writeDemandRiskCSV},
+		{"part_criticality.csv", writePartCriticalityCSV},
+	}
+	for _, w := range writers {
+		filename := filepath.Join(config.OutputDir, w.name)
+		file, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("failed to create CSV file: %w", err)
+		}
+		err = w.write(report, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		if config.Verbose {
+			fmt.Printf("💾 Schedule risk saved to: %s\n", filename)
+		}
+	}
+
+	return nil
+}
+
+// writeDemandRiskCSV writes one row per demand with its completion date percentiles
+func writeDemandRiskCSV(report *dto.ScheduleRiskReport, w io.Writer) error {
+	header := []string{
+		"demand", "part_number", "need_date", "planned_completion", "p50", "p80", "p95",
+		"on_time_probability",
+	}
+	var records [][]string
+	for _, demand := range report.Demands {
+		records = append(records, []string{
+			demand.Demand,
+			string(demand.PartNumber),
+			demand.NeedDate.Format("2006-01-02"),
+			demand.PlannedCompletion.Format("2006-01-02"),
+			demand.P50.Format("2006-01-02"),
+			demand.P80.Format("2006-01-02"),
+			demand.P95.Format("2006-01-02"),
+			strconv.FormatFloat(demand.OnTimeProbability, 'f', 4, 64),
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
+
+// writePartCriticalityCSV writes one row per part that was ever critical, most critical first
+func writePartCriticalityCSV(report *dto.ScheduleRiskReport, w io.Writer) error {
+	header := []string{"part_number", "description", "demands", "criticality_index", "has_lead_time_range"}
+	var records [][]string
+	for _, part := range report.Parts {
+		records = append(records, []string{
+			string(part.PartNumber),
+			part.Description,
+			strconv.Itoa(part.Demands),
+			strconv.FormatFloat(part.CriticalityIndex, 'f', 4, 64),
+			strconv.FormatBool(part.HasLeadTimeRange),
+		})
+	}
+	return writeCSVRecords(w, header, records)
+}
+
+// generateScheduleRiskJSON writes the schedule risk report as JSON to stdout or the output
+// directory
+func generateScheduleRiskJSON(report *dto.ScheduleRiskReport, config Config) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "schedule_risk.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Schedule risk saved to: %s\n", filename)
+	}
+
+	return nil
+}
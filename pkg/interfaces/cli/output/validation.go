@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateValidationReport writes the problems found in a scenario
+func GenerateValidationReport(report *dto.ValidationReport, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateValidationText(report)
+	case "json":
+		return generateValidationJSON(report, config)
+	default:
+		return fmt.Errorf("unsupported output format for validation: %s", config.Format)
+	}
+}
+
+// generateValidationText prints one line per issue
+func generateValidationText(report *dto.ValidationReport) error {
+	fmt.Printf("🔍 Scenario Validation\n")
+	fmt.Printf("======================\n\n")
+
+	if len(report.Issues) == 0 {
+		fmt.Printf("✅ No issues found\n")
+		return nil
+	}
+
+	fmt.Printf("Errors: %d, Warnings: %d\n\n", report.ErrorCount, report.WarningCount)
+	for _, issue := range report.Issues {
+		icon := "❌"
+		if issue.Severity == dto.SeverityWarning {
+			icon = "⚠️ "
+		}
+		location := string(issue.PartNumber)
+		if issue.FindNumber != 0 {
+			location = fmt.Sprintf("%s find %d", issue.PartNumber, issue.FindNumber)
+		}
+		fmt.Printf("  %s %-7s [%s] %s: %s\n", icon, issue.SeverityName, issue.Check, location, issue.Message)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateValidationJSON writes the report as JSON to stdout or the output directory
+func generateValidationJSON(report *dto.ValidationReport, config Config) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "validation.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Validation report saved to: %s\n", filename)
+	}
+
+	return nil
+}
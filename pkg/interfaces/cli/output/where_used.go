@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vsinha/mrp/pkg/application/dto"
+)
+
+// GenerateWhereUsed writes the assemblies, end items and demands using a part
+func GenerateWhereUsed(result *dto.WhereUsedResult, config Config) error {
+	switch config.Format {
+	case "text":
+		return generateWhereUsedText(result)
+	case "json":
+		return generateWhereUsedJSON(result, config)
+	default:
+		return fmt.Errorf("unsupported output format for where-used: %s", config.Format)
+	}
+}
+
+// generateWhereUsedText prints the where-used report as tables
+func generateWhereUsedText(result *dto.WhereUsedResult) error {
+	serial := result.Serial
+	if serial == "" {
+		serial = "all serials"
+	}
+	fmt.Printf("🔎 Where Used: %s (%s)\n", result.PartNumber, serial)
+	fmt.Printf("======================\n\n")
+
+	if len(result.Usages) == 0 {
+		fmt.Printf("No assemblies use %s\n\n", result.PartNumber)
+	} else {
+		fmt.Printf("Used In (%d BOM lines):\n", len(result.Usages))
+		fmt.Printf("%-6s %-20s %-20s %-8s %-6s %-10s %-10s\n",
+			"Level", "Parent", "Child", "Qty Per", "Find", "From", "To")
+		fmt.Printf("%-6s %-20s %-20s %-8s %-6s %-10s %-10s\n",
+			"------", "--------------------", "--------------------", "--------", "------",
+			"----------", "----------")
+		for _, usage := range result.Usages {
+			toSerial := usage.ToSerial
+			if toSerial == "" {
+				toSerial = "-"
+			}
+			fmt.Printf("%-6d %-20s %-20s %-8d %-6d %-10s %-10s\n",
+				usage.Level,
+				usage.ParentPN,
+				usage.ChildPN,
+				usage.QtyPer,
+				usage.FindNumber,
+				usage.FromSerial,
+				toSerial)
+		}
+		fmt.Println()
+	}
+
+	if len(result.EndItems) > 0 {
+		fmt.Printf("End Items (%d):\n", len(result.EndItems))
+		for _, endItem := range result.EndItems {
+			path := make([]string, len(endItem.Path))
+			for i, pn := range endItem.Path {
+				path[i] = string(pn)
+			}
+			fmt.Printf("  %-20s %d per unit  (%s)\n",
+				endItem.EndItem, endItem.QuantityPer, strings.Join(path, " → "))
+		}
+		fmt.Println()
+	}
+
+	if len(result.Demands) == 0 {
+		fmt.Printf("✅ No open demands use %s\n", result.PartNumber)
+		return nil
+	}
+
+	fmt.Printf("⚠️  Affected Demands (%d):\n", len(result.Demands))
+	fmt.Printf("%-20s %-20s %-8s %-12s %-12s %-10s %-10s\n",
+		"Demand Source", "Part Number", "Qty", "Need Date", "Location", "Serial", "Parts Req")
+	fmt.Printf("%-20s %-20s %-8s %-12s %-12s %-10s %-10s\n",
+		"--------------------", "--------------------", "--------", "------------",
+		"------------", "----------", "----------")
+	for _, demand := range result.Demands {
+		fmt.Printf("%-20s %-20s %-8d %-12s %-12s %-10s %-10d\n",
+			demand.DemandSource,
+			demand.PartNumber,
+			demand.Quantity,
+			demand.NeedDate.Format("2006-01-02"),
+			demand.Location,
+			demand.TargetSerial,
+			demand.PartsRequired)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// generateWhereUsedJSON writes the where-used report as JSON to stdout or the output directory
+func generateWhereUsedJSON(result *dto.WhereUsedResult, config Config) error {
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if config.OutputDir == "" {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filename := filepath.Join(config.OutputDir, "where_used.json")
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("💾 Where-used report saved to: %s\n", filename)
+	}
+
+	return nil
+}
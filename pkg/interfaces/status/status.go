@@ -0,0 +1,75 @@
+package status
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/vsinha/mrp/pkg/domain/entities"
+)
+
+// Exit codes of the mrp command. Code 2 is left to the flag package for usage errors.
+const (
+	ExitOK                   = 0
+	ExitFailure              = 1 // Any error without a more specific code
+	ExitItemNotFound         = 3
+	ExitCyclicBOM            = 4
+	ExitNoEffectiveAlternate = 5
+	ExitInvalidSerial        = 6
+	ExitAllocationConflict   = 7
+	ExitLateDemand           = 8 // A demand is projected late, with --fail-on-late
+	ExitNotFound             = 9 // A lot, serial or ECO not found
+)
+
+// ExitCode maps an error to the process exit code for the domain error it wraps
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var (
+		itemNotFound         *entities.ItemNotFoundError
+		cyclicBOM            *entities.CyclicBOMError
+		noEffectiveAlternate *entities.NoEffectiveAlternateError
+		invalidSerial        *entities.InvalidSerialError
+		allocationConflict   *entities.AllocationConflictError
+		lateDemand           *entities.LateDemandError
+		notFound             *entities.NotFoundError
+	)
+	switch {
+	case errors.As(err, &itemNotFound):
+		return ExitItemNotFound
+	case errors.As(err, &cyclicBOM):
+		return ExitCyclicBOM
+	case errors.As(err, &noEffectiveAlternate):
+		return ExitNoEffectiveAlternate
+	case errors.As(err, &invalidSerial):
+		return ExitInvalidSerial
+	case errors.As(err, &allocationConflict):
+		return ExitAllocationConflict
+	case errors.As(err, &lateDemand):
+		return ExitLateDemand
+	case errors.As(err, &notFound):
+		return ExitNotFound
+	default:
+		return ExitFailure
+	}
+}
+
+// HTTPStatus maps an error to the HTTP status an API should answer with: bad input is a
+// client error, data the request cannot be planned against is unprocessable
+func HTTPStatus(err error) int {
+	switch ExitCode(err) {
+	case ExitOK:
+		return http.StatusOK
+	case ExitItemNotFound, ExitNotFound:
+		return http.StatusNotFound
+	case ExitCyclicBOM, ExitNoEffectiveAlternate, ExitLateDemand:
+		return http.StatusUnprocessableEntity
+	case ExitInvalidSerial:
+		return http.StatusBadRequest
+	case ExitAllocationConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,167 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vsinha/mrp/pkg/application/services/mps"
+	"github.com/vsinha/mrp/pkg/application/services/mrp"
+	"github.com/vsinha/mrp/pkg/domain/entities"
+	"github.com/vsinha/mrp/pkg/infrastructure/repositories/memory"
+)
+
+func TestExitCodeAndHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		exitCode   int
+		httpStatus int
+	}{
+		{"nil", nil, ExitOK, http.StatusOK},
+		{"untyped", errors.New("disk full"), ExitFailure, http.StatusInternalServerError},
+		{"item_not_found", &entities.ItemNotFoundError{PartNumber: "F1_ENGINE"},
+			ExitItemNotFound, http.StatusNotFound},
+		{"cyclic_bom", &entities.CyclicBOMError{Cycle: []entities.PartNumber{"A", "B", "A"}},
+			ExitCyclicBOM, http.StatusUnprocessableEntity},
+		{"no_effective_alternate", &entities.NoEffectiveAlternateError{ParentPN: "SATURN_V", Serial: "AS499"},
+			ExitNoEffectiveAlternate, http.StatusUnprocessableEntity},
+		{"invalid_serial", &entities.InvalidSerialError{PartNumber: "SATURN_V", Serial: "X", Reason: "fits no serial scheme"},
+			ExitInvalidSerial, http.StatusBadRequest},
+		{"allocation_conflict", &entities.AllocationConflictError{PartNumber: "BOLT", LotNumber: "LOT1",
+			Status: entities.Quarantine}, ExitAllocationConflict, http.StatusConflict},
+		{"late_demand", &entities.LateDemandError{Late: 2, Total: 5},
+			ExitLateDemand, http.StatusUnprocessableEntity},
+		{"not_found", &entities.NotFoundError{Kind: "lot", ID: "LOT1", PartNumber: "BOLT"},
+			ExitNotFound, http.StatusNotFound},
+		{"wrapped", fmt.Errorf("failed to explode demand: %w", &entities.ItemNotFoundError{PartNumber: "X"}),
+			ExitItemNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, got)
+			}
+			if got := HTTPStatus(tt.err); got != tt.httpStatus {
+				t.Errorf("Expected HTTP status %d, got %d", tt.httpStatus, got)
+			}
+		})
+	}
+}
+
+// TestExitCodeAndHTTPStatus_FromServices maps the errors the services actually return, wrapped
+// as they reach the CLI, rather than errors built by hand
+func TestExitCodeAndHTTPStatus_FromServices(t *testing.T) {
+	ctx := context.Background()
+	needDate := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newItemRepo := func(partNumbers ...entities.PartNumber) *memory.ItemRepository {
+		itemRepo := memory.NewItemRepository(len(partNumbers))
+		for _, pn := range partNumbers {
+			if err := itemRepo.SaveItem(&entities.Item{
+				PartNumber:   pn,
+				LeadTimeDays: 10,
+				LotSizeRule:  entities.LotForLot,
+				MinOrderQty:  1,
+			}); err != nil {
+				t.Fatalf("Failed to save item: %v", err)
+			}
+		}
+		return itemRepo
+	}
+	newBOMRepo := func(lines ...*entities.BOMLine) *memory.BOMRepository {
+		bomRepo := memory.NewBOMRepository(len(lines))
+		for _, line := range lines {
+			if err := bomRepo.SaveBOMLine(line); err != nil {
+				t.Fatalf("Failed to save BOM line: %v", err)
+			}
+		}
+		return bomRepo
+	}
+	explode := func(bomRepo *memory.BOMRepository, itemRepo *memory.ItemRepository, serial string) error {
+		demands := []*entities.DemandRequirement{
+			{PartNumber: "ENGINE", Quantity: 1, NeedDate: needDate, DemandSource: "ORDER", Location: "PLANT", TargetSerial: serial},
+		}
+		_, err := mrp.NewMRPService().ExplodeDemand(
+			ctx, demands, bomRepo, itemRepo, memory.NewInventoryRepository(), memory.NewDemandRepository())
+		if err != nil {
+			return fmt.Errorf("error running MRP explosion: %w", err)
+		}
+		return nil
+	}
+	fromSN := func(from string) entities.SerialEffectivity { return entities.SerialEffectivity{FromSerial: from} }
+
+	t.Run("item_not_found", func(t *testing.T) {
+		bomRepo := newBOMRepo(&entities.BOMLine{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 1, FindNumber: 100, Effectivity: fromSN("SN001")})
+		err := explode(bomRepo, newItemRepo("ENGINE"), "SN001")
+		if ExitCode(err) != ExitItemNotFound || HTTPStatus(err) != http.StatusNotFound {
+			t.Errorf("Expected exit code %d and HTTP 404, got %d and %d for %v",
+				ExitItemNotFound, ExitCode(err), HTTPStatus(err), err)
+		}
+	})
+
+	t.Run("cyclic_bom", func(t *testing.T) {
+		bomRepo := newBOMRepo(
+			&entities.BOMLine{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 1, FindNumber: 100, Effectivity: fromSN("SN001")},
+			&entities.BOMLine{ParentPN: "VALVE", ChildPN: "ENGINE", QtyPer: 1, FindNumber: 100, Effectivity: fromSN("SN001")},
+		)
+		err := explode(bomRepo, newItemRepo("ENGINE", "VALVE"), "SN001")
+		if ExitCode(err) != ExitCyclicBOM || HTTPStatus(err) != http.StatusUnprocessableEntity {
+			t.Errorf("Expected exit code %d and HTTP 422, got %d and %d for %v",
+				ExitCyclicBOM, ExitCode(err), HTTPStatus(err), err)
+		}
+	})
+
+	t.Run("no_effective_alternate", func(t *testing.T) {
+		// The valve assembly's only line cuts in after the serial built
+		bomRepo := newBOMRepo(
+			&entities.BOMLine{ParentPN: "ENGINE", ChildPN: "VALVE", QtyPer: 1, FindNumber: 100, Effectivity: fromSN("SN001")},
+			&entities.BOMLine{ParentPN: "VALVE", ChildPN: "SEAL", QtyPer: 1, FindNumber: 100, Effectivity: fromSN("SN050")},
+		)
+		err := explode(bomRepo, newItemRepo("ENGINE", "VALVE", "SEAL"), "SN010")
+		var noEffective *entities.NoEffectiveAlternateError
+		if !errors.As(err, &noEffective) || noEffective.ParentPN != "VALVE" {
+			t.Fatalf("Expected a NoEffectiveAlternateError for VALVE, got %v", err)
+		}
+		if ExitCode(err) != ExitNoEffectiveAlternate || HTTPStatus(err) != http.StatusUnprocessableEntity {
+			t.Errorf("Expected exit code %d and HTTP 422, got %d and %d",
+				ExitNoEffectiveAlternate, ExitCode(err), HTTPStatus(err))
+		}
+	})
+
+	t.Run("allocation_conflict", func(t *testing.T) {
+		itemRepo := newItemRepo("ENGINE")
+		inventoryRepo := memory.NewInventoryRepository()
+		if err := inventoryRepo.SaveInventoryLot(&entities.InventoryLot{
+			PartNumber: "ENGINE", LotNumber: "LOT001", Location: "PLANT", Quantity: 2, Status: entities.Available,
+		}); err != nil {
+			t.Fatalf("Failed to save inventory: %v", err)
+		}
+
+		service := mps.NewMPSService(itemRepo, inventoryRepo)
+		orders := []*entities.DemandRequirement{
+			{PartNumber: "ENGINE", Quantity: 3, NeedDate: needDate, DemandSource: "ORDER", Location: "PLANT", TargetSerial: "SN001"},
+		}
+		schedule, err := service.BuildSchedule(ctx, nil, orders, nil, nil, mps.Config{Start: needDate, PeriodDays: 7})
+		if err != nil {
+			t.Fatalf("BuildSchedule failed: %v", err)
+		}
+
+		// The stock the schedule counted on goes to another run before it is reserved
+		if _, err := inventoryRepo.AllocateInventory("ENGINE", "PLANT", 1); err != nil {
+			t.Fatalf("AllocateInventory failed: %v", err)
+		}
+		err = service.ReserveStock(schedule)
+		if err != nil {
+			err = fmt.Errorf("error reserving end item stock: %w", err)
+		}
+		if ExitCode(err) != ExitAllocationConflict || HTTPStatus(err) != http.StatusConflict {
+			t.Errorf("Expected exit code %d and HTTP 409, got %d and %d for %v",
+				ExitAllocationConflict, ExitCode(err), HTTPStatus(err), err)
+		}
+	})
+}